@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	_ "spy-cats/docs" // Import docs for swagger
+	"spy-cats/internal/breeds"
 	"spy-cats/internal/cats"
 	"spy-cats/internal/database"
 	"spy-cats/internal/middleware"
 	"spy-cats/internal/missions"
+	"spy-cats/internal/payroll"
+	"spy-cats/internal/scoring"
+	"spy-cats/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -29,12 +34,37 @@ import (
 // @host      localhost:8080
 // @BasePath  /api
 
+// @securityDefinitions.apikey  AdminToken
+// @in                          header
+// @name                        Authorization
+// @description                 Bearer ADMIN_TOKEN, required by admin operations
+
 func main() {
 	db, err := database.Connect()
 	if err != nil {
 		log.Fatal("Database connection failed:", err)
 	}
 
+	catalog, err := utils.NewBreedCatalog(utils.BreedCatalogConfigFromEnv())
+	if err != nil {
+		log.Fatal("Breed catalog setup failed:", err)
+	}
+	breedService := breeds.NewService(breeds.NewRepository(db), catalog)
+	if _, err := breedService.Seed(); err != nil {
+		log.Fatal("Breed seeding failed:", err)
+	}
+
+	ranks, err := utils.RankLadderFromEnv()
+	if err != nil {
+		log.Fatal("Rank ladder setup failed:", err)
+	}
+
+	go database.NewPurger(db, database.PurgeConfigFromEnv()).Run(context.Background())
+	catService := cats.NewService(cats.NewRepository(db), breedService, utils.DefaultCurrencyFromEnv(), ranks)
+	go cats.NewSalaryScheduler(catService, cats.SalaryScheduleIntervalFromEnv()).Run(context.Background())
+	scorer := scoring.NewScorer(db, scoring.IntervalFromEnv())
+	go scorer.Run(context.Background())
+
 	r := gin.Default()
 	r.Use(middleware.LoggingMiddleware())
 
@@ -43,8 +73,11 @@ func main() {
 
 	api := r.Group("/api")
 	{
-		cats.RegisterRoutes(api.Group("/cats"), db)
-		missions.RegisterRoutes(api.Group("/missions"), db)
+		breeds.RegisterRoutes(api.Group("/breeds"), breedService, middleware.AdminMiddleware(middleware.AdminTokenFromEnv()))
+		cats.RegisterRoutes(api.Group("/cats"), catService)
+		missions.RegisterRoutes(api.Group("/missions"), db, scorer, ranks)
+		payroll.RegisterRoutes(api.Group("/payroll"), db, payroll.ConfigFromEnv())
+		scoring.RegisterRoutes(api, db)
 	}
 
 	log.Println("Server started on port 8080")
@@ -24,23 +24,23 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cats": {
+        "/breeds": {
             "get": {
-                "description": "Get a list of all spy cats in the system",
+                "description": "Get all cat breeds a spy cat may have",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cats"
+                    "breeds"
                 ],
-                "summary": "List all spy cats",
+                "summary": "List cat breeds",
                 "responses": {
                     "200": {
-                        "description": "List of cats",
+                        "description": "List of breeds",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/cats.Cat"
+                                "$ref": "#/definitions/breeds.Breed"
                             }
                         }
                     },
@@ -54,43 +54,59 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/breeds/sync": {
             "post": {
-                "description": "Create a new spy cat with the provided information",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Admin operation: refresh breeds from TheCatAPI (or the bundled snapshot when offline) into the breeds table. Existing breeds are never removed. Requires ` + "`" + `Authorization: Bearer \u003cADMIN_TOKEN\u003e` + "`" + ` and is disabled when ADMIN_TOKEN is unset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cats"
+                    "breeds"
                 ],
-                "summary": "Create a spy cat",
-                "parameters": [
-                    {
-                        "description": "Cat information",
-                        "name": "cat",
-                        "in": "body",
-                        "required": true,
+                "summary": "Sync cat breeds",
+                "responses": {
+                    "200": {
+                        "description": "Sync summary",
                         "schema": {
-                            "$ref": "#/definitions/cats.CreateCatRequest"
+                            "$ref": "#/definitions/breeds.SyncResult"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Successfully created cat",
+                    },
+                    "401": {
+                        "description": "Missing or wrong admin token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "integer",
-                                "format": "int64"
+                                "type": "string"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid input",
+                    "403": {
+                        "description": "Admin operations are disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Breed catalog unavailable",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -101,34 +117,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/cats/{id}": {
+        "/breeds/{name}": {
             "get": {
-                "description": "Get a spy cat by its ID",
+                "description": "Get a cat breed by its name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cats"
+                    "breeds"
                 ],
-                "summary": "Get a spy cat",
+                "summary": "Get a cat breed",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Cat ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Breed name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cat information",
+                        "description": "Breed information",
                         "schema": {
-                            "$ref": "#/definitions/cats.Cat"
+                            "$ref": "#/definitions/breeds.Breed"
                         }
                     },
                     "404": {
-                        "description": "Cat not found",
+                        "description": "Breed not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -146,25 +162,122 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a spy cat by its ID",
+            }
+        },
+        "/cats": {
+            "get": {
+                "description": "Get a page of spy cats, optionally filtered and sorted. Pass the X-Next-Cursor value as cursor to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "cats"
                 ],
-                "summary": "Delete a spy cat",
+                "summary": "List spy cats",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Breed (case-insensitive)",
+                        "name": "breed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name prefix (case-insensitive)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Salary currency (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Minimum salary, a decimal amount such as 1000.10",
+                        "name": "min_salary",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum salary, a decimal amount such as 90000.00",
+                        "name": "max_salary",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Cat ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Minimum years of experience",
+                        "name": "min_experience",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum years of experience",
+                        "name": "max_experience",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "name",
+                            "-name",
+                            "salary",
+                            "-salary",
+                            "years_of_experience",
+                            "-years_of_experience"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list soft-deleted cats",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cat deleted successfully",
+                        "description": "List of cats",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cats.Cat"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page with rel=next"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Number of cats matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -182,11 +295,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/cats/{id}/salary": {
-            "patch": {
-                "description": "Update the salary of a specific spy cat",
+            },
+            "post": {
+                "description": "Create a new spy cat with the provided information",
                 "consumes": [
                     "application/json"
                 ],
@@ -196,82 +307,89 @@ const docTemplate = `{
                 "tags": [
                     "cats"
                 ],
-                "summary": "Update cat salary",
+                "summary": "Create a spy cat",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Cat ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "New salary information",
-                        "name": "salary",
+                        "description": "Cat information",
+                        "name": "cat",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/cats.UpdateSalaryRequest"
+                            "$ref": "#/definitions/cats.CreateCatRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Salary updated successfully",
+                    "201": {
+                        "description": "Successfully created cat",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "integer",
+                                "format": "int64"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "404": {
-                        "description": "Cat not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/missions": {
+        "/cats/{id}": {
             "get": {
-                "description": "Get a list of all missions in the system",
+                "description": "Get a spy cat by its ID. include=current_mission embeds the incomplete mission the cat is on with its targets.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "missions"
+                    "cats"
+                ],
+                "summary": "Get a spy cat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "current_mission"
+                        ],
+                        "type": "string",
+                        "description": "Embed related data",
+                        "name": "include",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all missions",
                 "responses": {
                     "200": {
-                        "description": "List of missions",
+                        "description": "Cat information",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/missions.Mission"
+                            "$ref": "#/definitions/cats.Cat"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -286,8 +404,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create a new mission with targets",
+            "put": {
+                "description": "Replace all fields of a spy cat",
                 "consumes": [
                     "application/json"
                 ],
@@ -295,29 +413,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Create a mission",
+                "summary": "Replace a spy cat",
                 "parameters": [
                     {
-                        "description": "Mission information",
-                        "name": "mission",
+                        "type": "integer",
+                        "description": "Cat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cat information",
+                        "name": "cat",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/missions.CreateMissionRequest"
+                            "$ref": "#/definitions/cats.CreateCatRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created mission",
+                    "200": {
+                        "description": "Updated cat",
                         "schema": {
-                            "$ref": "#/definitions/missions.Mission"
+                            "$ref": "#/definitions/cats.Cat"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Cat not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -335,27 +467,34 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/missions/targets/{targetId}": {
+            },
             "delete": {
-                "description": "Delete a target by its ID",
+                "description": "Soft delete a spy cat by its ID. Deleted cats are hidden until restored and purged after the retention window. A cat on an incomplete mission is only deleted if reassign_to names a cat without incomplete missions to hand the missions to.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Delete target",
+                "summary": "Delete a spy cat",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
-                        "name": "targetId",
+                        "description": "Cat ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cat ID that takes over the incomplete missions",
+                        "name": "reassign_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Target deleted successfully",
+                        "description": "Cat deleted successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -364,49 +503,87 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid reassign_to",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Update target completion status and notes",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "missions"
-                ],
-                "summary": "Update target",
+                    },
+                    "404": {
+                        "description": "Cat or reassign_to cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Cat has incomplete missions, listed in mission_ids, or reassign_to cat is busy or its rank does not allow the missions' targets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update some of a spy cat's fields; omitted fields are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cats"
+                ],
+                "summary": "Update a spy cat",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
-                        "name": "targetId",
+                        "description": "Cat ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Target update information",
-                        "name": "target",
+                        "description": "Fields to update",
+                        "name": "cat",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/missions.UpdateTargetRequest"
+                            "$ref": "#/definitions/cats.UpdateCatRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Target updated successfully",
+                        "description": "Updated cat",
+                        "schema": {
+                            "$ref": "#/definitions/cats.Cat"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Cat not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -414,8 +591,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -426,34 +603,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/missions/{id}": {
+        "/cats/{id}/missions": {
             "get": {
-                "description": "Get a mission by its ID",
+                "description": "Get every mission the cat was assigned to with when and why each assignment ended, most recent first. Repeat status to match any of several mission statuses.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Get a mission",
+                "summary": "Cat mission history",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Mission ID",
+                        "description": "Cat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "planned",
+                                "active",
+                                "on_hold",
+                                "completed",
+                                "aborted",
+                                "failed"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Mission status",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Mission information",
+                        "description": "Assignment history",
                         "schema": {
-                            "$ref": "#/definitions/missions.Mission"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cats.MissionAssignment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
-                        "description": "Mission not found",
+                        "description": "Cat not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -471,17 +678,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a mission by its ID",
+            }
+        },
+        "/cats/{id}/restore": {
+            "post": {
+                "description": "Undelete a soft-deleted spy cat that has not been purged yet",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Delete a mission",
+                "summary": "Restore a spy cat",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Mission ID",
+                        "description": "Cat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -489,7 +701,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Mission deleted successfully",
+                        "description": "Restored cat",
+                        "schema": {
+                            "$ref": "#/definitions/cats.Cat"
+                        }
+                    },
+                    "404": {
+                        "description": "No deleted cat with this ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -497,8 +715,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -509,9 +727,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/missions/{id}/assign": {
-            "put": {
-                "description": "Assign a spy cat to a mission",
+        "/cats/{id}/salary": {
+            "patch": {
+                "description": "Update the salary of a specific spy cat and record the change in its salary history. A future effective_date schedules the change, which is applied automatically on that date.",
                 "consumes": [
                     "application/json"
                 ],
@@ -519,39 +737,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Assign cat to mission",
+                "summary": "Update cat salary",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Mission ID",
+                        "description": "Cat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Cat assignment information",
-                        "name": "request",
+                        "description": "New salary information",
+                        "name": "salary",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/missions.AssignCatRequest"
+                            "$ref": "#/definitions/cats.UpdateSalaryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cat assigned successfully",
+                        "description": "Salary updated successfully, with the recorded change",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "Salary change scheduled, with the recorded change",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid input, salary outside the band of the cat's rank, or effective date in the past",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -560,7 +783,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Mission not found",
+                        "description": "Cat not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -580,17 +803,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/missions/{id}/complete": {
-            "patch": {
-                "description": "Mark a mission as complete by its ID",
+        "/cats/{id}/salary-history": {
+            "get": {
+                "description": "Get every salary change of a spy cat with the old and new salary, effective date, reason and actor, latest effective date first. Scheduled changes are included without old_salary and applied_at.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Mark mission complete",
+                "summary": "Cat salary history",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Mission ID",
+                        "description": "Cat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -598,16 +824,16 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Mission marked complete",
+                        "description": "Salary history",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cats.SalaryChange"
                             }
                         }
                     },
                     "404": {
-                        "description": "Mission not found",
+                        "description": "Cat not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -627,40 +853,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/missions/{id}/targets": {
-            "post": {
-                "description": "Add a new target to an existing mission",
-                "consumes": [
-                    "application/json"
-                ],
+        "/cats/{id}/stats": {
+            "get": {
+                "description": "Get the missions a cat completed and failed, the targets it neutralized, its success rate, average time from assignment to completion and score. Stats are updated shortly after missions and targets close.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "missions"
+                    "cats"
                 ],
-                "summary": "Add target to mission",
+                "summary": "Get a cat's stats",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Mission ID",
+                        "description": "Cat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cat stats",
+                        "schema": {
+                            "$ref": "#/definitions/scoring.Stats"
+                        }
                     },
-                    {
-                        "description": "Target information",
-                        "name": "target",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "Invalid cat ID",
                         "schema": {
-                            "$ref": "#/definitions/missions.CreateTarget"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/leaderboard": {
+            "get": {
+                "description": "Rank the cats that have completed or failed a mission or neutralized a target. Cats earn 100 points per completed mission and 25 per neutralized target. Ranking by success rate or average time to complete only includes cats those are defined for; ties go to the lower cat ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cats"
+                ],
+                "summary": "Get the leaderboard",
+                "parameters": [
+                    {
+                        "enum": [
+                            "score",
+                            "missions_completed",
+                            "targets_neutralized",
+                            "success_rate",
+                            "avg_time_to_complete"
+                        ],
+                        "type": "string",
+                        "default": "score",
+                        "description": "Ranking",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of cats (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Target added successfully",
+                    "200": {
+                        "description": "Ranked cats",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/scoring.LeaderboardEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -668,8 +961,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -679,40 +972,1616 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "cats.Cat": {
-            "type": "object",
-            "properties": {
-                "breed": {
-                    "type": "string",
-                    "example": "Siamese"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Whiskers"
-                },
-                "salary": {
-                    "type": "number",
-                    "example": 50000
-                },
-                "years_of_experience": {
-                    "type": "integer",
-                    "example": 5
-                }
-            }
         },
-        "cats.CreateCatRequest": {
-            "type": "object",
-            "required": [
-                "breed",
-                "name",
-                "salary",
+        "/missions": {
+            "get": {
+                "description": "Get a page of missions, optionally filtered and sorted. Missions without a deadline sort as if it were infinitely far off. Pass the X-Next-Cursor value as cursor to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "List missions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "planned",
+                            "active",
+                            "on_hold",
+                            "completed",
+                            "aborted",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Mission status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Completion status",
+                        "name": "is_complete",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Assigned cat ID",
+                        "name": "cat_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only missions without a cat",
+                        "name": "unassigned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Missions with a target in this country (case-insensitive)",
+                        "name": "target_country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name contains (case-insensitive)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "critical"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Any of these priorities",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline on or after this date (YYYY-MM-DD)",
+                        "name": "deadline_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline on or before this date (YYYY-MM-DD)",
+                        "name": "deadline_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only missions that are, or are not, open past their deadline",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "priority",
+                            "-priority",
+                            "deadline",
+                            "-deadline"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "targets"
+                        ],
+                        "type": "string",
+                        "description": "Embed related data",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list soft-deleted missions",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of missions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/missions.Mission"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page with rel=next"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Number of missions matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new mission with 1 to 3 targets. The priority defaults to medium, and the deadline may not precede the start date or, unless the mission is created complete, today. A mission created complete must have all its targets complete and credits its cat with them; a planned mission's targets must all be pending.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Create a mission",
+                "parameters": [
+                    {
+                        "description": "Mission information",
+                        "name": "mission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.CreateMissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, target count, target status or schedule",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Cat already has an active mission or its rank does not allow that many targets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/targets": {
+            "get": {
+                "description": "Get a page of targets across all missions ordered by ID, optionally filtered. Pass the X-Next-Cursor value as cursor to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Search targets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Country (case-insensitive)",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "completed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Target status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Completion status",
+                        "name": "is_complete",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "mission_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list soft-deleted targets",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of targets",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/missions.Target"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page with rel=next"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Number of targets matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/targets/{targetId}": {
+            "get": {
+                "description": "Get a target by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Get a target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "targetId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Target information",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Target"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft delete a pending target of an open mission by its ID. The mission must keep at least one target and is closed once no pending targets remain. Deleted targets are hidden until restored and purged after the retention window.",
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Delete target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "targetId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Target deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Target or mission is closed, or it is the mission's last target",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch to a target: absent fields are left untouched and ` + "`" + `\"notes\": null` + "`" + ` clears the notes. A pending target of an active mission can be completed or failed; once no target is pending the mission is completed, or failed if every target failed. Notes are frozen once the target or its mission is closed, and closed targets cannot be reopened.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Update target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "targetId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target fields to change",
+                        "name": "target",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.UpdateTargetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated target",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Target"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Target or mission is closed, or the status change is not allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/targets/{targetId}/move": {
+            "post": {
+                "description": "Move a pending target to another open mission. The source mission must keep at least one target and the destination may not exceed three; an active source left without pending targets is closed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Move target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "targetId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Destination mission",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.MoveTargetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Moved target",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Target"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or target limit exceeded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Target or mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Target or mission is complete, or the destination cat's rank does not allow another target",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/targets/{targetId}/restore": {
+            "post": {
+                "description": "Undelete a soft-deleted target that has not been purged yet. Its mission must not be deleted or closed and must have fewer than 3 targets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Restore target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "targetId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored target",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Target"
+                        }
+                    },
+                    "404": {
+                        "description": "No deleted target with this ID or its mission is deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is closed or already has the maximum number of targets for itself or its cat's rank",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}": {
+            "get": {
+                "description": "Get a mission by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Get a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mission information",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft delete a mission and its targets by the mission ID and return what was deleted. Assigned missions are only deleted with unassign=true, which releases the cat first; active and on hold missions cannot be deleted. Deleted missions are hidden until restored and purged after the retention window.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Delete a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Release the assigned cat and delete the mission",
+                        "name": "unassign",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Summary of the deleted mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.DeletedMission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid unassign flag",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is assigned to a cat or in progress",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/assign": {
+            "put": {
+                "description": "Assign a spy cat to an unassigned mission. Missions that already have another cat are reassigned with POST /missions/{id}/reassign.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Assign cat to mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cat assignment information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.AssignCatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cat assigned successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission or cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is closed or assigned to another cat, or cat already has an active mission or its rank does not allow the mission's targets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Release the cat of a mission. Active missions are put on hold until a cat is assigned again, and closed missions keep their cat. The change is recorded in the mission history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Unassign cat from mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unassigned mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is closed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/assignments": {
+            "get": {
+                "description": "Get every cat assigned to a mission with when and why each assignment ended, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Mission assignment history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Assignment history",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/missions.Assignment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/complete": {
+            "patch": {
+                "description": "Mark an active mission as complete by its ID. Missions with incomplete targets are rejected unless force is set, which completes the targets as well.",
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Mark mission complete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also complete all open targets",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mission marked complete",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid force flag",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission has incomplete targets or cannot be completed from its status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/reassign": {
+            "post": {
+                "description": "Swap the cat of an assigned mission for another one in a single step. The change is recorded in the mission history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Reassign mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cat taking over the mission",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.AssignCatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reassigned mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission or cat not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is closed or unassigned, or cat already has an active mission or its rank does not allow the mission's targets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/restore": {
+            "post": {
+                "description": "Undelete a soft-deleted mission that has not been purged yet, along with the targets deleted with it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Restore a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "404": {
+                        "description": "No deleted mission with this ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/targets": {
+            "get": {
+                "description": "Get all targets of a mission ordered by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "List mission targets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Targets of the mission",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/missions.Target"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new target to an existing mission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Add target to mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target information",
+                        "name": "target",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.CreateTarget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created target",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Target"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created target"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or mission already has the maximum number of targets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is complete or its cat's rank does not allow another target",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/transitions": {
+            "get": {
+                "description": "Get every status a mission went through with the time of each change, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Mission status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status history",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/missions.StatusChange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Move a mission through its lifecycle: planned -\u003e active (needs an assigned cat), active \u003c-\u003e on_hold, active -\u003e completed (needs all targets closed), and planned, active or on_hold -\u003e aborted, active -\u003e failed. Completed, aborted and failed missions are final.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "missions"
+                ],
+                "summary": "Change mission status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target status",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/missions.TransitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated mission",
+                        "schema": {
+                            "$ref": "#/definitions/missions.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payroll/runs": {
+            "post": {
+                "description": "Pay every cat that is not deleted and is paid in the run's currency for a monthly or biweekly pay period: the yearly salary prorated to the period, a bonus per mission completed in the period, minus deductions. The currency defaults to DEFAULT_CURRENCY. Only periods that ended before today (UTC) can be run. Runs are immutable and a period that overlaps an earlier run in the same currency cannot be run again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payroll"
+                ],
+                "summary": "Run payroll",
+                "parameters": [
+                    {
+                        "description": "Pay period",
+                        "name": "run",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payroll.CreateRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Payroll run with its line items",
+                        "schema": {
+                            "$ref": "#/definitions/payroll.Run"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the payroll run"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, pay period or currency",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Pay period already run",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payroll/runs/{id}": {
+            "get": {
+                "description": "Get a payroll run with the line item of every cat it paid",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payroll"
+                ],
+                "summary": "Get a payroll run",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Payroll run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payroll run",
+                        "schema": {
+                            "$ref": "#/definitions/payroll.Run"
+                        }
+                    },
+                    "404": {
+                        "description": "Payroll run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "breeds.Breed": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Siam",
+                        "Thai Cat"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Siamese"
+                },
+                "origin": {
+                    "type": "string",
+                    "example": "Thailand"
+                },
+                "temperament": {
+                    "type": "string",
+                    "example": "Active, Agile, Clever, Sociable, Loving, Energetic"
+                }
+            }
+        },
+        "breeds.SyncResult": {
+            "type": "object",
+            "properties": {
+                "fetched": {
+                    "type": "integer",
+                    "example": 67
+                },
+                "upserted": {
+                    "type": "integer",
+                    "example": 67
+                }
+            }
+        },
+        "cats.Cat": {
+            "type": "object",
+            "properties": {
+                "breed": {
+                    "type": "string",
+                    "example": "Siamese"
+                },
+                "current_mission": {
+                    "$ref": "#/definitions/cats.CurrentMission"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "missions_completed": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Whiskers"
+                },
+                "rank": {
+                    "type": "string",
+                    "example": "senior"
+                },
+                "salary": {
+                    "$ref": "#/definitions/utils.Money"
+                },
+                "years_of_experience": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "cats.CreateCatRequest": {
+            "type": "object",
+            "required": [
+                "breed",
+                "name",
+                "salary",
                 "years_of_experience"
             ],
             "properties": {
@@ -727,15 +2596,185 @@ const docTemplate = `{
                     "example": "Whiskers"
                 },
                 "salary": {
-                    "type": "number",
-                    "minimum": 0,
-                    "example": 50000
+                    "$ref": "#/definitions/utils.Money"
+                },
+                "years_of_experience": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 5
+                }
+            }
+        },
+        "cats.CurrentMission": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Operation Stealth"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "planned",
+                        "active",
+                        "on_hold"
+                    ],
+                    "example": "active"
+                },
+                "status_changed_at": {
+                    "type": "string",
+                    "example": "2024-05-02T08:30:00Z"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cats.MissionTarget"
+                    }
+                }
+            }
+        },
+        "cats.MissionAssignment": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "mission_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mission_name": {
+                    "type": "string",
+                    "example": "Operation Stealth"
+                },
+                "mission_status": {
+                    "type": "string",
+                    "enum": [
+                        "planned",
+                        "active",
+                        "on_hold",
+                        "completed",
+                        "aborted",
+                        "failed"
+                    ],
+                    "example": "completed"
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "unassigned",
+                        "reassigned",
+                        "completed",
+                        "aborted",
+                        "failed",
+                        "mission_deleted",
+                        "cat_deleted"
+                    ],
+                    "example": "completed"
+                },
+                "released_at": {
+                    "type": "string",
+                    "example": "2024-05-10T17:00:00Z"
+                }
+            }
+        },
+        "cats.MissionTarget": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "Russia"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Agent Smith"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "High priority target"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "cats.SalaryChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "m.hale"
+                },
+                "applied_at": {
+                    "type": "string",
+                    "example": "2024-07-01T00:05:00Z"
+                },
+                "cat_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-06-15T10:00:00Z"
+                },
+                "effective_date": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "new_salary": {
+                    "$ref": "#/definitions/utils.Money"
+                },
+                "old_salary": {
+                    "$ref": "#/definitions/utils.Money"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Annual raise"
+                }
+            }
+        },
+        "cats.UpdateCatRequest": {
+            "type": "object",
+            "properties": {
+                "breed": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Siamese"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2,
+                    "example": "Whiskers"
+                },
+                "salary": {
+                    "$ref": "#/definitions/utils.Money"
                 },
                 "years_of_experience": {
                     "type": "integer",
                     "maximum": 50,
                     "minimum": 0,
-                    "example": 5
+                    "example": 6
                 }
             }
         },
@@ -745,10 +2784,22 @@ const docTemplate = `{
                 "salary"
             ],
             "properties": {
+                "actor": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "m.hale"
+                },
+                "effective_date": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Annual raise"
+                },
                 "salary": {
-                    "type": "number",
-                    "minimum": 0,
-                    "example": 60000
+                    "$ref": "#/definitions/utils.Money"
                 }
             }
         },
@@ -764,6 +2815,40 @@ const docTemplate = `{
                 }
             }
         },
+        "missions.Assignment": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "cat_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "reason": {
+                    "enum": [
+                        "unassigned",
+                        "reassigned",
+                        "completed",
+                        "aborted",
+                        "failed",
+                        "mission_deleted",
+                        "cat_deleted"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.ReleaseReason"
+                        }
+                    ],
+                    "example": "completed"
+                },
+                "released_at": {
+                    "type": "string",
+                    "example": "2024-05-10T17:00:00Z"
+                }
+            }
+        },
         "missions.CreateMissionRequest": {
             "type": "object",
             "required": [
@@ -775,6 +2860,15 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 5
                 },
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Recover the stolen plans from the embassy"
+                },
                 "is_complete": {
                     "type": "boolean",
                     "example": false
@@ -783,8 +2877,28 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Operation Stealth"
                 },
+                "priority": {
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high",
+                        "critical"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.Priority"
+                        }
+                    ],
+                    "example": "high"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
                 "targets": {
                     "type": "array",
+                    "maxItems": 3,
+                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/missions.CreateTarget"
                     }
@@ -816,6 +2930,47 @@ const docTemplate = `{
                 }
             }
         },
+        "missions.DeletedMission": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-05-03T09:00:00Z"
+                },
+                "deleted_targets": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Operation Stealth"
+                },
+                "status": {
+                    "enum": [
+                        "planned",
+                        "active",
+                        "on_hold",
+                        "completed",
+                        "aborted",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.MissionStatus"
+                        }
+                    ],
+                    "example": "planned"
+                },
+                "unassigned_cat_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
         "missions.Mission": {
             "type": "object",
             "properties": {
@@ -823,6 +2978,22 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 5
                 },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-05-03T09:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Recover the stolen plans from the embassy"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -835,6 +3006,48 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Operation Stealth"
                 },
+                "overdue": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "priority": {
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high",
+                        "critical"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.Priority"
+                        }
+                    ],
+                    "example": "high"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "status": {
+                    "enum": [
+                        "planned",
+                        "active",
+                        "on_hold",
+                        "completed",
+                        "aborted",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.MissionStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "status_changed_at": {
+                    "type": "string",
+                    "example": "2024-05-02T08:30:00Z"
+                },
                 "targets": {
                     "type": "array",
                     "items": {
@@ -843,6 +3056,106 @@ const docTemplate = `{
                 }
             }
         },
+        "missions.MissionStatus": {
+            "type": "string",
+            "enum": [
+                "planned",
+                "active",
+                "on_hold",
+                "completed",
+                "aborted",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "MissionPlanned",
+                "MissionActive",
+                "MissionOnHold",
+                "MissionCompleted",
+                "MissionAborted",
+                "MissionFailed"
+            ]
+        },
+        "missions.MoveTargetRequest": {
+            "type": "object",
+            "required": [
+                "mission_id"
+            ],
+            "properties": {
+                "mission_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "missions.Priority": {
+            "type": "string",
+            "enum": [
+                "low",
+                "medium",
+                "high",
+                "critical"
+            ],
+            "x-enum-varnames": [
+                "PriorityLow",
+                "PriorityMedium",
+                "PriorityHigh",
+                "PriorityCritical"
+            ]
+        },
+        "missions.ReleaseReason": {
+            "type": "string",
+            "enum": [
+                "unassigned",
+                "reassigned",
+                "completed",
+                "aborted",
+                "failed",
+                "mission_deleted",
+                "cat_deleted"
+            ],
+            "x-enum-varnames": [
+                "ReleaseUnassigned",
+                "ReleaseReassigned",
+                "ReleaseCompleted",
+                "ReleaseAborted",
+                "ReleaseFailed",
+                "ReleaseMissionDeleted",
+                "ReleaseCatDeleted"
+            ]
+        },
+        "missions.StatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "from": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.MissionStatus"
+                        }
+                    ],
+                    "example": "planned"
+                },
+                "from_cat_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "to": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.MissionStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "to_cat_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
         "missions.Target": {
             "type": "object",
             "properties": {
@@ -850,6 +3163,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Russia"
                 },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-05-03T09:00:00Z"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -869,6 +3186,59 @@ const docTemplate = `{
                 "notes": {
                     "type": "string",
                     "example": "High priority target"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "completed",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.TargetStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "status_changed_at": {
+                    "type": "string",
+                    "example": "2024-05-02T08:30:00Z"
+                }
+            }
+        },
+        "missions.TargetStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TargetPending",
+                "TargetCompleted",
+                "TargetFailed"
+            ]
+        },
+        "missions.TransitionRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "active",
+                        "on_hold",
+                        "completed",
+                        "aborted",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/missions.MissionStatus"
+                        }
+                    ],
+                    "example": "active"
                 }
             }
         },
@@ -882,9 +3252,245 @@ const docTemplate = `{
                 "notes": {
                     "type": "string",
                     "example": "Mission accomplished"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "completed"
+                }
+            }
+        },
+        "payroll.CreateRunRequest": {
+            "type": "object",
+            "required": [
+                "frequency",
+                "period_start"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "frequency": {
+                    "enum": [
+                        "monthly",
+                        "biweekly"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payroll.Frequency"
+                        }
+                    ],
+                    "example": "monthly"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                }
+            }
+        },
+        "payroll.Frequency": {
+            "type": "string",
+            "enum": [
+                "monthly",
+                "biweekly"
+            ],
+            "x-enum-varnames": [
+                "Monthly",
+                "Biweekly"
+            ]
+        },
+        "payroll.LineItem": {
+            "type": "object",
+            "properties": {
+                "base_salary": {
+                    "type": "string",
+                    "example": "4166.67"
+                },
+                "cat_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cat_name": {
+                    "type": "string",
+                    "example": "Whiskers"
+                },
+                "deductions": {
+                    "type": "string",
+                    "example": "0.00"
+                },
+                "mission_bonus": {
+                    "type": "string",
+                    "example": "1500.00"
+                },
+                "missions_completed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "net_pay": {
+                    "type": "string",
+                    "example": "5666.67"
+                }
+            }
+        },
+        "payroll.Run": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-06-01T09:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "frequency": {
+                    "enum": [
+                        "monthly",
+                        "biweekly"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payroll.Frequency"
+                        }
+                    ],
+                    "example": "monthly"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payroll.LineItem"
+                    }
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2024-05-31"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "total_deductions": {
+                    "type": "string",
+                    "example": "0.00"
+                },
+                "total_gross": {
+                    "type": "string",
+                    "example": "5666.67"
+                },
+                "total_net": {
+                    "type": "string",
+                    "example": "5666.67"
+                }
+            }
+        },
+        "scoring.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "avg_time_to_complete_seconds": {
+                    "type": "integer",
+                    "example": 172800
+                },
+                "cat_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cat_name": {
+                    "type": "string",
+                    "example": "Whiskers"
+                },
+                "missions_completed": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "missions_failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rank": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 625
+                },
+                "success_rate": {
+                    "type": "number",
+                    "example": 0.8
+                },
+                "targets_neutralized": {
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "scoring.Stats": {
+            "type": "object",
+            "properties": {
+                "avg_time_to_complete_seconds": {
+                    "type": "integer",
+                    "example": 172800
+                },
+                "cat_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cat_name": {
+                    "type": "string",
+                    "example": "Whiskers"
+                },
+                "missions_completed": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "missions_failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 625
+                },
+                "success_rate": {
+                    "type": "number",
+                    "example": 0.8
+                },
+                "targets_neutralized": {
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "utils.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "65000.00"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Bearer ADMIN_TOKEN, required by admin operations",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
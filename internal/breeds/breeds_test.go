@@ -0,0 +1,198 @@
+package breeds_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"spy-cats/internal/breeds"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) GetAllBreeds() ([]breeds.Breed, error) {
+	args := m.Called()
+	return args.Get(0).([]breeds.Breed), args.Error(1)
+}
+
+func (m *mockService) GetBreed(name string) (*breeds.Breed, error) {
+	args := m.Called(name)
+	return args.Get(0).(*breeds.Breed), args.Error(1)
+}
+
+func (m *mockService) Sync(ctx context.Context) (*breeds.SyncResult, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*breeds.SyncResult), args.Error(1)
+}
+
+func TestListBreeds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		mockReturnBreeds []breeds.Breed
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
+	}{
+		{
+			name: "success",
+			mockReturnBreeds: []breeds.Breed{
+				{Name: "Siamese", Origin: "Thailand", Aliases: []string{"Siam"}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"aliases":["Siam"]`,
+		},
+		{
+			name:             "empty table",
+			mockReturnBreeds: nil,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `[]`,
+		},
+		{
+			name:             "service error",
+			mockReturnBreeds: nil,
+			mockReturnErr:    errors.New("database error"),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedBody:     `"error":"failed to get breeds"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := breeds.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.GET("/breeds", h.ListBreeds)
+
+			mockSvc.On("GetAllBreeds").Return(tt.mockReturnBreeds, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/breeds", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetBreed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		breedName       string
+		mockReturnBreed *breeds.Breed
+		mockReturnErr   error
+		expectedStatus  int
+		expectedBody    string
+	}{
+		{
+			name:            "success",
+			breedName:       "Maine Coon",
+			mockReturnBreed: &breeds.Breed{Name: "Maine Coon", Origin: "United States"},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `"origin":"United States"`,
+		},
+		{
+			name:            "breed not found",
+			breedName:       "Unicorn",
+			mockReturnBreed: nil,
+			expectedStatus:  http.StatusNotFound,
+			expectedBody:    `"error":"breed not found: Unicorn"`,
+		},
+		{
+			name:            "service error",
+			breedName:       "Siamese",
+			mockReturnBreed: nil,
+			mockReturnErr:   errors.New("database error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedBody:    `"error":"failed to get breed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := breeds.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.GET("/breeds/:name", h.GetBreed)
+
+			mockSvc.On("GetBreed", tt.breedName).Return(tt.mockReturnBreed, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/breeds/"+tt.breedName, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestSyncBreeds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		mockReturnResult *breeds.SyncResult
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
+	}{
+		{
+			name:             "success",
+			mockReturnResult: &breeds.SyncResult{Fetched: 67, Upserted: 67},
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"upserted":67`,
+		},
+		{
+			name:             "upstream unavailable",
+			mockReturnResult: nil,
+			mockReturnErr:    fmt.Errorf("%w: timeout", breeds.ErrUpstreamUnavailable),
+			expectedStatus:   http.StatusBadGateway,
+			expectedBody:     `"error":"breed catalog unavailable: timeout"`,
+		},
+		{
+			name:             "service error",
+			mockReturnResult: nil,
+			mockReturnErr:    errors.New("database error"),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedBody:     `"error":"failed to sync breeds"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := breeds.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.POST("/breeds/sync", h.SyncBreeds)
+
+			mockSvc.On("Sync", mock.Anything).Return(tt.mockReturnResult, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodPost, "/breeds/sync", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package breeds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service BreedService
+}
+
+type BreedService interface {
+	GetAllBreeds() ([]Breed, error)
+	GetBreed(name string) (*Breed, error)
+	Sync(ctx context.Context) (*SyncResult, error)
+}
+
+func NewHandler(service BreedService) *Handler {
+	return &Handler{service: service}
+}
+
+// ListBreeds retrieves all known cat breeds
+// @Summary      List cat breeds
+// @Description  Get all cat breeds a spy cat may have
+// @Tags         breeds
+// @Produce      json
+// @Success      200  {array}   Breed "List of breeds"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /breeds [get]
+func (h *Handler) ListBreeds(c *gin.Context) {
+	breeds, err := h.service.GetAllBreeds()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get breeds"})
+		return
+	}
+	if breeds == nil {
+		breeds = []Breed{}
+	}
+	c.JSON(http.StatusOK, breeds)
+}
+
+// GetBreed retrieves a breed by name
+// @Summary      Get a cat breed
+// @Description  Get a cat breed by its name
+// @Tags         breeds
+// @Produce      json
+// @Param        name  path      string  true  "Breed name"
+// @Success      200   {object}  Breed "Breed information"
+// @Failure      404   {object}  map[string]string "Breed not found"
+// @Failure      500   {object}  map[string]string "Internal server error"
+// @Router       /breeds/{name} [get]
+func (h *Handler) GetBreed(c *gin.Context) {
+	breed, err := h.service.GetBreed(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get breed"})
+		return
+	}
+	if breed == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "breed not found: " + c.Param("name")})
+		return
+	}
+	c.JSON(http.StatusOK, breed)
+}
+
+// SyncBreeds syncs the breeds table with TheCatAPI
+// @Summary      Sync cat breeds
+// @Description  Admin operation: refresh breeds from TheCatAPI (or the bundled snapshot when offline) into the breeds table. Existing breeds are never removed. Requires `Authorization: Bearer <ADMIN_TOKEN>` and is disabled when ADMIN_TOKEN is unset.
+// @Tags         breeds
+// @Produce      json
+// @Security     AdminToken
+// @Success      200  {object}  SyncResult "Sync summary"
+// @Failure      401  {object}  map[string]string "Missing or wrong admin token"
+// @Failure      403  {object}  map[string]string "Admin operations are disabled"
+// @Failure      502  {object}  map[string]string "Breed catalog unavailable"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /breeds/sync [post]
+func (h *Handler) SyncBreeds(c *gin.Context) {
+	result, err := h.service.Sync(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, ErrUpstreamUnavailable) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sync breeds"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
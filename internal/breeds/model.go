@@ -0,0 +1,15 @@
+package breeds
+
+// Breed represents a cat breed known to the agency
+type Breed struct {
+	Name        string   `json:"name" example:"Siamese"`
+	Origin      string   `json:"origin" example:"Thailand"`
+	Temperament string   `json:"temperament" example:"Active, Agile, Clever, Sociable, Loving, Energetic"`
+	Aliases     []string `json:"aliases" example:"Siam,Thai Cat"`
+}
+
+// SyncResult summarizes a breed catalog sync
+type SyncResult struct {
+	Fetched  int `json:"fetched" example:"67"`
+	Upserted int `json:"upserted" example:"67"`
+}
@@ -0,0 +1,72 @@
+package breeds
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetAll() ([]Breed, error) {
+	rows, err := r.db.Query(`SELECT name, origin, temperament, aliases FROM breeds ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breeds []Breed
+	for rows.Next() {
+		var b Breed
+		if err := rows.Scan(&b.Name, &b.Origin, &b.Temperament, pq.Array(&b.Aliases)); err != nil {
+			return nil, err
+		}
+		breeds = append(breeds, b)
+	}
+	return breeds, rows.Err()
+}
+
+func (r *Repository) GetByName(name string) (*Breed, error) {
+	var b Breed
+	err := r.db.QueryRow(
+		`SELECT name, origin, temperament, aliases FROM breeds WHERE name=$1`, name,
+	).Scan(&b.Name, &b.Origin, &b.Temperament, pq.Array(&b.Aliases))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &b, err
+}
+
+// Upsert inserts the breeds or refreshes their details. Breeds are never
+// removed here, so cats referencing them stay valid.
+func (r *Repository) Upsert(breeds []Breed) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO breeds (name, origin, temperament, aliases)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (name) DO UPDATE
+		 SET origin = EXCLUDED.origin, temperament = EXCLUDED.temperament, aliases = EXCLUDED.aliases`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, b := range breeds {
+		if _, err := stmt.Exec(b.Name, b.Origin, b.Temperament, pq.Array(b.Aliases)); err != nil {
+			return 0, err
+		}
+	}
+	return len(breeds), tx.Commit()
+}
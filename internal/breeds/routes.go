@@ -0,0 +1,14 @@
+package breeds
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers the breed routes. admin guards the sync.
+func RegisterRoutes(rg *gin.RouterGroup, service *Service, admin gin.HandlerFunc) {
+	handler := NewHandler(service)
+
+	rg.GET("/", handler.ListBreeds)
+	rg.GET("/:name", handler.GetBreed)
+	rg.POST("/sync", admin, handler.SyncBreeds)
+}
@@ -0,0 +1,136 @@
+package breeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+// ErrUpstreamUnavailable is returned by Sync when the breed source cannot be refreshed
+var ErrUpstreamUnavailable = errors.New("breed catalog unavailable")
+
+// Source provides the upstream breed list. Breeds returns it with the time
+// it was fetched, the zero time until it is, and may revalidate it in the
+// background; *utils.BreedCatalog implements it.
+type Source interface {
+	Refresh(ctx context.Context) error
+	Breeds() ([]utils.Breed, time.Time)
+}
+
+// BreedRepository is the persistence used by Service. *Repository
+// implements it on top of PostgreSQL.
+type BreedRepository interface {
+	GetAll() ([]Breed, error)
+	GetByName(name string) (*Breed, error)
+	Upsert(breeds []Breed) (int, error)
+}
+
+type Service struct {
+	repo   BreedRepository
+	source Source
+
+	// matcher is built from the breeds table on first use and dropped
+	// whenever the table is written, so the next lookup rebuilds it.
+	// syncedAt is the fetch time of the source breeds last written.
+	mu       sync.Mutex
+	matcher  *utils.BreedMatcher
+	syncedAt time.Time
+}
+
+func NewService(repo BreedRepository, source Source) *Service {
+	return &Service{repo: repo, source: source}
+}
+
+func (s *Service) GetAllBreeds() ([]Breed, error) {
+	return s.repo.GetAll()
+}
+
+// GetBreed looks a breed up by name or alias, ignoring case
+func (s *Service) GetBreed(name string) (*Breed, error) {
+	canonical, err := s.ResolveBreed(name)
+	var unknown *utils.UnknownBreedError
+	if errors.As(err, &unknown) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByName(canonical)
+}
+
+// ResolveBreed matches breed against the local breeds table, which is what
+// the cats.breed foreign key is enforced against, and returns the canonical
+// name or an *utils.UnknownBreedError with suggestions. Breeds the source
+// fetched since the last write are upserted first, so the table follows the
+// source's cache.
+func (s *Service) ResolveBreed(breed string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if upstream, fetchedAt := s.source.Breeds(); fetchedAt.After(s.syncedAt) {
+		if _, err := s.upsertLocked(upstream, fetchedAt); err != nil {
+			log.Printf("breeds: keeping the breeds table, upsert failed: %v", err)
+		}
+	}
+	if s.matcher == nil {
+		breeds, err := s.repo.GetAll()
+		if err != nil {
+			return "", err
+		}
+		s.matcher = utils.NewBreedMatcher()
+		for _, b := range breeds {
+			s.matcher.Add(b.Name, b.Aliases...)
+		}
+	}
+	return s.matcher.Resolve(breed)
+}
+
+// Sync refreshes the source and upserts its breeds into the breeds table.
+// An offline source is synced from its snapshot.
+func (s *Service) Sync(ctx context.Context) (*SyncResult, error) {
+	if err := s.source.Refresh(ctx); err != nil && !errors.Is(err, utils.ErrCatalogOffline) {
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upsertLocked(s.source.Breeds())
+}
+
+// Seed upserts the breeds the source currently has, the bundled snapshot if
+// the source cannot be reached, so that the breeds table is filled before the
+// API serves requests
+func (s *Service) Seed() (*SyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upsertLocked(s.source.Breeds())
+}
+
+// upsertLocked writes the source breeds fetched at fetchedAt into the breeds
+// table and drops the matcher. It must be called with s.mu held.
+func (s *Service) upsertLocked(upstream []utils.Breed, fetchedAt time.Time) (*SyncResult, error) {
+	breeds := make([]Breed, 0, len(upstream))
+	for _, b := range upstream {
+		breeds = append(breeds, Breed{
+			Name:        b.Name,
+			Origin:      b.Origin,
+			Temperament: b.Temperament,
+			Aliases:     b.Aliases(),
+		})
+	}
+
+	n, err := s.repo.Upsert(breeds)
+	s.matcher = nil
+	if err != nil {
+		return nil, err
+	}
+	if fetchedAt.After(s.syncedAt) {
+		s.syncedAt = fetchedAt
+	}
+	return &SyncResult{Fetched: len(upstream), Upserted: n}, nil
+}
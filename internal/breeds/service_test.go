@@ -0,0 +1,115 @@
+package breeds_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/breeds"
+	"spy-cats/internal/utils"
+)
+
+// fakeRepo is an in-memory BreedRepository that counts full table reads
+type fakeRepo struct {
+	breeds []breeds.Breed
+	loads  int
+}
+
+func (r *fakeRepo) GetAll() ([]breeds.Breed, error) {
+	r.loads++
+	return append([]breeds.Breed(nil), r.breeds...), nil
+}
+
+func (r *fakeRepo) GetByName(name string) (*breeds.Breed, error) {
+	for _, b := range r.breeds {
+		if b.Name == name {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) Upsert(bs []breeds.Breed) (int, error) {
+	r.breeds = append(r.breeds, bs...)
+	return len(bs), nil
+}
+
+func TestServiceResolveBreed(t *testing.T) {
+	repo := &fakeRepo{breeds: []breeds.Breed{
+		{Name: "Siamese", Aliases: []string{"Siam"}},
+		{Name: "Maine Coon"},
+	}}
+	catalog, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{})
+	require.NoError(t, err)
+	svc := breeds.NewService(repo, catalog)
+
+	for input, want := range map[string]string{
+		"Siamese":      "Siamese",
+		"siam":         "Siamese",
+		" MAINE-coon ": "Maine Coon",
+	} {
+		got, err := svc.ResolveBreed(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err = svc.ResolveBreed("Siamece")
+	var unknown *utils.UnknownBreedError
+	require.ErrorAs(t, err, &unknown)
+	assert.Contains(t, unknown.Suggestions, "Siamese")
+
+	_, err = svc.ResolveBreed("Bengal")
+	assert.ErrorAs(t, err, &unknown)
+	assert.Equal(t, 1, repo.loads, "the matcher is built once")
+
+	// the offline catalog syncs its snapshot, which has Bengal
+	_, err = svc.Sync(context.Background())
+	require.NoError(t, err)
+	got, err := svc.ResolveBreed("bengal")
+	require.NoError(t, err)
+	assert.Equal(t, "Bengal", got)
+	assert.Equal(t, 2, repo.loads, "sync rebuilds the matcher")
+}
+
+func TestServiceSeed(t *testing.T) {
+	repo := &fakeRepo{}
+	catalog, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{})
+	require.NoError(t, err)
+	svc := breeds.NewService(repo, catalog)
+
+	result, err := svc.Seed()
+	require.NoError(t, err)
+	snapshot, _ := catalog.Breeds()
+	assert.Equal(t, len(snapshot), result.Upserted)
+
+	got, err := svc.ResolveBreed("maine coon")
+	require.NoError(t, err)
+	assert.Equal(t, "Maine Coon", got)
+}
+
+func TestServiceFollowsCatalog(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "Toybob", "origin": "Russia", "alt_names": "Scythian-Toy-Bob"}]`)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{breeds: []breeds.Breed{{Name: "Siamese"}}}
+	catalog, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, TTL: time.Hour})
+	require.NoError(t, err)
+	svc := breeds.NewService(repo, catalog)
+
+	// the first lookup fetches the catalog and writes what it fetched
+	got, err := svc.ResolveBreed("scythian-toy-bob")
+	require.NoError(t, err)
+	assert.Equal(t, "Toybob", got)
+	got, err = svc.ResolveBreed("siamese")
+	require.NoError(t, err)
+	assert.Equal(t, "Siamese", got)
+	assert.Len(t, repo.breeds, 2, "fresh catalog data is written once")
+}
@@ -4,17 +4,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"spy-cats/internal/cats"
+	"spy-cats/internal/utils"
 )
 
+func usd(amount utils.Amount) utils.Money {
+	return utils.Money{Amount: amount, Currency: "USD"}
+}
+
 type mockService struct {
 	mock.Mock
 }
@@ -24,22 +31,63 @@ func (m *mockService) CreateCat(req cats.CreateCatRequest) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *mockService) GetAllCats() ([]cats.Cat, error) {
-	args := m.Called()
-	return args.Get(0).([]cats.Cat), args.Error(1)
+func (m *mockService) GetAllCats(q cats.ListCatsQuery) (*cats.CatPage, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cats.CatPage), args.Error(1)
 }
-func (m *mockService) GetCat(id int64) (*cats.Cat, error) {
-	args := m.Called(id)
+func (m *mockService) GetCat(id int64, q cats.GetCatQuery) (*cats.Cat, error) {
+	args := m.Called(id, q)
 	return args.Get(0).(*cats.Cat), args.Error(1)
 }
-func (m *mockService) UpdateSalary(id int64, salary float64) error {
-	args := m.Called(id, salary)
-	return args.Error(0)
+func (m *mockService) UpdateCat(id int64, req cats.UpdateCatRequest) (*cats.Cat, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cats.Cat), args.Error(1)
 }
-func (m *mockService) DeleteCat(id int64) error {
+func (m *mockService) ReplaceCat(id int64, req cats.CreateCatRequest) (*cats.Cat, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cats.Cat), args.Error(1)
+}
+func (m *mockService) UpdateSalary(id int64, req cats.UpdateSalaryRequest) (*cats.SalaryChange, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cats.SalaryChange), args.Error(1)
+}
+func (m *mockService) GetSalaryHistory(id int64) ([]cats.SalaryChange, error) {
 	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]cats.SalaryChange), args.Error(1)
+}
+func (m *mockService) DeleteCat(id int64, reassignTo *int64) error {
+	args := m.Called(id, reassignTo)
 	return args.Error(0)
 }
+func (m *mockService) RestoreCat(id int64) (*cats.Cat, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cats.Cat), args.Error(1)
+}
+func (m *mockService) GetCatMissions(id int64, q cats.ListCatMissionsQuery) ([]cats.MissionAssignment, error) {
+	args := m.Called(id, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]cats.MissionAssignment), args.Error(1)
+}
 
 func TestCreateCat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -58,7 +106,7 @@ func TestCreateCat(t *testing.T) {
 				Name:              "Whiskers",
 				YearsOfExperience: 5,
 				Breed:             "Siamese",
-				Salary:            1000.0,
+				Salary:            &utils.Money{Amount: 1000_00},
 			},
 			mockReturnID:   42,
 			mockReturnErr:  nil,
@@ -77,13 +125,26 @@ func TestCreateCat(t *testing.T) {
 				Name:              "Tom",
 				YearsOfExperience: 3,
 				Breed:             "UnknownBreed",
-				Salary:            500.0,
+				Salary:            &utils.Money{Amount: 500_00},
 			},
 			mockReturnID:   0,
 			mockReturnErr:  errors.New("invalid cat breed: UnknownBreed"),
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `"error":"invalid cat breed: UnknownBreed"`,
 		},
+		{
+			name: "misspelled breed with suggestions",
+			body: cats.CreateCatRequest{
+				Name:              "Tom",
+				YearsOfExperience: 3,
+				Breed:             "Siamse",
+				Salary:            &utils.Money{Amount: 500_00},
+			},
+			mockReturnID:   0,
+			mockReturnErr:  &utils.UnknownBreedError{Breed: "Siamse", Suggestions: []string{"Siamese"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"suggestions":["Siamese"]`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,18 +184,29 @@ func TestCreateCat(t *testing.T) {
 func TestListCats(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	minSalary := utils.Amount(500_00)
+	exactMin, exactMax := utils.Amount(1000_10), utils.Amount(2000_00)
+	deletedAt := time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name           string
-		mockReturnCats []cats.Cat
+		query          string
+		callsService   bool
+		expectedQuery  cats.ListCatsQuery
+		mockReturnPage *cats.CatPage
 		mockReturnErr  error
 		expectedStatus int
 		expectedBody   string
+		expectedLink   string
 	}{
 		{
-			name: "success with cats",
-			mockReturnCats: []cats.Cat{
-				{ID: 1, Name: "Whiskers", YearsOfExperience: 5, Breed: "Siamese", Salary: 1000.0},
-				{ID: 2, Name: "Mittens", YearsOfExperience: 3, Breed: "Persian", Salary: 800.0},
+			name:         "success with cats",
+			callsService: true,
+			mockReturnPage: &cats.CatPage{
+				Cats: []cats.Cat{
+					{ID: 1, Name: "Whiskers", YearsOfExperience: 5, Breed: "Siamese", Salary: usd(1000_00)},
+					{ID: 2, Name: "Mittens", YearsOfExperience: 3, Breed: "Persian", Salary: usd(800_00)},
+				},
+				Total: 2,
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
@@ -142,14 +214,83 @@ func TestListCats(t *testing.T) {
 		},
 		{
 			name:           "success with empty list",
-			mockReturnCats: []cats.Cat{},
+			callsService:   true,
+			mockReturnPage: &cats.CatPage{Cats: []cats.Cat{}},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `[]`,
 		},
+		{
+			name:          "filters, sort and next page",
+			query:         "?breed=siamese&min_salary=500&sort=-salary&limit=1",
+			callsService:  true,
+			expectedQuery: cats.ListCatsQuery{Breed: "siamese", MinSalary: &minSalary, Sort: "-salary", Limit: 1},
+			mockReturnPage: &cats.CatPage{
+				Cats:       []cats.Cat{{ID: 1, Name: "Whiskers", Breed: "Siamese", Salary: usd(1000_00)}},
+				Total:      2,
+				NextCursor: "abc",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Whiskers"`,
+			expectedLink:   `</cats?breed=siamese&cursor=abc&limit=1&min_salary=500&sort=-salary>; rel="next"`,
+		},
+		{
+			name:           "exact salary range",
+			query:          "?min_salary=1000.10&max_salary=2000",
+			callsService:   true,
+			expectedQuery:  cats.ListCatsQuery{MinSalary: &exactMin, MaxSalary: &exactMax},
+			mockReturnPage: &cats.CatPage{Cats: []cats.Cat{{ID: 1, Name: "Whiskers", Breed: "Siamese", Salary: usd(1000_10)}}, Total: 1},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"amount":"1000.10"`,
+		},
+		{
+			name:           "salary filter below a cent",
+			query:          "?min_salary=1000.105",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "negative salary filter",
+			query:          "?max_salary=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:          "include deleted",
+			query:         "?include_deleted=true",
+			callsService:  true,
+			expectedQuery: cats.ListCatsQuery{IncludeDeleted: true},
+			mockReturnPage: &cats.CatPage{
+				Cats:  []cats.Cat{{ID: 3, Name: "Ghost", Breed: "Siamese", DeletedAt: &deletedAt}},
+				Total: 1,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"deleted_at":"2024-05-03T09:00:00Z"`,
+		},
+		{
+			name:           "unknown sort",
+			query:          "?sort=breed",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "limit too large",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=garbage",
+			callsService:   true,
+			expectedQuery:  cats.ListCatsQuery{Cursor: "garbage"},
+			mockReturnErr:  utils.ErrInvalidCursor,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid cursor"`,
+		},
 		{
 			name:           "service error",
-			mockReturnCats: []cats.Cat{},
+			callsService:   true,
 			mockReturnErr:  errors.New("database connection error"),
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"error":"failed to get cats"`,
@@ -165,14 +306,21 @@ func TestListCats(t *testing.T) {
 			r := gin.Default()
 			r.GET("/cats", h.ListCats)
 
-			mockSvc.On("GetAllCats").Return(tt.mockReturnCats, tt.mockReturnErr)
+			if tt.callsService {
+				mockSvc.On("GetAllCats", tt.expectedQuery).Return(tt.mockReturnPage, tt.mockReturnErr)
+			}
 
-			req, _ := http.NewRequest(http.MethodGet, "/cats", nil)
+			req, _ := http.NewRequest(http.MethodGet, "/cats"+tt.query, nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedLink, w.Header().Get("Link"))
+			if tt.mockReturnPage != nil {
+				assert.Equal(t, fmt.Sprint(tt.mockReturnPage.Total), w.Header().Get("X-Total-Count"))
+			}
+			mockSvc.AssertExpectations(t)
 		})
 	}
 }
@@ -183,6 +331,8 @@ func TestGetCat(t *testing.T) {
 	tests := []struct {
 		name           string
 		catID          string
+		query          string
+		expectedQuery  cats.GetCatQuery
 		mockReturnCat  *cats.Cat
 		mockReturnErr  error
 		expectedStatus int
@@ -196,12 +346,52 @@ func TestGetCat(t *testing.T) {
 				Name:              "Whiskers",
 				YearsOfExperience: 5,
 				Breed:             "Siamese",
-				Salary:            1000.0,
+				Salary:            usd(1000_00),
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"name":"Whiskers"`,
 		},
+		{
+			name:  "with rank",
+			catID: "1",
+			mockReturnCat: &cats.Cat{
+				ID:                1,
+				Name:              "Whiskers",
+				YearsOfExperience: 5,
+				MissionsCompleted: 12,
+				Rank:              "senior",
+				Breed:             "Siamese",
+				Salary:            usd(70000_00),
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"missions_completed":12,"rank":"senior"`,
+		},
+		{
+			name:          "with current mission",
+			catID:         "1",
+			query:         "?include=current_mission",
+			expectedQuery: cats.GetCatQuery{Include: "current_mission"},
+			mockReturnCat: &cats.Cat{
+				ID:   1,
+				Name: "Whiskers",
+				CurrentMission: &cats.CurrentMission{
+					ID:      3,
+					Name:    "Operation Stealth",
+					Status:  "active",
+					Targets: []cats.MissionTarget{{ID: 7, Name: "Agent Smith", Country: "Russia", Status: "pending"}},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"targets":[{"id":7,"name":"Agent Smith","country":"Russia","notes":"","status":"pending"}]}`,
+		},
+		{
+			name:           "invalid include",
+			catID:          "1",
+			query:          "?include=targets",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":`,
+		},
 		{
 			name:           "cat not found",
 			catID:          "999",
@@ -229,9 +419,9 @@ func TestGetCat(t *testing.T) {
 			r := gin.Default()
 			r.GET("/cats/:id", h.GetCat)
 
-			mockSvc.On("GetCat", mock.AnythingOfType("int64")).Return(tt.mockReturnCat, tt.mockReturnErr)
+			mockSvc.On("GetCat", mock.AnythingOfType("int64"), tt.expectedQuery).Return(tt.mockReturnCat, tt.mockReturnErr)
 
-			req, _ := http.NewRequest(http.MethodGet, "/cats/"+tt.catID, nil)
+			req, _ := http.NewRequest(http.MethodGet, "/cats/"+tt.catID+tt.query, nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
@@ -241,31 +431,248 @@ func TestGetCat(t *testing.T) {
 	}
 }
 
-func TestUpdateSalary(t *testing.T) {
+func TestUpdateCat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		catID          string
+		body           string
+		callsService   bool
+		mockReturnCat  *cats.Cat
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:         "success with partial update",
+			catID:        "1",
+			body:         `{"years_of_experience": 6}`,
+			callsService: true,
+			mockReturnCat: &cats.Cat{
+				ID: 1, Name: "Whiskers", YearsOfExperience: 6, Breed: "Siamese", Salary: usd(1000_00),
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"years_of_experience":6`,
+		},
+		{
+			name:           "invalid cat ID",
+			catID:          "invalid",
+			body:           `{"name": "Tom"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid cat id"`,
+		},
+		{
+			name:           "name too short",
+			catID:          "1",
+			body:           `{"name": "T"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "negative salary",
+			catID:          "1",
+			body:           `{"salary": -1}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: -1.00 must be between 0 and %s", cats.ErrInvalidSalary, cats.MaxSalary),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid salary: -1.00 must be between 0 and 99999999.99"`,
+		},
+		{
+			name:           "salary with fractions of a cent",
+			catID:          "1",
+			body:           `{"salary": {"amount": "1000.005", "currency": "USD"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `has more than 2 decimal places`,
+		},
+		{
+			name:           "unknown breed",
+			catID:          "1",
+			body:           `{"breed": "Persain"}`,
+			callsService:   true,
+			mockReturnErr:  &utils.UnknownBreedError{Breed: "Persain", Suggestions: []string{"Persian"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"suggestions":["Persian"]`,
+		},
+		{
+			name:           "cat not found",
+			catID:          "999",
+			body:           `{"name": "Tom"}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+		{
+			name:           "service error",
+			catID:          "1",
+			body:           `{"name": "Tom"}`,
+			callsService:   true,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to update cat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := cats.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.PATCH("/cats/:id", h.UpdateCat)
+
+			if tt.callsService {
+				mockSvc.On("UpdateCat", mock.AnythingOfType("int64"), mock.Anything).Return(tt.mockReturnCat, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPatch, "/cats/"+tt.catID, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReplaceCat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	valid := cats.CreateCatRequest{
+		Name:              "Whiskers",
+		YearsOfExperience: 7,
+		Breed:             "Siamese",
+		Salary:            &utils.Money{Amount: 2000_00},
+	}
+
 	tests := []struct {
 		name           string
 		catID          string
 		body           any
+		callsService   bool
+		mockReturnCat  *cats.Cat
 		mockReturnErr  error
 		expectedStatus int
 		expectedBody   string
+	}{
+		{
+			name:         "success",
+			catID:        "1",
+			body:         valid,
+			callsService: true,
+			mockReturnCat: &cats.Cat{
+				ID: 1, Name: "Whiskers", YearsOfExperience: 7, Breed: "Siamese", Salary: usd(2000_00),
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"salary":{"amount":"2000.00","currency":"USD"}`,
+		},
+		{
+			name:           "missing required fields",
+			catID:          "1",
+			body:           `{"name": "Whiskers"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "cat not found",
+			catID:          "999",
+			body:           valid,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := cats.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.PUT("/cats/:id", h.ReplaceCat)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				b, _ := json.Marshal(v)
+				bodyBytes = b
+			}
+
+			if tt.callsService {
+				mockSvc.On("ReplaceCat", mock.AnythingOfType("int64"), mock.Anything).Return(tt.mockReturnCat, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPut, "/cats/"+tt.catID, bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateSalary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	old := usd(1000_00)
+	applied := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		catID            string
+		body             any
+		callsService     bool
+		mockReturnChange *cats.SalaryChange
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
 	}{
 		{
 			name:  "success",
 			catID: "1",
 			body: cats.UpdateSalaryRequest{
-				Salary: 1500.0,
+				Salary: &utils.Money{Amount: 1500_00},
+			},
+			callsService: true,
+			mockReturnChange: &cats.SalaryChange{
+				ID: 1, CatID: 1, OldSalary: &old, NewSalary: usd(1500_00), EffectiveDate: "2024-06-15", AppliedAt: &applied,
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"message":"salary updated successfully"`,
 		},
+		{
+			name:  "scheduled",
+			catID: "1",
+			body: cats.UpdateSalaryRequest{
+				Salary:        &utils.Money{Amount: 1500_00},
+				EffectiveDate: "2099-07-01",
+				Reason:        "Annual raise",
+				Actor:         "m.hale",
+			},
+			callsService: true,
+			mockReturnChange: &cats.SalaryChange{
+				ID: 2, CatID: 1, NewSalary: usd(1500_00), EffectiveDate: "2099-07-01", Reason: "Annual raise", Actor: "m.hale",
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"message":"salary change scheduled"`,
+		},
 		{
 			name:           "invalid cat ID",
 			catID:          "invalid",
-			body:           cats.UpdateSalaryRequest{Salary: 1500.0},
+			body:           cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 1500_00}},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `"error":"invalid cat id"`,
 		},
@@ -276,13 +683,42 @@ func TestUpdateSalary(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `"error"`,
 		},
+		{
+			name:           "invalid effective date",
+			catID:          "1",
+			body:           `{"salary": 1500, "effective_date": "01/07/2024"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "salary too large",
+			catID:          "1",
+			body:           `{"salary": {"amount": "100000000.00", "currency": "EUR"}}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: 100000000.00 must be between 0 and %s", cats.ErrInvalidSalary, cats.MaxSalary),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid salary: 100000000.00 must be between 0 and 99999999.99"`,
+		},
+		{
+			name:  "effective date in the past",
+			catID: "1",
+			body: cats.UpdateSalaryRequest{
+				Salary:        &utils.Money{Amount: 1500_00},
+				EffectiveDate: "2020-01-01",
+			},
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: effective_date 2020-01-01 is in the past", cats.ErrInvalidSalaryChange),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid salary change: effective_date 2020-01-01 is in the past"`,
+		},
 		{
 			name:  "cat not found",
 			catID: "999",
 			body: cats.UpdateSalaryRequest{
-				Salary: 1500.0,
+				Salary: &utils.Money{Amount: 1500_00},
 			},
-			mockReturnErr:  errors.New("cat not found with id 999"),
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   `"error":"cat not found with id 999"`,
 		},
@@ -290,12 +726,24 @@ func TestUpdateSalary(t *testing.T) {
 			name:  "service error",
 			catID: "1",
 			body: cats.UpdateSalaryRequest{
-				Salary: 1500.0,
+				Salary: &utils.Money{Amount: 1500_00},
 			},
+			callsService:   true,
 			mockReturnErr:  errors.New("database error"),
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"error":"failed to update salary"`,
 		},
+		{
+			name:  "other not found error",
+			catID: "1",
+			body: cats.UpdateSalaryRequest{
+				Salary: &utils.Money{Amount: 1500_00},
+			},
+			callsService:   true,
+			mockReturnErr:  errors.New("relation salary_changes not found"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to update salary"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -316,8 +764,12 @@ func TestUpdateSalary(t *testing.T) {
 				bodyBytes = b
 			}
 
-			if tt.catID != "invalid" && tt.body != `{"salary": "invalid"}` {
-				mockSvc.On("UpdateSalary", mock.AnythingOfType("int64"), mock.AnythingOfType("float64")).Return(tt.mockReturnErr)
+			if tt.callsService {
+				var change any
+				if tt.mockReturnChange != nil {
+					change = tt.mockReturnChange
+				}
+				mockSvc.On("UpdateSalary", mock.AnythingOfType("int64"), mock.AnythingOfType("cats.UpdateSalaryRequest")).Return(change, tt.mockReturnErr)
 			}
 
 			req, _ := http.NewRequest(http.MethodPut, "/cats/"+tt.catID+"/salary", bytes.NewReader(bodyBytes))
@@ -328,30 +780,163 @@ func TestUpdateSalary(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
 		})
 	}
 }
 
-func TestDeleteCat(t *testing.T) {
+func TestGetSalaryHistory(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	old := usd(1000_00)
+	applied := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name           string
 		catID          string
+		mockChanges    []cats.SalaryChange
 		mockReturnErr  error
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
-			name:           "success",
+			name:  "success",
+			catID: "1",
+			mockChanges: []cats.SalaryChange{
+				{ID: 2, CatID: 1, NewSalary: usd(2000_00), EffectiveDate: "2099-07-01", Reason: "Annual raise"},
+				{ID: 1, CatID: 1, OldSalary: &old, NewSalary: usd(1500_00), EffectiveDate: "2024-06-15", Actor: "m.hale", AppliedAt: &applied},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"cat_id":1,"old_salary":{"amount":"1000.00","currency":"USD"},"new_salary":{"amount":"1500.00","currency":"USD"},"effective_date":"2024-06-15","reason":"","actor":"m.hale"`,
+		},
+		{
+			name:           "cat not found",
+			catID:          "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found"`,
+		},
+		{
+			name:           "service error",
 			catID:          "1",
-			mockReturnErr:  nil,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch salary history"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := cats.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/cats/:id/salary-history", h.GetSalaryHistory)
+
+			var changes any
+			if tt.mockChanges != nil {
+				changes = tt.mockChanges
+			}
+			mockSvc.On("GetSalaryHistory", mock.AnythingOfType("int64")).Return(changes, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/cats/"+tt.catID+"/salary-history", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestSalaryScheduleIntervalFromEnv(t *testing.T) {
+	t.Setenv("SALARY_SCHEDULE_INTERVAL", "")
+	assert.Equal(t, cats.DefaultSalaryScheduleInterval, cats.SalaryScheduleIntervalFromEnv())
+
+	t.Setenv("SALARY_SCHEDULE_INTERVAL", "15m")
+	assert.Equal(t, 15*time.Minute, cats.SalaryScheduleIntervalFromEnv())
+}
+
+func TestDeleteCat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reassignTo := int64(2)
+	tests := []struct {
+		name             string
+		target           string
+		callsService     bool
+		expectedReassign *int64
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
+	}{
+		{
+			name:           "success",
+			target:         "1",
+			callsService:   true,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"message":"cat deleted"`,
 		},
+		{
+			name:             "reassigns missions",
+			target:           "1?reassign_to=2",
+			callsService:     true,
+			expectedReassign: &reassignTo,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"message":"cat deleted"`,
+		},
+		{
+			name:           "invalid reassign_to",
+			target:         "1?reassign_to=tom",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid reassign_to"`,
+		},
+		{
+			name:           "not found",
+			target:         "999",
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+		{
+			name:           "on incomplete missions",
+			target:         "1",
+			callsService:   true,
+			mockReturnErr:  &cats.MissionsInProgressError{CatID: 1, MissionIDs: []int64{4, 7}},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"mission_ids":[4,7]`,
+		},
+		{
+			name:             "reassign_to cat is busy",
+			target:           "1?reassign_to=2",
+			callsService:     true,
+			expectedReassign: &reassignTo,
+			mockReturnErr:    fmt.Errorf("%w: cat 2 is on mission 5", cats.ErrCatBusy),
+			expectedStatus:   http.StatusConflict,
+			expectedBody:     `cat 2 is on mission 5`,
+		},
+		{
+			name:             "reassign_to cat rank too low",
+			target:           "1?reassign_to=2",
+			callsService:     true,
+			expectedReassign: &reassignTo,
+			mockReturnErr:    fmt.Errorf("%w: cat 2 is a recruit and may take missions of at most 2 targets", cats.ErrRankTooLow),
+			expectedStatus:   http.StatusConflict,
+			expectedBody:     `cat rank too low`,
+		},
+		{
+			name:             "reassign to itself",
+			target:           "2?reassign_to=2",
+			callsService:     true,
+			expectedReassign: &reassignTo,
+			mockReturnErr:    fmt.Errorf("%w: cat 2 cannot take over its own missions", cats.ErrInvalidReassign),
+			expectedStatus:   http.StatusBadRequest,
+			expectedBody:     `cannot take over its own missions`,
+		},
 		{
 			name:           "service error",
-			catID:          "1",
+			target:         "1",
+			callsService:   true,
 			mockReturnErr:  errors.New("database error"),
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"error":"failed to delete cat"`,
@@ -367,9 +952,157 @@ func TestDeleteCat(t *testing.T) {
 			r := gin.Default()
 			r.DELETE("/cats/:id", h.DeleteCat)
 
-			mockSvc.On("DeleteCat", mock.AnythingOfType("int64")).Return(tt.mockReturnErr)
+			if tt.callsService {
+				mockSvc.On("DeleteCat", mock.AnythingOfType("int64"), tt.expectedReassign).Return(tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodDelete, "/cats/"+tt.target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestoreCat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		catID          string
+		mockReturnCat  *cats.Cat
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			catID:          "1",
+			mockReturnCat:  &cats.Cat{ID: 1, Name: "Whiskers", Breed: "Siamese"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Whiskers"`,
+		},
+		{
+			name:           "no deleted cat",
+			catID:          "2",
+			mockReturnErr:  fmt.Errorf("%w: no deleted cat with id 2", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `no deleted cat with id 2`,
+		},
+		{
+			name:           "service error",
+			catID:          "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to restore cat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := cats.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/cats/:id/restore", h.RestoreCat)
+
+			if tt.mockReturnCat != nil {
+				mockSvc.On("RestoreCat", mock.AnythingOfType("int64")).Return(tt.mockReturnCat, nil)
+			} else {
+				mockSvc.On("RestoreCat", mock.AnythingOfType("int64")).Return(nil, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/cats/"+tt.catID+"/restore", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetCatMissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	released := time.Date(2024, 5, 10, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		catID           string
+		query           string
+		expectedQuery   cats.ListCatMissionsQuery
+		mockAssignments []cats.MissionAssignment
+		mockReturnErr   error
+		expectedStatus  int
+		expectedBody    string
+	}{
+		{
+			name:  "success",
+			catID: "1",
+			mockAssignments: []cats.MissionAssignment{
+				{MissionID: 2, MissionName: "Operation Night", MissionStatus: "active"},
+				{MissionID: 1, MissionName: "Operation Stealth", MissionStatus: "completed", ReleasedAt: &released, Reason: "completed"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"mission_status":"completed","assigned_at":"0001-01-01T00:00:00Z","released_at":"2024-05-10T17:00:00Z","reason":"completed"}]`,
+		},
+		{
+			name:            "filtered by status",
+			catID:           "1",
+			query:           "?status=active&status=on_hold",
+			expectedQuery:   cats.ListCatMissionsQuery{Status: []string{"active", "on_hold"}},
+			mockAssignments: []cats.MissionAssignment{{MissionID: 2, MissionName: "Operation Night", MissionStatus: "active"}},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `"mission_status":"active"`,
+		},
+		{
+			name:           "invalid status",
+			catID:          "1",
+			query:          "?status=done",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":`,
+		},
+		{
+			name:            "no missions",
+			catID:           "1",
+			mockAssignments: []cats.MissionAssignment{},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `[]`,
+		},
+		{
+			name:           "cat not found",
+			catID:          "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", cats.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found"`,
+		},
+		{
+			name:           "service error",
+			catID:          "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch mission history"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := cats.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/cats/:id/missions", h.GetCatMissions)
+
+			var assignments any
+			if tt.mockAssignments != nil {
+				assignments = tt.mockAssignments
+			}
+			mockSvc.On("GetCatMissions", mock.AnythingOfType("int64"), tt.expectedQuery).Return(assignments, tt.mockReturnErr)
 
-			req, _ := http.NewRequest(http.MethodDelete, "/cats/"+tt.catID, nil)
+			req, _ := http.NewRequest(http.MethodGet, "/cats/"+tt.catID+"/missions"+tt.query, nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
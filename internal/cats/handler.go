@@ -1,11 +1,11 @@
 package cats
 
 import (
-	"database/sql"
 	"errors"
 	"net/http"
 	"strconv"
-	"strings"
+
+	"spy-cats/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,10 +16,15 @@ type Handler struct {
 
 type CatService interface {
 	CreateCat(req CreateCatRequest) (int64, error)
-	GetAllCats() ([]Cat, error)
-	GetCat(id int64) (*Cat, error)
-	UpdateSalary(id int64, salary float64) error
-	DeleteCat(id int64) error
+	GetAllCats(q ListCatsQuery) (*CatPage, error)
+	GetCat(id int64, q GetCatQuery) (*Cat, error)
+	UpdateCat(id int64, req UpdateCatRequest) (*Cat, error)
+	ReplaceCat(id int64, req CreateCatRequest) (*Cat, error)
+	UpdateSalary(id int64, req UpdateSalaryRequest) (*SalaryChange, error)
+	GetSalaryHistory(id int64) ([]SalaryChange, error)
+	DeleteCat(id int64, reassignTo *int64) error
+	RestoreCat(id int64) (*Cat, error)
+	GetCatMissions(id int64, q ListCatMissionsQuery) ([]MissionAssignment, error)
 }
 
 func NewHandler(service CatService) *Handler {
@@ -34,7 +39,7 @@ func NewHandler(service CatService) *Handler {
 // @Produce      json
 // @Param        cat  body      CreateCatRequest  true  "Cat information"
 // @Success      201  {object}  map[string]int64  "Successfully created cat"
-// @Failure      400  {object}  map[string]string "Invalid input"
+// @Failure      400  {object}  map[string]any    "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions"
 // @Router       /cats [post]
 func (h *Handler) CreateCat(c *gin.Context) {
 	var req CreateCatRequest
@@ -45,6 +50,11 @@ func (h *Handler) CreateCat(c *gin.Context) {
 
 	id, err := h.service.CreateCat(req)
 	if err != nil {
+		var unknown *utils.UnknownBreedError
+		if errors.As(err, &unknown) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "suggestions": unknown.Suggestions})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -52,36 +62,71 @@ func (h *Handler) CreateCat(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
-// ListCats retrieves all spy cats
-// @Summary      List all spy cats
-// @Description  Get a list of all spy cats in the system
+// ListCats retrieves spy cats page by page
+// @Summary      List spy cats
+// @Description  Get a page of spy cats, optionally filtered and sorted. Pass the X-Next-Cursor value as cursor to get the next page.
 // @Tags         cats
 // @Produce      json
+// @Param        breed           query     string  false  "Breed (case-insensitive)"
+// @Param        name            query     string  false  "Name prefix (case-insensitive)"
+// @Param        currency        query     string  false  "Salary currency (ISO 4217)"
+// @Param        min_salary      query     string  false  "Minimum salary, a decimal amount such as 1000.10"
+// @Param        max_salary      query     string  false  "Maximum salary, a decimal amount such as 90000.00"
+// @Param        min_experience  query     int     false  "Minimum years of experience"
+// @Param        max_experience  query     int     false  "Maximum years of experience"
+// @Param        sort            query     string  false  "Sort order, prefix with - for descending" Enums(id, -id, name, -name, salary, -salary, years_of_experience, -years_of_experience)
+// @Param        limit           query     int     false  "Page size (default 50, max 200)"
+// @Param        cursor          query     string  false  "Cursor from a previous page"
+// @Param        include_deleted query     bool    false  "Also list soft-deleted cats"
 // @Success      200  {array}   Cat "List of cats"
+// @Header       200  {int}     X-Total-Count  "Number of cats matching the filters"
+// @Header       200  {string}  X-Next-Cursor  "Cursor of the next page, absent on the last page"
+// @Header       200  {string}  Link           "URL of the next page with rel=next"
+// @Failure      400  {object}  map[string]string "Invalid query"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /cats [get]
 func (h *Handler) ListCats(c *gin.Context) {
-	cats, err := h.service.GetAllCats()
+	var q ListCatsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.GetAllCats(q)
 	if err != nil {
+		if errors.Is(err, ErrInvalidQuery) || errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cats"})
 		return
 	}
-	c.JSON(http.StatusOK, cats)
+
+	utils.SetPageHeaders(c, page.Total, page.NextCursor)
+	c.JSON(http.StatusOK, page.Cats)
 }
 
 // GetCat retrieves a specific spy cat by ID
 // @Summary      Get a spy cat
-// @Description  Get a spy cat by its ID
+// @Description  Get a spy cat by its ID. include=current_mission embeds the incomplete mission the cat is on with its targets.
 // @Tags         cats
 // @Produce      json
-// @Param        id   path      int  true  "Cat ID"
+// @Param        id       path      int     true   "Cat ID"
+// @Param        include  query     string  false  "Embed related data" Enums(current_mission)
 // @Success      200  {object}  Cat "Cat information"
+// @Failure      400  {object}  map[string]string "Invalid query"
 // @Failure      404  {object}  map[string]string "Cat not found"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /cats/{id} [get]
 func (h *Handler) GetCat(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	cat, err := h.service.GetCat(id)
+	var q GetCatQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.service.GetCat(id, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cat"})
 		return
@@ -93,16 +138,99 @@ func (h *Handler) GetCat(c *gin.Context) {
 	c.JSON(http.StatusOK, cat)
 }
 
+// UpdateCat partially updates a spy cat
+// @Summary      Update a spy cat
+// @Description  Update some of a spy cat's fields; omitted fields are left unchanged
+// @Tags         cats
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int               true  "Cat ID"
+// @Param        cat  body      UpdateCatRequest  true  "Fields to update"
+// @Success      200  {object}  Cat               "Updated cat"
+// @Failure      400  {object}  map[string]any    "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions"
+// @Failure      404  {object}  map[string]string "Cat not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id} [patch]
+func (h *Handler) UpdateCat(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cat id"})
+		return
+	}
+
+	var req UpdateCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.service.UpdateCat(id, req)
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// ReplaceCat replaces a spy cat's profile
+// @Summary      Replace a spy cat
+// @Description  Replace all fields of a spy cat
+// @Tags         cats
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int               true  "Cat ID"
+// @Param        cat  body      CreateCatRequest  true  "Cat information"
+// @Success      200  {object}  Cat               "Updated cat"
+// @Failure      400  {object}  map[string]any    "Invalid input or salary outside the band of the cat's rank; unknown breeds include did-you-mean suggestions"
+// @Failure      404  {object}  map[string]string "Cat not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id} [put]
+func (h *Handler) ReplaceCat(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cat id"})
+		return
+	}
+
+	var req CreateCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.service.ReplaceCat(id, req)
+	if err != nil {
+		h.respondUpdateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+func (h *Handler) respondUpdateError(c *gin.Context, err error) {
+	var unknown *utils.UnknownBreedError
+	switch {
+	case errors.As(err, &unknown):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "suggestions": unknown.Suggestions})
+	case errors.Is(err, ErrInvalidSalary):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrCatNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found with id " + c.Param("id")})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update cat"})
+	}
+}
+
 // UpdateSalary updates a spy cat's salary
 // @Summary      Update cat salary
-// @Description  Update the salary of a specific spy cat
+// @Description  Update the salary of a specific spy cat and record the change in its salary history. A future effective_date schedules the change, which is applied automatically on that date.
 // @Tags         cats
 // @Accept       json
 // @Produce      json
 // @Param        id      path      int                  true  "Cat ID"
 // @Param        salary  body      UpdateSalaryRequest  true  "New salary information"
-// @Success      200     {object}  map[string]string    "Salary updated successfully"
-// @Failure      400     {object}  map[string]string    "Invalid input"
+// @Success      200     {object}  map[string]any       "Salary updated successfully, with the recorded change"
+// @Success      202     {object}  map[string]any       "Salary change scheduled, with the recorded change"
+// @Failure      400     {object}  map[string]string    "Invalid input, salary outside the band of the cat's rank, or effective date in the past"
 // @Failure      404     {object}  map[string]string    "Cat not found"
 // @Failure      500     {object}  map[string]string    "Internal server error"
 // @Router       /cats/{id}/salary [patch]
@@ -119,9 +247,13 @@ func (h *Handler) UpdateSalary(c *gin.Context) {
 		return
 	}
 
-	err = h.service.UpdateSalary(id, req.Salary)
+	change, err := h.service.UpdateSalary(id, req)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, ErrInvalidSalaryChange) || errors.Is(err, ErrInvalidSalary) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrCatNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "cat not found with id " + c.Param("id")})
 			return
 		}
@@ -129,22 +261,134 @@ func (h *Handler) UpdateSalary(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "salary updated successfully"})
+	if change.AppliedAt == nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "salary change scheduled", "change": change})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "salary updated successfully", "change": change})
+}
+
+// GetSalaryHistory lists the salary changes of a spy cat
+// @Summary      Cat salary history
+// @Description  Get every salary change of a spy cat with the old and new salary, effective date, reason and actor, latest effective date first. Scheduled changes are included without old_salary and applied_at.
+// @Tags         cats
+// @Produce      json
+// @Param        id   path      int  true  "Cat ID"
+// @Success      200  {array}   SalaryChange      "Salary history"
+// @Failure      404  {object}  map[string]string "Cat not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id}/salary-history [get]
+func (h *Handler) GetSalaryHistory(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	changes, err := h.service.GetSalaryHistory(id)
+	if err != nil {
+		if errors.Is(err, ErrCatNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch salary history"})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
 }
 
 // DeleteCat deletes a spy cat
 // @Summary      Delete a spy cat
-// @Description  Delete a spy cat by its ID
+// @Description  Soft delete a spy cat by its ID. Deleted cats are hidden until restored and purged after the retention window. A cat on an incomplete mission is only deleted if reassign_to names a cat without incomplete missions to hand the missions to.
 // @Tags         cats
-// @Param        id  path      int  true  "Cat ID"
+// @Produce      json
+// @Param        id           path      int  true   "Cat ID"
+// @Param        reassign_to  query     int  false  "Cat ID that takes over the incomplete missions"
 // @Success      200 {object}  map[string]string "Cat deleted successfully"
+// @Failure      400 {object}  map[string]string "Invalid reassign_to"
+// @Failure      404 {object}  map[string]string "Cat or reassign_to cat not found"
+// @Failure      409 {object}  map[string]any    "Cat has incomplete missions, listed in mission_ids, or reassign_to cat is busy or its rank does not allow the missions' targets"
 // @Failure      500 {object}  map[string]string "Internal server error"
 // @Router       /cats/{id} [delete]
 func (h *Handler) DeleteCat(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err := h.service.DeleteCat(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete cat"})
+
+	var reassignTo *int64
+	if v := c.Query("reassign_to"); v != "" {
+		to, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to"})
+			return
+		}
+		reassignTo = &to
+	}
+
+	if err := h.service.DeleteCat(id, reassignTo); err != nil {
+		var inProgress *MissionsInProgressError
+		switch {
+		case errors.As(err, &inProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "mission_ids": inProgress.MissionIDs})
+		case errors.Is(err, ErrCatNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrCatBusy), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidReassign):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete cat"})
+		}
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "cat deleted"})
 }
+
+// RestoreCat restores a deleted spy cat
+// @Summary      Restore a spy cat
+// @Description  Undelete a soft-deleted spy cat that has not been purged yet
+// @Tags         cats
+// @Produce      json
+// @Param        id   path      int  true  "Cat ID"
+// @Success      200  {object}  Cat               "Restored cat"
+// @Failure      404  {object}  map[string]string "No deleted cat with this ID"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id}/restore [post]
+func (h *Handler) RestoreCat(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	cat, err := h.service.RestoreCat(id)
+	if err != nil {
+		if errors.Is(err, ErrCatNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore cat"})
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// GetCatMissions lists the missions a spy cat was assigned to
+// @Summary      Cat mission history
+// @Description  Get every mission the cat was assigned to with when and why each assignment ended, most recent first. Repeat status to match any of several mission statuses.
+// @Tags         cats
+// @Produce      json
+// @Param        id      path      int       true   "Cat ID"
+// @Param        status  query     []string  false  "Mission status" collectionFormat(multi) Enums(planned, active, on_hold, completed, aborted, failed)
+// @Success      200  {array}   MissionAssignment "Assignment history"
+// @Failure      400  {object}  map[string]string "Invalid query"
+// @Failure      404  {object}  map[string]string "Cat not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id}/missions [get]
+func (h *Handler) GetCatMissions(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var q ListCatMissionsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignments, err := h.service.GetCatMissions(id, q)
+	if err != nil {
+		if errors.Is(err, ErrCatNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch mission history"})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
@@ -1,23 +1,127 @@
 package cats
 
-// Cat represents a spy cat
+import (
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+// MaxSalary is the largest salary the NUMERIC(10,2) salary column holds
+const MaxSalary utils.Amount = 99_999_999_99
+
+// Cat represents a spy cat. Rank is derived from the years of experience
+// and completed missions, DeletedAt is set for soft-deleted cats and
+// CurrentMission is only loaded on request.
 type Cat struct {
-	ID                int64   `json:"id" example:"1"`
-	Name              string  `json:"name" example:"Whiskers"`
-	YearsOfExperience int     `json:"years_of_experience" example:"5"`
-	Breed             string  `json:"breed" example:"Siamese"`
-	Salary            float64 `json:"salary" example:"50000.0"`
+	ID                int64           `json:"id" example:"1"`
+	Name              string          `json:"name" example:"Whiskers"`
+	YearsOfExperience int             `json:"years_of_experience" example:"5"`
+	MissionsCompleted int             `json:"missions_completed" example:"12"`
+	Rank              string          `json:"rank" example:"senior"`
+	Breed             string          `json:"breed" example:"Siamese"`
+	Salary            utils.Money     `json:"salary"`
+	DeletedAt         *time.Time      `json:"deleted_at,omitempty" example:"2024-05-01T12:00:00Z"`
+	CurrentMission    *CurrentMission `json:"current_mission,omitempty"`
 }
 
-// CreateCatRequest represents the request to create a new cat
+// CurrentMission is the incomplete mission a cat is on
+type CurrentMission struct {
+	ID              int64           `json:"id" example:"1"`
+	Name            string          `json:"name" example:"Operation Stealth"`
+	Status          string          `json:"status" enums:"planned,active,on_hold" example:"active"`
+	StatusChangedAt time.Time       `json:"status_changed_at" example:"2024-05-02T08:30:00Z"`
+	Targets         []MissionTarget `json:"targets"`
+}
+
+// MissionTarget is a target of a cat's current mission
+type MissionTarget struct {
+	ID      int64  `json:"id" example:"1"`
+	Name    string `json:"name" example:"Agent Smith"`
+	Country string `json:"country" example:"Russia"`
+	Notes   string `json:"notes" example:"High priority target"`
+	Status  string `json:"status" enums:"pending,completed,failed" example:"pending"`
+}
+
+// GetCatQuery represents the options of a single cat lookup
+type GetCatQuery struct {
+	Include string `form:"include" binding:"omitempty,oneof=current_mission" example:"current_mission"`
+}
+
+// CreateCatRequest represents the request to create a new cat. A salary
+// without a currency, or given as a bare amount, is in the default currency.
 type CreateCatRequest struct {
-	Name              string  `json:"name" binding:"required,min=2,max=50" example:"Whiskers"`
-	YearsOfExperience int     `json:"years_of_experience" binding:"required,gte=0,lte=50" example:"5"`
-	Breed             string  `json:"breed" binding:"required" example:"Siamese"`
-	Salary            float64 `json:"salary" binding:"required,gte=0" example:"50000.0"`
+	Name              string       `json:"name" binding:"required,min=2,max=50" example:"Whiskers"`
+	YearsOfExperience int          `json:"years_of_experience" binding:"required,gte=0,lte=50" example:"5"`
+	Breed             string       `json:"breed" binding:"required" example:"Siamese"`
+	Salary            *utils.Money `json:"salary" binding:"required"`
 }
 
-// UpdateSalaryRequest represents the request to update a cat's salary
+// UpdateSalaryRequest represents the request to update a cat's salary. A
+// future effective date schedules the change instead of applying it now.
 type UpdateSalaryRequest struct {
-	Salary float64 `json:"salary" binding:"required,gte=0" example:"60000.0"`
+	Salary        *utils.Money `json:"salary" binding:"required"`
+	EffectiveDate string       `json:"effective_date" binding:"omitempty,datetime=2006-01-02" example:"2024-07-01"`
+	Reason        string       `json:"reason" binding:"max=200" example:"Annual raise"`
+	Actor         string       `json:"actor" binding:"max=100" example:"m.hale"`
+}
+
+// SalaryChange is an entry of a cat's salary history. OldSalary and
+// AppliedAt are unset while a scheduled change has not taken effect yet.
+type SalaryChange struct {
+	ID            int64        `json:"id" example:"1"`
+	CatID         int64        `json:"cat_id" example:"1"`
+	OldSalary     *utils.Money `json:"old_salary,omitempty"`
+	NewSalary     utils.Money  `json:"new_salary"`
+	EffectiveDate string       `json:"effective_date" example:"2024-07-01"`
+	Reason        string       `json:"reason" example:"Annual raise"`
+	Actor         string       `json:"actor" example:"m.hale"`
+	CreatedAt     time.Time    `json:"created_at" example:"2024-06-15T10:00:00Z"`
+	AppliedAt     *time.Time   `json:"applied_at,omitempty" example:"2024-07-01T00:05:00Z"`
+}
+
+// UpdateCatRequest represents a partial update of a cat; omitted fields are left unchanged
+type UpdateCatRequest struct {
+	Name              *string      `json:"name" binding:"omitempty,min=2,max=50" example:"Whiskers"`
+	YearsOfExperience *int         `json:"years_of_experience" binding:"omitempty,gte=0,lte=50" example:"6"`
+	Breed             *string      `json:"breed" binding:"omitempty,min=1" example:"Siamese"`
+	Salary            *utils.Money `json:"salary"`
+}
+
+// MissionAssignment is a period during which the cat worked a mission.
+// ReleasedAt and Reason are unset while the cat is still assigned.
+type MissionAssignment struct {
+	MissionID     int64      `json:"mission_id" example:"1"`
+	MissionName   string     `json:"mission_name" example:"Operation Stealth"`
+	MissionStatus string     `json:"mission_status" enums:"planned,active,on_hold,completed,aborted,failed" example:"completed"`
+	AssignedAt    time.Time  `json:"assigned_at" example:"2024-05-01T12:00:00Z"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty" example:"2024-05-10T17:00:00Z"`
+	Reason        string     `json:"reason,omitempty" enums:"unassigned,reassigned,completed,aborted,failed,mission_deleted,cat_deleted" example:"completed"`
+}
+
+// ListCatMissionsQuery represents the filters of a cat's mission history.
+// Status matches any of the given mission statuses.
+type ListCatMissionsQuery struct {
+	Status []string `form:"status" binding:"omitempty,dive,oneof=planned active on_hold completed aborted failed" example:"active"`
+}
+
+// ListCatsQuery represents the filters, sort order and page of a cat listing
+type ListCatsQuery struct {
+	Breed          string        `form:"breed" example:"Siamese"`
+	Name           string        `form:"name" example:"Whis"`
+	Currency       string        `form:"currency" binding:"omitempty,len=3" example:"USD"`
+	MinSalary      *utils.Amount `form:"min_salary" binding:"omitempty,gte=0" swaggertype:"string" example:"1000.00"`
+	MaxSalary      *utils.Amount `form:"max_salary" binding:"omitempty,gte=0" swaggertype:"string" example:"90000.00"`
+	MinExperience  *int          `form:"min_experience" binding:"omitempty,gte=0" example:"2"`
+	MaxExperience  *int          `form:"max_experience" binding:"omitempty,gte=0" example:"10"`
+	Sort           string        `form:"sort" binding:"omitempty,oneof=id -id name -name salary -salary years_of_experience -years_of_experience" example:"-salary"`
+	Limit          int           `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Cursor         string        `form:"cursor"`
+	IncludeDeleted bool          `form:"include_deleted" example:"true"`
+}
+
+// CatPage is one page of a cat listing
+type CatPage struct {
+	Cats       []Cat
+	Total      int
+	NextCursor string
 }
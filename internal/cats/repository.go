@@ -1,64 +1,467 @@
 package cats
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"spy-cats/internal/database"
+	"spy-cats/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+// catColumns selects a cat along with the number of missions it completed,
+// as counted by scoring in cat_stats
+const catColumns = `id, name, years_of_experience, breed, salary, currency, deleted_at,
+	COALESCE((SELECT s.missions_completed FROM cat_stats s WHERE s.cat_id = cats.id), 0)`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCat(row scanner) (*Cat, error) {
+	var c Cat
+	if err := row.Scan(
+		&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary.Amount, &c.Salary.Currency, &c.DeletedAt, &c.MissionsCompleted,
+	); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// openMissionStatuses matches missions that are not closed
+const openMissionStatuses = `('planned', 'active', 'on_hold')`
 
 type Repository struct {
-	db *sql.DB
+	db database.Conn
 }
 
 func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: database.NewConn(db)}
+}
+
+// InTx runs fn with a repository bound to a single transaction, committing
+// if fn succeeds
+func (r *Repository) InTx(fn func(tx CatRepository) error) error {
+	return r.db.InTx(func(tx database.Conn) error {
+		return fn(&Repository{db: tx})
+	})
 }
 
 func (r *Repository) Create(cat Cat) (int64, error) {
 	var id int64
 	err := r.db.QueryRow(
-		`INSERT INTO cats (name, years_of_experience, breed, salary)
-		 VALUES ($1, $2, $3, $4) RETURNING id`,
-		cat.Name, cat.YearsOfExperience, cat.Breed, cat.Salary,
+		`INSERT INTO cats (name, years_of_experience, breed, salary, currency)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		cat.Name, cat.YearsOfExperience, cat.Breed, cat.Salary.Amount, cat.Salary.Currency,
 	).Scan(&id)
 	return id, err
 }
 
-func (r *Repository) GetAll() ([]Cat, error) {
-	rows, err := r.db.Query(`SELECT id, name, years_of_experience, breed, salary FROM cats ORDER BY id`)
+// catSortValues extracts the cursor value of each sortable column
+var catSortValues = map[string]func(Cat) string{
+	"id":                  func(c Cat) string { return strconv.FormatInt(c.ID, 10) },
+	"name":                func(c Cat) string { return c.Name },
+	"salary":              func(c Cat) string { return c.Salary.Amount.String() },
+	"years_of_experience": func(c Cat) string { return strconv.Itoa(c.YearsOfExperience) },
+}
+
+// List returns one page of cats matching q, ordered by q.Sort with id as a
+// tiebreaker, along with the number of cats matching the filters. Deleted
+// cats are only listed if q.IncludeDeleted is set.
+func (r *Repository) List(q ListCatsQuery, cursor *utils.Cursor) (*CatPage, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if !q.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if q.Breed != "" {
+		add("lower(breed) = lower($%d)", q.Breed)
+	}
+	if q.Name != "" {
+		add(`lower(name) LIKE lower($%d) || '%%'`, utils.EscapeLike(q.Name))
+	}
+	if q.Currency != "" {
+		add("currency = upper($%d)", q.Currency)
+	}
+	if q.MinSalary != nil {
+		add("salary >= $%d", *q.MinSalary)
+	}
+	if q.MaxSalary != nil {
+		add("salary <= $%d", *q.MaxSalary)
+	}
+	if q.MinExperience != nil {
+		add("years_of_experience >= $%d", *q.MinExperience)
+	}
+	if q.MaxExperience != nil {
+		add("years_of_experience <= $%d", *q.MaxExperience)
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	page := CatPage{Cats: []Cat{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM cats`+filter, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	column, desc := utils.SortSpec(q.Sort)
+	if cursor != nil {
+		where = append(where, utils.KeysetCondition(column, desc, len(args)+1))
+		args = append(args, cursor.Value, cursor.ID)
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	args = append(args, q.Limit+1)
+	query := fmt.Sprintf(
+		`SELECT %s FROM cats%s ORDER BY %s %s, id %s LIMIT $%d`,
+		catColumns, filter, column, dir, dir, len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var cats []Cat
 	for rows.Next() {
-		var c Cat
-		if err := rows.Scan(&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary); err != nil {
+		c, err := scanCat(rows)
+		if err != nil {
 			return nil, err
 		}
-		cats = append(cats, c)
+		page.Cats = append(page.Cats, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Cats) > q.Limit {
+		page.Cats = page.Cats[:q.Limit]
+		last := page.Cats[q.Limit-1]
+		page.NextCursor = utils.EncodeCursor(utils.Cursor{
+			Sort:  q.Sort,
+			Value: catSortValues[column](last),
+			ID:    last.ID,
+		})
 	}
-	return cats, nil
+	return &page, nil
 }
 
+// GetByID returns the cat, or nil if it does not exist or was deleted
 func (r *Repository) GetByID(id int64) (*Cat, error) {
-	var c Cat
-	err := r.db.QueryRow(
-		`SELECT id, name, years_of_experience, breed, salary FROM cats WHERE id=$1`, id,
-	).Scan(&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary)
+	c, err := scanCat(r.db.QueryRow(`SELECT `+catColumns+` FROM cats WHERE id=$1 AND deleted_at IS NULL`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// Update overwrites all editable fields of the cat and returns the stored row
+func (r *Repository) Update(cat Cat) (*Cat, error) {
+	c, err := scanCat(r.db.QueryRow(
+		`UPDATE cats SET name=$1, years_of_experience=$2, breed=$3, salary=$4, currency=$5
+		 WHERE id=$6 AND deleted_at IS NULL
+		 RETURNING `+catColumns,
+		cat.Name, cat.YearsOfExperience, cat.Breed, cat.Salary.Amount, cat.Salary.Currency, cat.ID,
+	))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return &c, err
+	return c, err
+}
+
+// SetSalary sets the salary of the cat and returns the previous one. found
+// is false if the cat does not exist or was deleted.
+func (r *Repository) SetSalary(id int64, salary utils.Money) (old utils.Money, found bool, err error) {
+	err = r.db.QueryRow(
+		`UPDATE cats c SET salary=$1, currency=$2
+		 FROM (SELECT id, salary, currency FROM cats WHERE id=$3 AND deleted_at IS NULL FOR UPDATE) prev
+		 WHERE c.id = prev.id
+		 RETURNING prev.salary, prev.currency`,
+		salary.Amount, salary.Currency, id,
+	).Scan(&old.Amount, &old.Currency)
+	if err == sql.ErrNoRows {
+		return utils.Money{}, false, nil
+	}
+	return old, err == nil, err
+}
+
+const salaryChangeColumns = `id, cat_id, old_salary, old_currency, new_salary, new_currency,
+	to_char(effective_date, 'YYYY-MM-DD'), reason, actor, created_at, applied_at`
+
+func scanSalaryChange(row scanner) (*SalaryChange, error) {
+	var ch SalaryChange
+	var oldAmount *utils.Amount
+	var oldCurrency *string
+	if err := row.Scan(
+		&ch.ID, &ch.CatID, &oldAmount, &oldCurrency, &ch.NewSalary.Amount, &ch.NewSalary.Currency,
+		&ch.EffectiveDate, &ch.Reason, &ch.Actor, &ch.CreatedAt, &ch.AppliedAt,
+	); err != nil {
+		return nil, err
+	}
+	if oldAmount != nil && oldCurrency != nil {
+		ch.OldSalary = &utils.Money{Amount: *oldAmount, Currency: *oldCurrency}
+	}
+	return &ch, nil
+}
+
+// CreateSalaryChange records a salary change. A change with an OldSalary is
+// stored as applied, one without it as scheduled.
+func (r *Repository) CreateSalaryChange(ch SalaryChange) (*SalaryChange, error) {
+	var oldAmount *utils.Amount
+	var oldCurrency *string
+	if ch.OldSalary != nil {
+		oldAmount, oldCurrency = &ch.OldSalary.Amount, &ch.OldSalary.Currency
+	}
+	return scanSalaryChange(r.db.QueryRow(
+		`INSERT INTO salary_changes
+		 (cat_id, old_salary, old_currency, new_salary, new_currency, effective_date, reason, actor, applied_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $2::numeric IS NULL THEN NULL ELSE now() END)
+		 RETURNING `+salaryChangeColumns,
+		ch.CatID, oldAmount, oldCurrency, ch.NewSalary.Amount, ch.NewSalary.Currency,
+		ch.EffectiveDate, ch.Reason, ch.Actor,
+	))
+}
+
+// ListSalaryChanges returns the salary history of the cat, scheduled changes
+// included, latest effective date first
+func (r *Repository) ListSalaryChanges(catID int64) ([]SalaryChange, error) {
+	rows, err := r.db.Query(
+		`SELECT `+salaryChangeColumns+` FROM salary_changes WHERE cat_id=$1 ORDER BY effective_date DESC, id DESC`,
+		catID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []SalaryChange{}
+	for rows.Next() {
+		ch, err := scanSalaryChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *ch)
+	}
+	return changes, rows.Err()
+}
+
+// LockDueSalaryChanges returns the scheduled changes of cats that are not
+// deleted that take effect on or before date, oldest first, and locks them
+// until the end of the transaction. Changes locked by another transaction
+// are skipped.
+func (r *Repository) LockDueSalaryChanges(date string) ([]SalaryChange, error) {
+	rows, err := r.db.Query(
+		`SELECT `+salaryChangeColumns+` FROM salary_changes s
+		 WHERE applied_at IS NULL AND effective_date <= $1::date
+		 AND EXISTS (SELECT 1 FROM cats c WHERE c.id = s.cat_id AND c.deleted_at IS NULL)
+		 ORDER BY effective_date, id
+		 FOR UPDATE SKIP LOCKED`,
+		date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []SalaryChange{}
+	for rows.Next() {
+		ch, err := scanSalaryChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *ch)
+	}
+	return changes, rows.Err()
+}
+
+// MarkSalaryChangeApplied records that a scheduled change took effect and
+// the salary it replaced
+func (r *Repository) MarkSalaryChangeApplied(id int64, oldSalary utils.Money) error {
+	_, err := r.db.Exec(
+		`UPDATE salary_changes SET old_salary=$2, old_currency=$3, applied_at=now() WHERE id=$1`,
+		id, oldSalary.Amount, oldSalary.Currency,
+	)
+	return err
+}
+
+// Lock locks the cat row until the end of the transaction, serializing it
+// with mission assignments of the same cat. It reports whether the cat
+// exists and is not deleted.
+func (r *Repository) Lock(id int64) (bool, error) {
+	var locked int64
+	err := r.db.QueryRow(`SELECT id FROM cats WHERE id=$1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// OpenMissionIDs returns the ids of the cat's missions that are not closed
+func (r *Repository) OpenMissionIDs(catID int64) ([]int64, error) {
+	rows, err := r.db.Query(
+		`SELECT id FROM missions
+		 WHERE cat_id=$1 AND status IN `+openMissionStatuses+` AND deleted_at IS NULL
+		 ORDER BY id`,
+		catID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MaxMissionTargets returns the largest number of targets, deleted ones
+// excluded, that any of the missions has
+func (r *Repository) MaxMissionTargets(missionIDs []int64) (int, error) {
+	var n int
+	err := r.db.QueryRow(
+		`SELECT COALESCE(MAX(n), 0) FROM (
+		   SELECT COUNT(*) AS n FROM targets
+		   WHERE mission_id = ANY($1) AND deleted_at IS NULL
+		   GROUP BY mission_id
+		 ) counts`,
+		pq.Array(missionIDs),
+	).Scan(&n)
+	return n, err
+}
+
+// ReassignMissions hands the missions over from one cat, which is being
+// deleted, to another and records the change in each mission's history and
+// assignments
+func (r *Repository) ReassignMissions(missionIDs []int64, from, to int64) error {
+	if _, err := r.db.Exec(`UPDATE missions SET cat_id=$1 WHERE id = ANY($2)`, to, pq.Array(missionIDs)); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(
+		`INSERT INTO mission_status_changes (mission_id, from_status, to_status, from_cat_id, to_cat_id)
+		 SELECT id, status, status, $2, $3 FROM missions WHERE id = ANY($1)`,
+		pq.Array(missionIDs), from, to,
+	); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(
+		`UPDATE mission_assignments SET released_at=now(), reason='cat_deleted'
+		 WHERE mission_id = ANY($1) AND released_at IS NULL`,
+		pq.Array(missionIDs),
+	); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(
+		`INSERT INTO mission_assignments (mission_id, cat_id) SELECT unnest($1::int[]), $2`,
+		pq.Array(missionIDs), to,
+	)
+	return err
 }
 
-func (r *Repository) UpdateSalary(id int64, salary float64) (int64, error) {
-	res, err := r.db.Exec(`UPDATE cats SET salary=$1 WHERE id=$2`, salary, id)
+// ListMissionAssignments returns the cat's assignments to missions that are
+// not deleted and match q, most recent first
+func (r *Repository) ListMissionAssignments(catID int64, q ListCatMissionsQuery) ([]MissionAssignment, error) {
+	filter := ""
+	args := []any{catID}
+	if len(q.Status) > 0 {
+		filter = " AND m.status = ANY($2)"
+		args = append(args, pq.Array(q.Status))
+	}
+
+	rows, err := r.db.Query(
+		`SELECT m.id, m.name, m.status, a.assigned_at, a.released_at, COALESCE(a.reason, '')
+		 FROM mission_assignments a JOIN missions m ON m.id = a.mission_id
+		 WHERE a.cat_id=$1 AND m.deleted_at IS NULL`+filter+`
+		 ORDER BY a.assigned_at DESC, a.id DESC`,
+		args...,
+	)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []MissionAssignment{}
+	for rows.Next() {
+		var a MissionAssignment
+		if err := rows.Scan(&a.MissionID, &a.MissionName, &a.MissionStatus, &a.AssignedAt, &a.ReleasedAt, &a.Reason); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
 	}
-	rows, _ := res.RowsAffected()
-	return rows, nil
+	return assignments, rows.Err()
 }
 
+// CurrentMission returns the incomplete mission the cat is on with its
+// targets, or nil if the cat is free
+func (r *Repository) CurrentMission(catID int64) (*CurrentMission, error) {
+	var m CurrentMission
+	err := r.db.QueryRow(
+		`SELECT id, name, status, status_changed_at FROM missions
+		 WHERE cat_id=$1 AND status IN `+openMissionStatuses+` AND deleted_at IS NULL
+		 ORDER BY id LIMIT 1`,
+		catID,
+	).Scan(&m.ID, &m.Name, &m.Status, &m.StatusChangedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, name, country, notes, status FROM targets
+		 WHERE mission_id=$1 AND deleted_at IS NULL ORDER BY id`,
+		m.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	m.Targets = []MissionTarget{}
+	for rows.Next() {
+		var t MissionTarget
+		if err := rows.Scan(&t.ID, &t.Name, &t.Country, &t.Notes, &t.Status); err != nil {
+			return nil, err
+		}
+		m.Targets = append(m.Targets, t)
+	}
+	return &m, rows.Err()
+}
+
+// Delete soft deletes the cat; the purge job removes it for good once the
+// retention window has passed
 func (r *Repository) Delete(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM cats WHERE id=$1`, id)
+	_, err := r.db.Exec(`UPDATE cats SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL`, id)
 	return err
 }
+
+// Restore undeletes the cat and returns it, or nil if there is no deleted
+// cat with the id
+func (r *Repository) Restore(id int64) (*Cat, error) {
+	c, err := scanCat(r.db.QueryRow(
+		`UPDATE cats SET deleted_at=NULL WHERE id=$1 AND deleted_at IS NOT NULL RETURNING `+catColumns, id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
@@ -1,19 +1,20 @@
 package cats
 
 import (
-	"database/sql"
-
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(rg *gin.RouterGroup, db *sql.DB) {
-	repo := NewRepository(db)
-	service := NewService(repo)
+func RegisterRoutes(rg *gin.RouterGroup, service *Service) {
 	handler := NewHandler(service)
 
 	rg.POST("/", handler.CreateCat)
 	rg.GET("/", handler.ListCats)
 	rg.GET("/:id", handler.GetCat)
+	rg.PATCH("/:id", handler.UpdateCat)
+	rg.PUT("/:id", handler.ReplaceCat)
 	rg.PATCH("/:id/salary", handler.UpdateSalary)
+	rg.GET("/:id/salary-history", handler.GetSalaryHistory)
 	rg.DELETE("/:id", handler.DeleteCat)
+	rg.POST("/:id/restore", handler.RestoreCat)
+	rg.GET("/:id/missions", handler.GetCatMissions)
 }
@@ -0,0 +1,55 @@
+package cats
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+const DefaultSalaryScheduleInterval = time.Hour
+
+// SalaryScheduleIntervalFromEnv reads how often scheduled salary changes are
+// checked from SALARY_SCHEDULE_INTERVAL
+func SalaryScheduleIntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("SALARY_SCHEDULE_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultSalaryScheduleInterval
+}
+
+// SalaryScheduler applies scheduled salary changes once they take effect
+type SalaryScheduler struct {
+	service  *Service
+	interval time.Duration
+}
+
+// NewSalaryScheduler applies due changes through service, the one the API
+// uses, so scheduled changes are checked against the same currency and ranks
+func NewSalaryScheduler(service *Service, interval time.Duration) *SalaryScheduler {
+	if interval <= 0 {
+		interval = DefaultSalaryScheduleInterval
+	}
+	return &SalaryScheduler{service: service, interval: interval}
+}
+
+// Run applies due salary changes every interval until ctx is cancelled
+func (s *SalaryScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.service.ApplyDueSalaryChanges()
+		if err != nil {
+			log.Printf("salary schedule: %v", err)
+		} else if n > 0 {
+			log.Printf("salary schedule: applied %d salary change(s)", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
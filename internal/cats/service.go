@@ -1,55 +1,551 @@
 package cats
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"spy-cats/internal/utils"
 )
 
+// BreedValidator resolves user supplied breeds to their canonical names. An
+// unknown breed is reported as an *utils.UnknownBreedError.
+type BreedValidator interface {
+	ResolveBreed(breed string) (string, error)
+}
+
+// ErrCatNotFound is returned when the requested cat does not exist
+var ErrCatNotFound = errors.New("cat not found")
+
+var (
+	// ErrCatOnMission is returned when deleting a cat that still has
+	// incomplete missions; the error is a *MissionsInProgressError
+	ErrCatOnMission = errors.New("cat has incomplete missions")
+	// ErrCatBusy is returned when missions are handed to a cat that already
+	// has an incomplete mission
+	ErrCatBusy = errors.New("cat already has an incomplete mission")
+	// ErrInvalidReassign is returned when a cat's missions are handed back
+	// to the same cat
+	ErrInvalidReassign = errors.New("invalid reassign_to")
+	// ErrInvalidSalaryChange is returned for salary changes dated in the past
+	ErrInvalidSalaryChange = errors.New("invalid salary change")
+	// ErrInvalidSalary is returned for salaries that are negative, too large
+	// for the salary column, not in an ISO 4217 currency or outside the
+	// salary band of the cat's rank
+	ErrInvalidSalary = errors.New("invalid salary")
+	// ErrRankTooLow is returned when missions are handed to a cat whose rank
+	// does not allow that many targets
+	ErrRankTooLow = errors.New("cat rank too low")
+)
+
+// MissionsInProgressError lists the incomplete missions that keep a cat from
+// being deleted
+type MissionsInProgressError struct {
+	CatID      int64
+	MissionIDs []int64
+}
+
+func (e *MissionsInProgressError) Error() string {
+	ids := make([]string, len(e.MissionIDs))
+	for i, id := range e.MissionIDs {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprintf("%v: cat %d is on mission(s) %s", ErrCatOnMission, e.CatID, strings.Join(ids, ", "))
+}
+
+func (e *MissionsInProgressError) Unwrap() error {
+	return ErrCatOnMission
+}
+
+// CatRepository is the persistence used by Service. *Repository implements
+// it on top of PostgreSQL.
+type CatRepository interface {
+	// InTx runs fn in a single transaction
+	InTx(fn func(tx CatRepository) error) error
+
+	Create(cat Cat) (int64, error)
+	List(q ListCatsQuery, cursor *utils.Cursor) (*CatPage, error)
+	GetByID(id int64) (*Cat, error)
+	Update(cat Cat) (*Cat, error)
+	Lock(id int64) (bool, error)
+	Delete(id int64) error
+	Restore(id int64) (*Cat, error)
+
+	SetSalary(id int64, salary utils.Money) (old utils.Money, found bool, err error)
+	CreateSalaryChange(ch SalaryChange) (*SalaryChange, error)
+	ListSalaryChanges(catID int64) ([]SalaryChange, error)
+	LockDueSalaryChanges(date string) ([]SalaryChange, error)
+	MarkSalaryChangeApplied(id int64, oldSalary utils.Money) error
+
+	OpenMissionIDs(catID int64) ([]int64, error)
+	MaxMissionTargets(missionIDs []int64) (int, error)
+	ReassignMissions(missionIDs []int64, from, to int64) error
+	ListMissionAssignments(catID int64, q ListCatMissionsQuery) ([]MissionAssignment, error)
+	CurrentMission(catID int64) (*CurrentMission, error)
+}
+
+// Service manages cats. Salaries given without a currency are in currency,
+// and salaries in currency must lie within the salary band of the cat's rank.
 type Service struct {
-	repo *Repository
+	repo     CatRepository
+	breeds   BreedValidator
+	currency string
+	ranks    *utils.RankLadder
+	now      func() time.Time
+}
+
+func NewService(repo CatRepository, breeds BreedValidator, currency string, ranks *utils.RankLadder) *Service {
+	return &Service{repo: repo, breeds: breeds, currency: currency, ranks: ranks, now: time.Now}
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// today returns the current UTC date, the calendar salary changes use
+func (s *Service) today() string {
+	return s.now().UTC().Format(time.DateOnly)
 }
 
 func (s *Service) CreateCat(req CreateCatRequest) (int64, error) {
-	ok, err := utils.CatBreedExists(req.Breed)
+	breed, err := s.resolveBreed(req.Breed)
 	if err != nil {
-		return 0, fmt.Errorf("failed to validate breed: %w", err)
+		return 0, err
 	}
-	if !ok {
-		return 0, fmt.Errorf("invalid cat breed: %s", req.Breed)
+	salary, err := s.salary(*req.Salary)
+	if err != nil {
+		return 0, err
 	}
 
 	cat := Cat{
 		Name:              req.Name,
 		YearsOfExperience: req.YearsOfExperience,
-		Breed:             req.Breed,
-		Salary:            req.Salary,
+		Breed:             breed,
+		Salary:            salary,
+	}
+	if err := s.checkSalaryBand(cat, salary); err != nil {
+		return 0, err
 	}
 	return s.repo.Create(cat)
 }
 
-func (s *Service) GetAllCats() ([]Cat, error) {
-	return s.repo.GetAll()
+// salary fills in the default currency and checks that the salary can be
+// stored without rounding or overflowing the salary column, and is a whole
+// number of minor units of its currency
+func (s *Service) salary(m utils.Money) (utils.Money, error) {
+	if m.Currency == "" {
+		m.Currency = s.currency
+	}
+	if !utils.ValidCurrency(m.Currency) {
+		return utils.Money{}, fmt.Errorf("%w: %q is not an ISO 4217 currency code with at most 2 decimal places", ErrInvalidSalary, m.Currency)
+	}
+	if !m.Exact() {
+		return utils.Money{}, fmt.Errorf("%w: %s has more decimal places than %s uses", ErrInvalidSalary, m.Amount, m.Currency)
+	}
+	if m.Amount < 0 || m.Amount > MaxSalary {
+		return utils.Money{}, fmt.Errorf("%w: %s must be between 0 and %s", ErrInvalidSalary, m.Amount, MaxSalary)
+	}
+	return m, nil
+}
+
+// rank fills in the rank of the cat
+func (s *Service) rank(cat *Cat) *Cat {
+	if cat != nil {
+		cat.Rank = s.ranks.Rank(cat.YearsOfExperience, cat.MissionsCompleted).Name
+	}
+	return cat
+}
+
+// checkSalaryBand checks that a salary in the default currency lies within
+// the salary band of the cat's rank. Salaries in other currencies have no
+// band.
+func (s *Service) checkSalaryBand(cat Cat, salary utils.Money) error {
+	if salary.Currency != s.currency {
+		return nil
+	}
+	tier := s.ranks.Rank(cat.YearsOfExperience, cat.MissionsCompleted)
+	if salary.Amount < tier.MinSalary || salary.Amount > tier.MaxSalary {
+		return fmt.Errorf("%w: %s is outside the %s salary band %s..%s",
+			ErrInvalidSalary, salary.Amount, tier.Name, tier.MinSalary, tier.MaxSalary)
+	}
+	return nil
+}
+
+// ErrInvalidQuery is returned for contradictory list filters
+var ErrInvalidQuery = errors.New("invalid query")
+
+func (s *Service) GetAllCats(q ListCatsQuery) (*CatPage, error) {
+	if q.Sort == "" {
+		q.Sort = "id"
+	}
+	if q.Limit == 0 {
+		q.Limit = utils.DefaultPageLimit
+	}
+	if q.MinSalary != nil && q.MaxSalary != nil && *q.MinSalary > *q.MaxSalary {
+		return nil, fmt.Errorf("%w: min_salary must not exceed max_salary", ErrInvalidQuery)
+	}
+	if q.MinExperience != nil && q.MaxExperience != nil && *q.MinExperience > *q.MaxExperience {
+		return nil, fmt.Errorf("%w: min_experience must not exceed max_experience", ErrInvalidQuery)
+	}
+
+	var cursor *utils.Cursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = utils.DecodeCursor(q.Cursor, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+	page, err := s.repo.List(q, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Cats {
+		s.rank(&page.Cats[i])
+	}
+	return page, nil
+}
+
+// GetCat returns the cat, or nil if it does not exist. include=current_mission
+// also loads the incomplete mission the cat is on.
+func (s *Service) GetCat(id int64, q GetCatQuery) (*Cat, error) {
+	cat, err := s.repo.GetByID(id)
+	if err != nil || cat == nil || q.Include != "current_mission" {
+		return s.rank(cat), err
+	}
+	if cat.CurrentMission, err = s.repo.CurrentMission(id); err != nil {
+		return nil, err
+	}
+	return s.rank(cat), nil
+}
+
+// UpdateCat applies the fields present in req to the cat
+func (s *Service) UpdateCat(id int64, req UpdateCatRequest) (*Cat, error) {
+	cat, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cat == nil {
+		return nil, fmt.Errorf("%w with id %d", ErrCatNotFound, id)
+	}
+
+	if req.Name != nil {
+		cat.Name = *req.Name
+	}
+	if req.YearsOfExperience != nil {
+		cat.YearsOfExperience = *req.YearsOfExperience
+	}
+	if req.Breed != nil {
+		if cat.Breed, err = s.resolveBreed(*req.Breed); err != nil {
+			return nil, err
+		}
+	}
+	if req.Salary != nil {
+		if cat.Salary, err = s.salary(*req.Salary); err != nil {
+			return nil, err
+		}
+	}
+	return s.update(*cat)
+}
+
+// ReplaceCat overwrites the whole cat profile
+func (s *Service) ReplaceCat(id int64, req CreateCatRequest) (*Cat, error) {
+	breed, err := s.resolveBreed(req.Breed)
+	if err != nil {
+		return nil, err
+	}
+	salary, err := s.salary(*req.Salary)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(Cat{
+		ID:                id,
+		Name:              req.Name,
+		YearsOfExperience: req.YearsOfExperience,
+		Breed:             breed,
+		Salary:            salary,
+	})
+}
+
+// update stores the cat and records a salary change in its history if the
+// salary differs from the stored one. A changed salary must lie within the
+// band of the cat's rank after the update.
+func (s *Service) update(cat Cat) (*Cat, error) {
+	var updated *Cat
+	err := s.repo.InTx(func(tx CatRepository) error {
+		// SetSalary locks the cat and reports the salary it replaces
+		old, found, err := tx.SetSalary(cat.ID, cat.Salary)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w with id %d", ErrCatNotFound, cat.ID)
+		}
+		if updated, err = tx.Update(cat); err != nil {
+			return err
+		}
+		if old == cat.Salary {
+			return nil
+		}
+		if err := s.checkSalaryBand(*updated, cat.Salary); err != nil {
+			return err
+		}
+		_, err = tx.CreateSalaryChange(SalaryChange{
+			CatID:         cat.ID,
+			OldSalary:     &old,
+			NewSalary:     cat.Salary,
+			EffectiveDate: s.today(),
+			Reason:        "profile update",
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.rank(updated), nil
+}
+
+// UpdateSalary changes the salary of the cat and records the change in its
+// salary history. A change with a future effective date is only recorded and
+// is applied by ApplyDueSalaryChanges once that date is reached; the returned
+// change tells which happened by whether AppliedAt is set. The salary must
+// lie within the band of the cat's current rank.
+func (s *Service) UpdateSalary(id int64, req UpdateSalaryRequest) (*SalaryChange, error) {
+	salary, err := s.salary(*req.Salary)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.today()
+	change := SalaryChange{
+		CatID:         id,
+		NewSalary:     salary,
+		EffectiveDate: today,
+		Reason:        req.Reason,
+		Actor:         req.Actor,
+	}
+	// both dates are YYYY-MM-DD, so they compare as strings
+	if req.EffectiveDate != "" {
+		if req.EffectiveDate < today {
+			return nil, fmt.Errorf("%w: effective_date %s is in the past", ErrInvalidSalaryChange, req.EffectiveDate)
+		}
+		change.EffectiveDate = req.EffectiveDate
+	}
+
+	var recorded *SalaryChange
+	err = s.repo.InTx(func(tx CatRepository) error {
+		found, err := tx.Lock(id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w with id %d", ErrCatNotFound, id)
+		}
+		cat, err := tx.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkSalaryBand(*cat, salary); err != nil {
+			return err
+		}
+
+		if change.EffectiveDate > today {
+			recorded, err = tx.CreateSalaryChange(change)
+			return err
+		}
+
+		old, _, err := tx.SetSalary(id, salary)
+		if err != nil {
+			return err
+		}
+		change.OldSalary = &old
+		recorded, err = tx.CreateSalaryChange(change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// GetSalaryHistory returns the salary changes of the cat, scheduled ones
+// included, latest effective date first
+func (s *Service) GetSalaryHistory(id int64) ([]SalaryChange, error) {
+	cat, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cat == nil {
+		return nil, fmt.Errorf("%w with id %d", ErrCatNotFound, id)
+	}
+	return s.repo.ListSalaryChanges(id)
+}
+
+// ApplyDueSalaryChanges applies the scheduled salary changes that have taken
+// effect, oldest first, and returns how many were applied. Changes of deleted
+// cats wait until the cat is restored, and changes outside the salary band of
+// the cat's current rank are held until the cat's rank fits them.
+func (s *Service) ApplyDueSalaryChanges() (int, error) {
+	applied := 0
+	err := s.repo.InTx(func(tx CatRepository) error {
+		due, err := tx.LockDueSalaryChanges(s.today())
+		if err != nil {
+			return err
+		}
+		for _, ch := range due {
+			found, err := tx.Lock(ch.CatID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			cat, err := tx.GetByID(ch.CatID)
+			if err != nil {
+				return err
+			}
+			// the cat's rank may have changed since the change was scheduled
+			if err := s.checkSalaryBand(*cat, ch.NewSalary); err != nil {
+				log.Printf("salary schedule: holding change %d of cat %d: %v", ch.ID, ch.CatID, err)
+				continue
+			}
+
+			old, found, err := tx.SetSalary(ch.CatID, ch.NewSalary)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			if err := tx.MarkSalaryChangeApplied(ch.ID, old); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
 }
 
-func (s *Service) GetCat(id int64) (*Cat, error) {
-	return s.repo.GetByID(id)
+// DeleteCat soft deletes the cat. A cat with incomplete missions is only
+// deleted if reassignTo names another cat without incomplete missions whose
+// rank allows their targets, which takes them over in the same transaction.
+func (s *Service) DeleteCat(id int64, reassignTo *int64) error {
+	if reassignTo != nil && *reassignTo == id {
+		return fmt.Errorf("%w: cat %d cannot take over its own missions", ErrInvalidReassign, id)
+	}
+
+	return s.repo.InTx(func(tx CatRepository) error {
+		if err := lockCats(tx, id, reassignTo); err != nil {
+			return err
+		}
+
+		missionIDs, err := tx.OpenMissionIDs(id)
+		if err != nil {
+			return err
+		}
+		if len(missionIDs) > 0 {
+			if reassignTo == nil {
+				return &MissionsInProgressError{CatID: id, MissionIDs: missionIDs}
+			}
+			busy, err := tx.OpenMissionIDs(*reassignTo)
+			if err != nil {
+				return err
+			}
+			if len(busy) > 0 {
+				return fmt.Errorf("%w: cat %d is on mission %d", ErrCatBusy, *reassignTo, busy[0])
+			}
+			if err := s.checkRank(tx, *reassignTo, missionIDs); err != nil {
+				return err
+			}
+			if err := tx.ReassignMissions(missionIDs, id, *reassignTo); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(id)
+	})
 }
 
-func (s *Service) UpdateSalary(id int64, salary float64) error {
-	rowsAffected, err := s.repo.UpdateSalary(id, salary)
+// checkRank checks that the rank of the cat allows the targets of each of
+// the missions
+func (s *Service) checkRank(tx CatRepository, catID int64, missionIDs []int64) error {
+	cat, err := tx.GetByID(catID)
+	if err != nil {
+		return err
+	}
+	targets, err := tx.MaxMissionTargets(missionIDs)
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("cat not found with id %d", id)
+	tier := s.ranks.Rank(cat.YearsOfExperience, cat.MissionsCompleted)
+	if targets > tier.MaxTargets {
+		return fmt.Errorf("%w: cat %d is a %s and may take missions of at most %d targets",
+			ErrRankTooLow, catID, tier.Name, tier.MaxTargets)
+	}
+	return nil
+}
+
+// lockCats locks the cat and, if set, the cat taking over its missions in
+// id order so that concurrent deletes reassigning to each other cannot
+// deadlock
+func lockCats(tx CatRepository, id int64, reassignTo *int64) error {
+	ids := []int64{id}
+	if reassignTo != nil {
+		ids = append(ids, *reassignTo)
+		slices.Sort(ids)
+	}
+	for _, catID := range ids {
+		found, err := tx.Lock(catID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if catID == id {
+				return fmt.Errorf("%w with id %d", ErrCatNotFound, id)
+			}
+			return fmt.Errorf("%w: reassign_to cat %d", ErrCatNotFound, catID)
+		}
 	}
 	return nil
 }
 
-func (s *Service) DeleteCat(id int64) error {
-	return s.repo.Delete(id)
+// GetCatMissions returns the missions the cat was assigned to that match q,
+// most recent first
+func (s *Service) GetCatMissions(id int64, q ListCatMissionsQuery) ([]MissionAssignment, error) {
+	cat, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cat == nil {
+		return nil, fmt.Errorf("%w with id %d", ErrCatNotFound, id)
+	}
+	return s.repo.ListMissionAssignments(id, q)
+}
+
+// RestoreCat undeletes a soft-deleted cat
+func (s *Service) RestoreCat(id int64) (*Cat, error) {
+	cat, err := s.repo.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+	if cat == nil {
+		return nil, fmt.Errorf("%w: no deleted cat with id %d", ErrCatNotFound, id)
+	}
+	return s.rank(cat), nil
+}
+
+// resolveBreed returns the canonical breed name. Unknown breeds are returned
+// as *utils.UnknownBreedError so handlers can surface the suggestions.
+func (s *Service) resolveBreed(breed string) (string, error) {
+	canonical, err := s.breeds.ResolveBreed(breed)
+	var unknown *utils.UnknownBreedError
+	if errors.As(err, &unknown) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to validate breed: %w", err)
+	}
+	return canonical, nil
 }
@@ -0,0 +1,473 @@
+package cats_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/cats"
+	"spy-cats/internal/database/dbtest"
+	"spy-cats/internal/utils"
+)
+
+// fakeRepo is an in-memory CatRepository. InTx restores the previous state
+// when fn fails, like a rolled back transaction, and every lock taken is
+// recorded in order.
+type fakeRepo struct {
+	cats     map[int64]cats.Cat
+	missions map[int64]fakeMission
+	changes  []cats.SalaryChange
+	locks    []int64
+	nextID   int64
+}
+
+// fakeMission is an open mission of a cat with its number of targets
+type fakeMission struct {
+	catID   int64
+	targets int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{cats: make(map[int64]cats.Cat), missions: make(map[int64]fakeMission)}
+}
+
+// seed stores a cat of the given experience paid salary and returns its id
+func (r *fakeRepo) seed(years, completed int, salary utils.Money) int64 {
+	id, _ := r.Create(cats.Cat{Name: "Whiskers", YearsOfExperience: years, Breed: "Siamese", Salary: salary})
+	cat := r.cats[id]
+	cat.MissionsCompleted = completed
+	r.cats[id] = cat
+	return id
+}
+
+func (r *fakeRepo) id() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *fakeRepo) InTx(fn func(tx cats.CatRepository) error) error {
+	return dbtest.InTx(func() error { return fn(r) }, &r.cats, &r.missions, &r.changes, &r.nextID)
+}
+
+func (r *fakeRepo) Create(cat cats.Cat) (int64, error) {
+	cat.ID = r.id()
+	r.cats[cat.ID] = cat
+	return cat.ID, nil
+}
+
+func (r *fakeRepo) List(q cats.ListCatsQuery, cursor *utils.Cursor) (*cats.CatPage, error) {
+	return &cats.CatPage{}, nil
+}
+
+func (r *fakeRepo) GetByID(id int64) (*cats.Cat, error) {
+	cat, ok := r.cats[id]
+	if !ok || cat.DeletedAt != nil {
+		return nil, nil
+	}
+	return &cat, nil
+}
+
+func (r *fakeRepo) Update(cat cats.Cat) (*cats.Cat, error) {
+	if _, ok := r.cats[cat.ID]; !ok {
+		return nil, nil
+	}
+	r.cats[cat.ID] = cat
+	return &cat, nil
+}
+
+func (r *fakeRepo) Lock(id int64) (bool, error) {
+	r.locks = append(r.locks, id)
+	cat, ok := r.cats[id]
+	return ok && cat.DeletedAt == nil, nil
+}
+
+func (r *fakeRepo) Delete(id int64) error {
+	cat := r.cats[id]
+	now := time.Now()
+	cat.DeletedAt = &now
+	r.cats[id] = cat
+	return nil
+}
+
+func (r *fakeRepo) Restore(id int64) (*cats.Cat, error) {
+	cat, ok := r.cats[id]
+	if !ok || cat.DeletedAt == nil {
+		return nil, nil
+	}
+	cat.DeletedAt = nil
+	r.cats[id] = cat
+	return &cat, nil
+}
+
+func (r *fakeRepo) SetSalary(id int64, salary utils.Money) (utils.Money, bool, error) {
+	cat, ok := r.cats[id]
+	if !ok || cat.DeletedAt != nil {
+		return utils.Money{}, false, nil
+	}
+	old := cat.Salary
+	cat.Salary = salary
+	r.cats[id] = cat
+	return old, true, nil
+}
+
+func (r *fakeRepo) CreateSalaryChange(ch cats.SalaryChange) (*cats.SalaryChange, error) {
+	ch.ID = r.id()
+	if ch.OldSalary != nil {
+		now := time.Now()
+		ch.AppliedAt = &now
+	}
+	r.changes = append(r.changes, ch)
+	return &ch, nil
+}
+
+func (r *fakeRepo) ListSalaryChanges(catID int64) ([]cats.SalaryChange, error) {
+	changes := []cats.SalaryChange{}
+	for _, ch := range r.changes {
+		if ch.CatID == catID {
+			changes = append(changes, ch)
+		}
+	}
+	return changes, nil
+}
+
+func (r *fakeRepo) LockDueSalaryChanges(date string) ([]cats.SalaryChange, error) {
+	due := []cats.SalaryChange{}
+	for _, ch := range r.changes {
+		if ch.AppliedAt == nil && ch.EffectiveDate <= date && r.cats[ch.CatID].DeletedAt == nil {
+			due = append(due, ch)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeRepo) MarkSalaryChangeApplied(id int64, oldSalary utils.Money) error {
+	for i, ch := range r.changes {
+		if ch.ID == id {
+			now := time.Now()
+			r.changes[i].OldSalary, r.changes[i].AppliedAt = &oldSalary, &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) OpenMissionIDs(catID int64) ([]int64, error) {
+	ids := []int64{}
+	for id, m := range r.missions {
+		if m.catID == catID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (r *fakeRepo) MaxMissionTargets(missionIDs []int64) (int, error) {
+	n := 0
+	for _, id := range missionIDs {
+		n = max(n, r.missions[id].targets)
+	}
+	return n, nil
+}
+
+func (r *fakeRepo) ReassignMissions(missionIDs []int64, from, to int64) error {
+	for _, id := range missionIDs {
+		m := r.missions[id]
+		m.catID = to
+		r.missions[id] = m
+	}
+	return nil
+}
+
+func (r *fakeRepo) ListMissionAssignments(catID int64, q cats.ListCatMissionsQuery) ([]cats.MissionAssignment, error) {
+	return []cats.MissionAssignment{}, nil
+}
+
+func (r *fakeRepo) CurrentMission(catID int64) (*cats.CurrentMission, error) {
+	return nil, nil
+}
+
+// newService returns a service on repo that resolves breeds from the bundled
+// snapshot and uses the default rank ladder
+func newService(t *testing.T, repo *fakeRepo) *cats.Service {
+	catalog, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{})
+	require.NoError(t, err)
+	return cats.NewService(repo, catalog, "USD", utils.DefaultRankLadder())
+}
+
+func TestServiceDeleteCat(t *testing.T) {
+	tests := []struct {
+		name string
+		// targets of the open mission of the deleted cat, none if zero
+		targets int
+		// reassign hands the missions to a recruit, who is on a mission of
+		// its own if heirBusy is set
+		reassign    bool
+		heirBusy    bool
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "deletes a free cat", wantDeleted: true},
+		{name: "hands missions over", targets: 2, reassign: true, wantDeleted: true},
+		{name: "rejects a cat on a mission", targets: 2, wantErr: cats.ErrCatOnMission},
+		{name: "rejects a busy heir", targets: 2, reassign: true, heirBusy: true, wantErr: cats.ErrCatBusy},
+		{name: "rejects an heir of too low a rank", targets: 3, reassign: true, wantErr: cats.ErrRankTooLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			heir := repo.seed(0, 0, usd(40000_00))
+			id := repo.seed(6, 12, usd(70000_00))
+			if tt.targets > 0 {
+				repo.missions[100] = fakeMission{catID: id, targets: tt.targets}
+			}
+			if tt.heirBusy {
+				repo.missions[200] = fakeMission{catID: heir, targets: 1}
+			}
+			var reassignTo *int64
+			if tt.reassign {
+				reassignTo = &heir
+			}
+
+			err := newService(t, repo).DeleteCat(id, reassignTo)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			cat, _ := repo.GetByID(id)
+			assert.Equal(t, tt.wantDeleted, cat == nil)
+			if tt.targets > 0 {
+				owner := id
+				if tt.wantDeleted {
+					owner = heir
+				}
+				assert.Equal(t, owner, repo.missions[100].catID)
+			}
+		})
+	}
+
+	t.Run("locks both cats in id order", func(t *testing.T) {
+		repo := newFakeRepo()
+		heir := repo.seed(0, 0, usd(40000_00))
+		id := repo.seed(6, 12, usd(70000_00))
+		repo.missions[100] = fakeMission{catID: id, targets: 1}
+
+		require.NoError(t, newService(t, repo).DeleteCat(id, &heir))
+		assert.Equal(t, []int64{heir, id}, repo.locks)
+	})
+
+	t.Run("lists the missions in progress", func(t *testing.T) {
+		repo := newFakeRepo()
+		id := repo.seed(6, 12, usd(70000_00))
+		repo.missions[100] = fakeMission{catID: id, targets: 1}
+		repo.missions[101] = fakeMission{catID: id, targets: 1}
+
+		err := newService(t, repo).DeleteCat(id, nil)
+		var inProgress *cats.MissionsInProgressError
+		require.ErrorAs(t, err, &inProgress)
+		assert.Equal(t, []int64{100, 101}, inProgress.MissionIDs)
+	})
+
+	t.Run("rejects reassigning to itself or an unknown cat", func(t *testing.T) {
+		repo := newFakeRepo()
+		id := repo.seed(6, 12, usd(70000_00))
+		svc := newService(t, repo)
+
+		assert.ErrorIs(t, svc.DeleteCat(id, &id), cats.ErrInvalidReassign)
+		unknown := int64(42)
+		assert.ErrorIs(t, svc.DeleteCat(id, &unknown), cats.ErrCatNotFound)
+		assert.ErrorIs(t, svc.DeleteCat(unknown, nil), cats.ErrCatNotFound)
+	})
+}
+
+func TestServiceUpdateSalary(t *testing.T) {
+	today := time.Now().UTC()
+	date := func(days int) string { return today.AddDate(0, 0, days).Format(time.DateOnly) }
+
+	tests := []struct {
+		name          string
+		req           cats.UpdateSalaryRequest
+		wantErr       error
+		wantSalary    utils.Money
+		wantScheduled bool
+	}{
+		{
+			name:       "applies a change effective today",
+			req:        cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 45000_00}, Reason: "Annual raise"},
+			wantSalary: usd(45000_00),
+		},
+		{
+			name:          "schedules a future change",
+			req:           cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 45000_00}, EffectiveDate: date(30)},
+			wantSalary:    usd(40000_00),
+			wantScheduled: true,
+		},
+		{
+			name:       "rejects a past change",
+			req:        cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 45000_00}, EffectiveDate: date(-1)},
+			wantErr:    cats.ErrInvalidSalaryChange,
+			wantSalary: usd(40000_00),
+		},
+		{
+			name:       "rejects a salary above the band",
+			req:        cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 50000_01}},
+			wantErr:    cats.ErrInvalidSalary,
+			wantSalary: usd(40000_00),
+		},
+		{
+			name:       "rejects a scheduled salary above the band",
+			req:        cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 90000_00}, EffectiveDate: date(30)},
+			wantErr:    cats.ErrInvalidSalary,
+			wantSalary: usd(40000_00),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			id := repo.seed(0, 0, usd(40000_00))
+
+			change, err := newService(t, repo).UpdateSalary(id, tt.req)
+			cat, _ := repo.GetByID(id)
+			assert.Equal(t, tt.wantSalary, cat.Salary)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, repo.changes)
+				return
+			}
+			require.NoError(t, err)
+
+			history, _ := repo.ListSalaryChanges(id)
+			require.Len(t, history, 1)
+			assert.Equal(t, *change, history[0])
+			assert.Equal(t, usd(45000_00), change.NewSalary)
+			if tt.wantScheduled {
+				assert.Equal(t, tt.req.EffectiveDate, change.EffectiveDate)
+				assert.Nil(t, change.OldSalary)
+				assert.Nil(t, change.AppliedAt)
+			} else {
+				assert.Equal(t, date(0), change.EffectiveDate)
+				assert.Equal(t, usd(40000_00), *change.OldSalary)
+				assert.NotNil(t, change.AppliedAt)
+			}
+		})
+	}
+
+	t.Run("unknown cat", func(t *testing.T) {
+		_, err := newService(t, newFakeRepo()).UpdateSalary(42, cats.UpdateSalaryRequest{Salary: &utils.Money{Amount: 45000_00}})
+		assert.ErrorIs(t, err, cats.ErrCatNotFound)
+	})
+}
+
+func TestServiceApplyDueSalaryChanges(t *testing.T) {
+	today := time.Now().UTC()
+	date := func(days int) string { return today.AddDate(0, 0, days).Format(time.DateOnly) }
+
+	repo := newFakeRepo()
+	raised := repo.seed(0, 0, usd(40000_00))
+	// a change the cat's rank does not allow is held
+	held := repo.seed(0, 0, usd(40000_00))
+	later := repo.seed(0, 0, usd(40000_00))
+	deleted := repo.seed(0, 0, usd(40000_00))
+	require.NoError(t, repo.Delete(deleted))
+	for _, ch := range []cats.SalaryChange{
+		{CatID: raised, NewSalary: usd(45000_00), EffectiveDate: date(-1)},
+		{CatID: held, NewSalary: usd(90000_00), EffectiveDate: date(0)},
+		{CatID: later, NewSalary: usd(45000_00), EffectiveDate: date(1)},
+		{CatID: deleted, NewSalary: usd(45000_00), EffectiveDate: date(0)},
+	} {
+		_, err := repo.CreateSalaryChange(ch)
+		require.NoError(t, err)
+	}
+
+	n, err := newService(t, repo).ApplyDueSalaryChanges()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	salaries := map[int64]utils.Money{}
+	for id, cat := range repo.cats {
+		salaries[id] = cat.Salary
+	}
+	assert.Equal(t, map[int64]utils.Money{
+		raised:  usd(45000_00),
+		held:    usd(40000_00),
+		later:   usd(40000_00),
+		deleted: usd(40000_00),
+	}, salaries)
+
+	history, _ := repo.ListSalaryChanges(raised)
+	require.NotNil(t, history[0].AppliedAt)
+	assert.Equal(t, usd(40000_00), *history[0].OldSalary)
+	for _, id := range []int64{held, later, deleted} {
+		history, _ := repo.ListSalaryChanges(id)
+		assert.Nil(t, history[0].AppliedAt)
+	}
+}
+
+func TestServiceSalaryBand(t *testing.T) {
+	tests := []struct {
+		name      string
+		years     int
+		completed int
+		salary    utils.Money
+		wantErr   error
+	}{
+		{name: "recruit at the top of the band", salary: usd(50000_00)},
+		{name: "recruit above the band", salary: usd(50000_01), wantErr: cats.ErrInvalidSalary},
+		{name: "agent at the bottom of the band", years: 2, completed: 3, salary: usd(30000_00)},
+		{name: "agent below the band", years: 2, completed: 3, salary: usd(29999_99), wantErr: cats.ErrInvalidSalary},
+		{name: "experience alone ranks a cat", years: 12, salary: usd(150000_00)},
+		{name: "missions alone rank a cat", completed: 10, salary: usd(90000_00)},
+		{name: "experienced cat below the band", years: 12, salary: usd(60000_00), wantErr: cats.ErrInvalidSalary},
+		{name: "other currencies have no band", salary: utils.Money{Amount: 90000_00, Currency: "EUR"}},
+		{name: "whole yen", salary: utils.Money{Amount: 9000000_00, Currency: "JPY"}},
+		{name: "fractional yen", salary: utils.Money{Amount: 9000000_50, Currency: "JPY"}, wantErr: cats.ErrInvalidSalary},
+		{name: "code outside ISO 4217", salary: utils.Money{Amount: 100_00, Currency: "XYZ"}, wantErr: cats.ErrInvalidSalary},
+		{name: "three decimal currency", salary: utils.Money{Amount: 100_00, Currency: "KWD"}, wantErr: cats.ErrInvalidSalary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			id := repo.seed(tt.years, tt.completed, usd(40000_00))
+
+			_, err := newService(t, repo).UpdateSalary(id, cats.UpdateSalaryRequest{Salary: &tt.salary})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("applies to new cats", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := newService(t, repo)
+
+		_, err := svc.CreateCat(cats.CreateCatRequest{Name: "Whiskers", Breed: "siamese", Salary: &utils.Money{Amount: 60000_00}})
+		assert.ErrorIs(t, err, cats.ErrInvalidSalary)
+		assert.Empty(t, repo.cats)
+
+		id, err := svc.CreateCat(cats.CreateCatRequest{Name: "Whiskers", Breed: "siamese", Salary: &utils.Money{Amount: 40000_00}})
+		require.NoError(t, err)
+		assert.Equal(t, "Siamese", repo.cats[id].Breed)
+	})
+
+	t.Run("experienced new cats are paid in their band", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := newService(t, repo)
+
+		id, err := svc.CreateCat(cats.CreateCatRequest{
+			Name: "Shadow", YearsOfExperience: 6, Breed: "siamese", Salary: &utils.Money{Amount: 90000_00},
+		})
+		require.NoError(t, err)
+		cat, err := svc.GetCat(id, cats.GetCatQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, "senior", cat.Rank)
+	})
+}
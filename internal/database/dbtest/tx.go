@@ -0,0 +1,51 @@
+// Package dbtest holds helpers for the in-memory repositories used by
+// service tests.
+package dbtest
+
+import (
+	"reflect"
+)
+
+// InTx runs fn and, if it fails, restores every value that state points to
+// as it was before the call, like a rolled back transaction. Maps and slices
+// are copied deeply enough that fn may update their elements in place.
+func InTx(fn func() error, state ...any) error {
+	saved := make([]reflect.Value, len(state))
+	for i, p := range state {
+		saved[i] = clone(reflect.ValueOf(p).Elem())
+	}
+	if err := fn(); err != nil {
+		for i, p := range state {
+			reflect.ValueOf(p).Elem().Set(saved[i])
+		}
+		return err
+	}
+	return nil
+}
+
+func clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			c.SetMapIndex(it.Key(), clone(it.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(clone(v.Index(i)))
+		}
+		return c
+	default:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		return c
+	}
+}
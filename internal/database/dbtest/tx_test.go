@@ -0,0 +1,48 @@
+package dbtest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/database/dbtest"
+)
+
+func TestInTx(t *testing.T) {
+	t.Run("restores state when fn fails", func(t *testing.T) {
+		names := map[int64]string{1: "Whiskers"}
+		history := map[int64][]int{1: {1, 2}}
+		nextID := int64(1)
+
+		err := dbtest.InTx(func() error {
+			names[2] = "Shadow"
+			delete(names, 1)
+			history[1][0] = 9
+			history[1] = append(history[1], 3)
+			nextID++
+			return errors.New("boom")
+		}, &names, &history, &nextID)
+
+		require.EqualError(t, err, "boom")
+		assert.Equal(t, map[int64]string{1: "Whiskers"}, names)
+		assert.Equal(t, map[int64][]int{1: {1, 2}}, history)
+		assert.Equal(t, int64(1), nextID)
+	})
+
+	t.Run("keeps changes when fn succeeds", func(t *testing.T) {
+		names := map[int64]string{1: "Whiskers"}
+		var events []string
+
+		err := dbtest.InTx(func() error {
+			names[2] = "Shadow"
+			events = append(events, "created")
+			return nil
+		}, &names, &events)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]string{1: "Whiskers", 2: "Shadow"}, names)
+		assert.Equal(t, []string{"created"}, events)
+	})
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE breeds (
+    name TEXT PRIMARY KEY,
+    origin TEXT NOT NULL DEFAULT '',
+    temperament TEXT NOT NULL DEFAULT '',
+    aliases TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- the breeds themselves are written by the API at startup from the bundled
+-- snapshot, internal/utils/breeds_snapshot.json
+
+-- Keep breeds of existing cats valid
+INSERT INTO breeds (name)
+SELECT DISTINCT breed FROM cats
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE cats
+    ADD CONSTRAINT fk_cats_breed FOREIGN KEY (breed)
+    REFERENCES breeds(name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX idx_cats_breed ON cats(breed);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cats_breed;
+ALTER TABLE cats DROP CONSTRAINT IF EXISTS fk_cats_breed;
+DROP TABLE IF EXISTS breeds;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE INDEX idx_cats_salary_id ON cats(salary, id);
+
+CREATE INDEX idx_cats_years_of_experience_id ON cats(years_of_experience, id);
+
+CREATE INDEX idx_cats_name_id ON cats(name, id);
+
+CREATE INDEX idx_cats_lower_name ON cats(lower(name) text_pattern_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cats_lower_name;
+DROP INDEX IF EXISTS idx_cats_name_id;
+DROP INDEX IF EXISTS idx_cats_years_of_experience_id;
+DROP INDEX IF EXISTS idx_cats_salary_id;
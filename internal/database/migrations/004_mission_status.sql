@@ -0,0 +1,58 @@
+-- +goose Up
+ALTER TABLE missions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'planned'
+        CHECK (status IN ('planned', 'active', 'on_hold', 'completed', 'aborted', 'failed')),
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- complete missions stay complete, assigned ones are under way and the rest
+-- have not started yet
+UPDATE missions SET status = CASE
+    WHEN is_complete THEN 'completed'
+    WHEN cat_id IS NOT NULL THEN 'active'
+    ELSE 'planned'
+END;
+
+ALTER TABLE targets
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'completed', 'failed')),
+    ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE targets SET status = 'completed' WHERE is_complete;
+
+CREATE TABLE mission_status_changes (
+    id SERIAL PRIMARY KEY,
+    mission_id INT NOT NULL REFERENCES missions(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO mission_status_changes (mission_id, to_status)
+SELECT id, status FROM missions;
+
+CREATE INDEX idx_mission_status_changes_mission_id ON mission_status_changes(mission_id);
+
+DROP INDEX IF EXISTS idx_missions_is_complete;
+DROP INDEX IF EXISTS idx_targets_is_complete;
+ALTER TABLE missions DROP COLUMN is_complete;
+ALTER TABLE targets DROP COLUMN is_complete;
+
+CREATE INDEX idx_missions_status ON missions(status);
+
+CREATE INDEX idx_targets_status ON targets(status);
+
+-- +goose Down
+ALTER TABLE missions ADD COLUMN is_complete BOOLEAN DEFAULT FALSE;
+ALTER TABLE targets ADD COLUMN is_complete BOOLEAN DEFAULT FALSE;
+UPDATE missions SET is_complete = (status = 'completed');
+UPDATE targets SET is_complete = (status = 'completed');
+CREATE INDEX idx_missions_is_complete ON missions(is_complete);
+CREATE INDEX idx_targets_is_complete ON targets(is_complete);
+
+DROP INDEX IF EXISTS idx_targets_status;
+DROP INDEX IF EXISTS idx_missions_status;
+DROP TABLE IF EXISTS mission_status_changes;
+
+ALTER TABLE targets DROP COLUMN status_changed_at, DROP COLUMN status;
+ALTER TABLE missions DROP COLUMN status_changed_at, DROP COLUMN created_at, DROP COLUMN status;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE cats ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE missions ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE targets ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- the purge job looks up rows deleted before the retention cutoff
+CREATE INDEX idx_cats_deleted_at ON cats(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX idx_missions_deleted_at ON missions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX idx_targets_deleted_at ON targets(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DELETE FROM targets WHERE deleted_at IS NOT NULL;
+DELETE FROM missions WHERE deleted_at IS NOT NULL;
+DELETE FROM cats WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_targets_deleted_at;
+DROP INDEX IF EXISTS idx_missions_deleted_at;
+DROP INDEX IF EXISTS idx_cats_deleted_at;
+
+ALTER TABLE targets DROP COLUMN deleted_at;
+ALTER TABLE missions DROP COLUMN deleted_at;
+ALTER TABLE cats DROP COLUMN deleted_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- assignment changes are kept in the mission history next to status
+-- changes; from_status equals to_status when only the cat changed
+ALTER TABLE mission_status_changes
+    ADD COLUMN from_cat_id INT REFERENCES cats(id) ON DELETE SET NULL,
+    ADD COLUMN to_cat_id INT REFERENCES cats(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE mission_status_changes DROP COLUMN to_cat_id, DROP COLUMN from_cat_id;
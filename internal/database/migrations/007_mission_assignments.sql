@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE mission_assignments (
+    id SERIAL PRIMARY KEY,
+    mission_id INT NOT NULL REFERENCES missions(id) ON DELETE CASCADE,
+    cat_id INT NOT NULL REFERENCES cats(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    released_at TIMESTAMPTZ,
+    reason TEXT CHECK (reason IN (
+        'unassigned', 'reassigned', 'completed', 'aborted', 'failed', 'mission_deleted', 'cat_deleted'
+    )),
+    CHECK ((released_at IS NULL) = (reason IS NULL))
+);
+
+-- current assignees; closed missions keep their cat but the assignment ended
+-- when the mission was closed
+INSERT INTO mission_assignments (mission_id, cat_id, assigned_at, released_at, reason)
+SELECT id, cat_id, created_at,
+       CASE WHEN status IN ('completed', 'aborted', 'failed') THEN status_changed_at END,
+       CASE WHEN status IN ('completed', 'aborted', 'failed') THEN status END
+FROM missions
+WHERE cat_id IS NOT NULL;
+
+-- a mission has at most one cat at a time
+CREATE UNIQUE INDEX idx_mission_assignments_open ON mission_assignments(mission_id) WHERE released_at IS NULL;
+
+CREATE INDEX idx_mission_assignments_mission_id ON mission_assignments(mission_id);
+
+CREATE INDEX idx_mission_assignments_cat_id ON mission_assignments(cat_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_mission_assignments_cat_id;
+DROP INDEX IF EXISTS idx_mission_assignments_mission_id;
+DROP INDEX IF EXISTS idx_mission_assignments_open;
+DROP TABLE IF EXISTS mission_assignments;
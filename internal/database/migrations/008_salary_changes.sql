@@ -0,0 +1,23 @@
+-- +goose Up
+-- old_salary and applied_at stay NULL until a scheduled change takes effect
+CREATE TABLE salary_changes (
+    id SERIAL PRIMARY KEY,
+    cat_id INT NOT NULL REFERENCES cats(id) ON DELETE CASCADE,
+    old_salary NUMERIC(10,2),
+    new_salary NUMERIC(10,2) NOT NULL CHECK (new_salary >= 0),
+    effective_date DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    applied_at TIMESTAMPTZ,
+    CHECK ((applied_at IS NULL) = (old_salary IS NULL))
+);
+
+CREATE INDEX idx_salary_changes_cat_id ON salary_changes(cat_id, effective_date);
+
+CREATE INDEX idx_salary_changes_pending ON salary_changes(effective_date) WHERE applied_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_salary_changes_pending;
+DROP INDEX IF EXISTS idx_salary_changes_cat_id;
+DROP TABLE IF EXISTS salary_changes;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- period_start and period_end are the first and last day paid
+CREATE TABLE payroll_runs (
+    id SERIAL PRIMARY KEY,
+    frequency TEXT NOT NULL CHECK (frequency IN ('monthly', 'biweekly')),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    total_gross NUMERIC(12,2) NOT NULL,
+    total_deductions NUMERIC(12,2) NOT NULL,
+    total_net NUMERIC(12,2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX idx_payroll_runs_period ON payroll_runs(period_start, period_end);
+
+-- cat_id has no foreign key so that pay records outlive purged cats
+CREATE TABLE payroll_items (
+    id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL REFERENCES payroll_runs(id),
+    cat_id INT NOT NULL,
+    cat_name TEXT NOT NULL,
+    base_salary NUMERIC(12,2) NOT NULL,
+    missions_completed INT NOT NULL CHECK (missions_completed >= 0),
+    mission_bonus NUMERIC(12,2) NOT NULL,
+    deductions NUMERIC(12,2) NOT NULL,
+    net_pay NUMERIC(12,2) NOT NULL,
+    UNIQUE (run_id, cat_id)
+);
+
+CREATE INDEX idx_payroll_items_cat_id ON payroll_items(cat_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION payroll_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'payroll records cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER payroll_runs_immutable BEFORE UPDATE OR DELETE ON payroll_runs
+    FOR EACH ROW EXECUTE FUNCTION payroll_immutable();
+
+CREATE TRIGGER payroll_items_immutable BEFORE UPDATE OR DELETE ON payroll_items
+    FOR EACH ROW EXECUTE FUNCTION payroll_immutable();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS payroll_items_immutable ON payroll_items;
+DROP TRIGGER IF EXISTS payroll_runs_immutable ON payroll_runs;
+DROP FUNCTION IF EXISTS payroll_immutable();
+DROP INDEX IF EXISTS idx_payroll_items_cat_id;
+DROP TABLE IF EXISTS payroll_items;
+DROP INDEX IF EXISTS idx_payroll_runs_period;
+DROP TABLE IF EXISTS payroll_runs;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- existing salaries and payroll runs were all paid in US dollars
+ALTER TABLE cats ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE salary_changes
+    ADD COLUMN old_currency TEXT CHECK (old_currency ~ '^[A-Z]{3}$'),
+    ADD COLUMN new_currency TEXT NOT NULL DEFAULT 'USD' CHECK (new_currency ~ '^[A-Z]{3}$');
+
+UPDATE salary_changes SET old_currency = 'USD' WHERE old_salary IS NOT NULL;
+
+ALTER TABLE salary_changes ADD CHECK ((old_salary IS NULL) = (old_currency IS NULL));
+
+-- payroll runs pay the cats of one currency; adding the column does not
+-- fire the immutability triggers
+ALTER TABLE payroll_runs ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE INDEX idx_cats_currency ON cats(currency);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_cats_currency;
+ALTER TABLE payroll_runs DROP COLUMN IF EXISTS currency;
+ALTER TABLE salary_changes DROP COLUMN IF EXISTS new_currency, DROP COLUMN IF EXISTS old_currency;
+ALTER TABLE cats DROP COLUMN IF EXISTS currency;
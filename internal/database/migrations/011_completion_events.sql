@@ -0,0 +1,50 @@
+-- +goose Up
+-- completion events are written by the missions service and consumed by the
+-- scorer, which sets processed_at. started_at is when the credited cat's
+-- assignment to the mission started. There are no foreign keys so that
+-- events outlive purged missions and cats.
+CREATE TABLE completion_events (
+    id SERIAL PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('mission_completed', 'mission_failed', 'target_completed')),
+    mission_id INT NOT NULL,
+    target_id INT,
+    cat_id INT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    processed_at TIMESTAMPTZ,
+    CHECK ((type = 'target_completed') = (target_id IS NOT NULL))
+);
+
+CREATE INDEX idx_completion_events_pending ON completion_events(id) WHERE processed_at IS NULL;
+
+-- past missions, credited to the cat whose assignment they closed
+INSERT INTO completion_events (type, mission_id, cat_id, started_at, occurred_at)
+SELECT 'mission_' || reason, mission_id, cat_id, assigned_at, released_at
+FROM mission_assignments
+WHERE reason IN ('completed', 'failed');
+
+-- past targets, credited to the cat assigned when the target was completed
+INSERT INTO completion_events (type, mission_id, target_id, cat_id, started_at, occurred_at)
+SELECT DISTINCT ON (t.id) 'target_completed', t.mission_id, t.id, a.cat_id, a.assigned_at, t.status_changed_at
+FROM targets t
+JOIN mission_assignments a ON a.mission_id = t.mission_id
+    AND a.assigned_at <= t.status_changed_at
+    AND (a.released_at IS NULL OR a.released_at >= t.status_changed_at)
+WHERE t.status = 'completed'
+ORDER BY t.id, a.assigned_at DESC;
+
+-- running totals per cat; completion_seconds adds up the time from
+-- assignment to completion of every completed mission
+CREATE TABLE cat_stats (
+    cat_id INT PRIMARY KEY,
+    missions_completed INT NOT NULL DEFAULT 0,
+    missions_failed INT NOT NULL DEFAULT 0,
+    targets_neutralized INT NOT NULL DEFAULT 0,
+    completion_seconds BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS cat_stats;
+DROP INDEX IF EXISTS idx_completion_events_pending;
+DROP TABLE IF EXISTS completion_events;
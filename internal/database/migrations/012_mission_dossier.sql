@@ -0,0 +1,27 @@
+-- +goose Up
+-- priority_rank orders priorities from low to critical for sorting
+ALTER TABLE missions
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN priority TEXT NOT NULL DEFAULT 'medium'
+        CHECK (priority IN ('low', 'medium', 'high', 'critical')),
+    ADD COLUMN priority_rank SMALLINT GENERATED ALWAYS AS (
+        CASE priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 ELSE 3 END
+    ) STORED,
+    ADD COLUMN start_date DATE,
+    ADD COLUMN deadline DATE,
+    ADD CONSTRAINT missions_deadline_after_start CHECK (deadline >= start_date);
+
+CREATE INDEX idx_missions_priority_rank_id ON missions(priority_rank, id);
+
+CREATE INDEX idx_missions_deadline_id ON missions(COALESCE(deadline, 'infinity'::date), id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_missions_deadline_id;
+DROP INDEX IF EXISTS idx_missions_priority_rank_id;
+ALTER TABLE missions
+    DROP CONSTRAINT IF EXISTS missions_deadline_after_start,
+    DROP COLUMN IF EXISTS deadline,
+    DROP COLUMN IF EXISTS start_date,
+    DROP COLUMN IF EXISTS priority_rank,
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- assignments are mission history and outlive purged cats, which only clear
+-- their cat_id
+ALTER TABLE mission_assignments
+    ALTER COLUMN cat_id DROP NOT NULL,
+    DROP CONSTRAINT mission_assignments_cat_id_fkey,
+    ADD CONSTRAINT mission_assignments_cat_id_fkey
+        FOREIGN KEY (cat_id) REFERENCES cats(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM mission_assignments WHERE cat_id IS NULL;
+ALTER TABLE mission_assignments
+    DROP CONSTRAINT mission_assignments_cat_id_fkey,
+    ADD CONSTRAINT mission_assignments_cat_id_fkey
+        FOREIGN KEY (cat_id) REFERENCES cats(id) ON DELETE CASCADE,
+    ALTER COLUMN cat_id SET NOT NULL;
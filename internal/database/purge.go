@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	DefaultPurgeRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval  = time.Hour
+)
+
+// PurgeConfig configures how long soft-deleted rows are kept and how often
+// the purger looks for expired ones
+type PurgeConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// PurgeConfigFromEnv builds the purge config from PURGE_* variables
+func PurgeConfigFromEnv() PurgeConfig {
+	cfg := PurgeConfig{
+		Retention: DefaultPurgeRetention,
+		Interval:  DefaultPurgeInterval,
+	}
+	if retention, err := time.ParseDuration(os.Getenv("PURGE_RETENTION")); err == nil {
+		cfg.Retention = retention
+	}
+	if interval, err := time.ParseDuration(os.Getenv("PURGE_INTERVAL")); err == nil {
+		cfg.Interval = interval
+	}
+	return cfg
+}
+
+// Execer is implemented by *sql.DB
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// PurgeResult counts the rows removed by one purge
+type PurgeResult struct {
+	Targets  int64
+	Missions int64
+	Cats     int64
+}
+
+// Purger hard deletes rows that were soft deleted longer ago than the
+// retention window
+type Purger struct {
+	db  Execer
+	cfg PurgeConfig
+	now func() time.Time
+}
+
+func NewPurger(db Execer, cfg PurgeConfig) *Purger {
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultPurgeRetention
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPurgeInterval
+	}
+	return &Purger{db: db, cfg: cfg, now: time.Now}
+}
+
+// purgeQueries delete targets, missions and cats in that order, matching
+// the fields of PurgeResult. Children go first so that purging a mission
+// never cascades to targets that are still inside their retention window.
+var purgeQueries = []string{
+	`DELETE FROM targets WHERE deleted_at < $1`,
+	`DELETE FROM missions WHERE deleted_at < $1
+	 AND NOT EXISTS (SELECT 1 FROM targets WHERE targets.mission_id = missions.id)`,
+	`DELETE FROM cats WHERE deleted_at < $1`,
+}
+
+// Purge removes the expired rows once
+func (p *Purger) Purge(ctx context.Context) (PurgeResult, error) {
+	cutoff := p.now().Add(-p.cfg.Retention)
+
+	var res PurgeResult
+	counts := []*int64{&res.Targets, &res.Missions, &res.Cats}
+	for i, query := range purgeQueries {
+		r, err := p.db.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return res, err
+		}
+		*counts[i], _ = r.RowsAffected()
+	}
+	return res, nil
+}
+
+// Run purges every interval until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := p.Purge(ctx)
+		if err != nil {
+			log.Printf("purge: %v", err)
+		} else if res.Targets+res.Missions+res.Cats > 0 {
+			log.Printf("purge: removed %d target(s), %d mission(s), %d cat(s)", res.Targets, res.Missions, res.Cats)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
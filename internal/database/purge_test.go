@@ -0,0 +1,85 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/database"
+)
+
+type rowsAffected int64
+
+func (n rowsAffected) LastInsertId() (int64, error) { return 0, nil }
+func (n rowsAffected) RowsAffected() (int64, error) { return int64(n), nil }
+
+// fakeExecer records the statements it runs and reports the given number of
+// affected rows per table
+type fakeExecer struct {
+	affected map[string]int64
+	failOn   string
+	queries  []string
+	cutoffs  []time.Time
+}
+
+func (e *fakeExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	e.cutoffs = append(e.cutoffs, args[0].(time.Time))
+	for table, n := range e.affected {
+		if strings.HasPrefix(query, "DELETE FROM "+table+" ") {
+			if table == e.failOn {
+				return nil, errors.New("database error")
+			}
+			return rowsAffected(n), nil
+		}
+	}
+	return rowsAffected(0), nil
+}
+
+func TestPurge(t *testing.T) {
+	db := &fakeExecer{affected: map[string]int64{"targets": 4, "missions": 1, "cats": 2}}
+	p := database.NewPurger(db, database.PurgeConfig{Retention: 48 * time.Hour, Interval: time.Minute})
+
+	before := time.Now()
+	res, err := p.Purge(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, database.PurgeResult{Targets: 4, Missions: 1, Cats: 2}, res)
+	require.Len(t, db.queries, 3)
+	assert.True(t, strings.HasPrefix(db.queries[0], "DELETE FROM targets "))
+	assert.True(t, strings.HasPrefix(db.queries[1], "DELETE FROM missions "))
+	assert.True(t, strings.HasPrefix(db.queries[2], "DELETE FROM cats "))
+	for _, cutoff := range db.cutoffs {
+		assert.WithinDuration(t, before.Add(-48*time.Hour), cutoff, time.Minute)
+	}
+}
+
+func TestPurgeStopsOnError(t *testing.T) {
+	db := &fakeExecer{affected: map[string]int64{"targets": 4, "missions": 1, "cats": 2}, failOn: "missions"}
+	p := database.NewPurger(db, database.PurgeConfig{})
+
+	res, err := p.Purge(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, database.PurgeResult{Targets: 4}, res)
+	assert.Len(t, db.queries, 2)
+	assert.WithinDuration(t, time.Now().Add(-database.DefaultPurgeRetention), db.cutoffs[0], time.Minute)
+}
+
+func TestPurgeConfigFromEnv(t *testing.T) {
+	t.Setenv("PURGE_RETENTION", "")
+	t.Setenv("PURGE_INTERVAL", "")
+	assert.Equal(t, database.PurgeConfig{
+		Retention: database.DefaultPurgeRetention,
+		Interval:  database.DefaultPurgeInterval,
+	}, database.PurgeConfigFromEnv())
+
+	t.Setenv("PURGE_RETENTION", "168h")
+	t.Setenv("PURGE_INTERVAL", "10m")
+	assert.Equal(t, database.PurgeConfig{Retention: 168 * time.Hour, Interval: 10 * time.Minute}, database.PurgeConfigFromEnv())
+}
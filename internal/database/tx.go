@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Conn is what a repository runs its queries on: the connection pool, or a
+// transaction the repository is bound to
+type Conn struct {
+	DBTX
+	pool *sql.DB
+}
+
+func NewConn(db *sql.DB) Conn {
+	return Conn{DBTX: db, pool: db}
+}
+
+// InTx runs fn with a connection bound to a single transaction, committing
+// if fn succeeds. Calls on a connection that is already in a transaction
+// join it.
+func (c Conn) InTx(fn func(tx Conn) error) error {
+	if c.pool == nil {
+		return fn(c)
+	}
+
+	tx, err := c.pool.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(Conn{DBTX: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -3,22 +3,37 @@ package missions
 import (
 	"database/sql"
 	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"spy-cats/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type MissionService interface {
 	CreateMission(req CreateMissionRequest) (*Mission, error)
-	DeleteMission(id int64) error
-	MarkMissionComplete(id int64) error
-	AddTarget(missionID int64, req CreateTarget) error
-	UpdateTarget(id int64, req UpdateTargetRequest) error
+	DeleteMission(id int64, unassign bool) (*DeletedMission, error)
+	RestoreMission(id int64) (*Mission, error)
+	MarkMissionComplete(id int64, force bool) error
+	TransitionMission(id int64, to MissionStatus) (*Mission, error)
+	GetMissionTransitions(id int64) ([]StatusChange, error)
+	GetMissionAssignments(id int64) ([]Assignment, error)
+	AddTarget(missionID int64, req CreateTarget) (*Target, error)
+	GetTarget(id int64) (*Target, error)
+	GetMissionTargets(missionID int64) ([]Target, error)
+	ListTargets(q ListTargetsQuery) (*TargetPage, error)
+	UpdateTarget(id int64, req UpdateTargetRequest) (*Target, error)
+	MoveTarget(id, missionID int64) (*Target, error)
 	DeleteTarget(id int64) error
-	GetAllMissions() ([]Mission, error)
+	RestoreTarget(id int64) (*Target, error)
+	GetAllMissions(q ListMissionsQuery) (*MissionPage, error)
 	GetMissionByID(id int64) (*Mission, error)
 	AssignCat(missionID, catID int64) error
+	ReassignCat(missionID, catID int64) (*Mission, error)
+	UnassignCat(missionID int64) (*Mission, error)
 }
 
 type Handler struct {
@@ -31,13 +46,15 @@ func NewHandler(service MissionService) *Handler {
 
 // CreateMission creates a new mission
 // @Summary      Create a mission
-// @Description  Create a new mission with targets
+// @Description  Create a new mission with 1 to 3 targets. The priority defaults to medium, and the deadline may not precede the start date or, unless the mission is created complete, today. A mission created complete must have all its targets complete and credits its cat with them; a planned mission's targets must all be pending.
 // @Tags         missions
 // @Accept       json
 // @Produce      json
 // @Param        mission  body      CreateMissionRequest  true  "Mission information"
 // @Success      201      {object}  Mission               "Successfully created mission"
-// @Failure      400      {object}  map[string]string     "Invalid input"
+// @Failure      400      {object}  map[string]string     "Invalid input, target count, target status or schedule"
+// @Failure      404      {object}  map[string]string     "Cat not found"
+// @Failure      409      {object}  map[string]string     "Cat already has an active mission or its rank does not allow that many targets"
 // @Failure      500      {object}  map[string]string     "Internal server error"
 // @Router       /missions [post]
 func (h *Handler) CreateMission(c *gin.Context) {
@@ -49,7 +66,18 @@ func (h *Handler) CreateMission(c *gin.Context) {
 
 	mission, err := h.service.CreateMission(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, ErrTargetLimit), errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrOpenTargets),
+			errors.Is(err, ErrInvalidTransition):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrCatNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrCatBusy), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("create mission: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create mission"})
+		}
 		return
 	}
 	c.JSON(http.StatusCreated, mission)
@@ -57,43 +85,192 @@ func (h *Handler) CreateMission(c *gin.Context) {
 
 // DeleteMission deletes a mission
 // @Summary      Delete a mission
-// @Description  Delete a mission by its ID
+// @Description  Soft delete a mission and its targets by the mission ID and return what was deleted. Assigned missions are only deleted with unassign=true, which releases the cat first; active and on hold missions cannot be deleted. Deleted missions are hidden until restored and purged after the retention window.
 // @Tags         missions
-// @Param        id  path      int  true  "Mission ID"
-// @Success      200 {object}  map[string]string "Mission deleted successfully"
-// @Failure      400 {object}  map[string]string "Bad request"
+// @Produce      json
+// @Param        id        path      int   true   "Mission ID"
+// @Param        unassign  query     bool  false  "Release the assigned cat and delete the mission"
+// @Success      200  {object}  DeletedMission    "Summary of the deleted mission"
+// @Failure      400  {object}  map[string]string "Invalid unassign flag"
+// @Failure      404  {object}  map[string]string "Mission not found"
+// @Failure      409  {object}  map[string]string "Mission is assigned to a cat or in progress"
+// @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /missions/{id} [delete]
 func (h *Handler) DeleteMission(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err := h.service.DeleteMission(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	unassign := false
+	if v := c.Query("unassign"); v != "" {
+		var err error
+		if unassign, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unassign flag"})
+			return
+		}
+	}
+
+	summary, err := h.service.DeleteMission(id, unassign)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMissionAssigned), errors.Is(err, ErrMissionInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete mission"})
+		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "mission deleted"})
+	c.JSON(http.StatusOK, summary)
+}
+
+// RestoreMission restores a deleted mission
+// @Summary      Restore a mission
+// @Description  Undelete a soft-deleted mission that has not been purged yet, along with the targets deleted with it
+// @Tags         missions
+// @Produce      json
+// @Param        id   path      int  true  "Mission ID"
+// @Success      200  {object}  Mission           "Restored mission"
+// @Failure      404  {object}  map[string]string "No deleted mission with this ID"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/restore [post]
+func (h *Handler) RestoreMission(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	mission, err := h.service.RestoreMission(id)
+	if err != nil {
+		if errors.Is(err, ErrMissionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore mission"})
+		return
+	}
+	c.JSON(http.StatusOK, mission)
 }
 
 // MarkMissionComplete marks a mission as complete
 // @Summary      Mark mission complete
-// @Description  Mark a mission as complete by its ID
+// @Description  Mark an active mission as complete by its ID. Missions with incomplete targets are rejected unless force is set, which completes the targets as well.
 // @Tags         missions
-// @Param        id  path      int  true  "Mission ID"
+// @Param        id     path      int   true   "Mission ID"
+// @Param        force  query     bool  false  "Also complete all open targets"
 // @Success      200 {object}  map[string]string "Mission marked complete"
+// @Failure      400 {object}  map[string]string "Invalid force flag"
 // @Failure      404 {object}  map[string]string "Mission not found"
+// @Failure      409 {object}  map[string]string "Mission has incomplete targets or cannot be completed from its status"
 // @Failure      500 {object}  map[string]string "Internal server error"
 // @Router       /missions/{id}/complete [patch]
 func (h *Handler) MarkMissionComplete(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err := h.service.MarkMissionComplete(id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+	force := false
+	if v := c.Query("force"); v != "" {
+		var err error
+		if force, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid force flag"})
+			return
+		}
+	}
+
+	if err := h.service.MarkMissionComplete(id, force); err != nil {
+		if errors.Is(err, ErrMissionNotFound) || errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, ErrOpenTargets) || errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrMissionClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("complete mission %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete mission"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "mission marked complete"})
 }
 
+// TransitionMission moves a mission to another status
+// @Summary      Change mission status
+// @Description  Move a mission through its lifecycle: planned -> active (needs an assigned cat), active <-> on_hold, active -> completed (needs all targets closed), and planned, active or on_hold -> aborted, active -> failed. Completed, aborted and failed missions are final.
+// @Tags         missions
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int                true  "Mission ID"
+// @Param        transition  body      TransitionRequest  true  "Target status"
+// @Success      200         {object}  Mission            "Updated mission"
+// @Failure      400         {object}  map[string]string  "Bad request"
+// @Failure      404         {object}  map[string]string  "Mission not found"
+// @Failure      409         {object}  map[string]string  "Transition not allowed"
+// @Failure      500         {object}  map[string]string  "Internal server error"
+// @Router       /missions/{id}/transitions [post]
+func (h *Handler) TransitionMission(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	var req TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mission, err := h.service.TransitionMission(id, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+		case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrOpenTargets),
+			errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change mission status"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, mission)
+}
+
+// GetMissionTransitions lists the status history of a mission
+// @Summary      Mission status history
+// @Description  Get every status a mission went through with the time of each change, oldest first
+// @Tags         missions
+// @Produce      json
+// @Param        id   path      int  true  "Mission ID"
+// @Success      200  {array}   StatusChange      "Status history"
+// @Failure      404  {object}  map[string]string "Mission not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/transitions [get]
+func (h *Handler) GetMissionTransitions(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	changes, err := h.service.GetMissionTransitions(id)
+	if err != nil {
+		if errors.Is(err, ErrMissionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch status history"})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetMissionAssignments lists the cats that worked a mission
+// @Summary      Mission assignment history
+// @Description  Get every cat assigned to a mission with when and why each assignment ended, oldest first
+// @Tags         missions
+// @Produce      json
+// @Param        id   path      int  true  "Mission ID"
+// @Success      200  {array}   Assignment        "Assignment history"
+// @Failure      404  {object}  map[string]string "Mission not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/assignments [get]
+func (h *Handler) GetMissionAssignments(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	assignments, err := h.service.GetMissionAssignments(id)
+	if err != nil {
+		if errors.Is(err, ErrMissionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch assignment history"})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
 // AddTarget adds a target to a mission
 // @Summary      Add target to mission
 // @Description  Add a new target to an existing mission
@@ -102,8 +279,12 @@ func (h *Handler) MarkMissionComplete(c *gin.Context) {
 // @Produce      json
 // @Param        id      path      int           true  "Mission ID"
 // @Param        target  body      CreateTarget  true  "Target information"
-// @Success      201     {object}  map[string]string "Target added successfully"
-// @Failure      400     {object}  map[string]string "Bad request"
+// @Success      201     {object}  Target            "Created target"
+// @Header       201     {string}  Location          "URL of the created target"
+// @Failure      400     {object}  map[string]string "Bad request or mission already has the maximum number of targets"
+// @Failure      404     {object}  map[string]string "Mission not found"
+// @Failure      409     {object}  map[string]string "Mission is complete or its cat's rank does not allow another target"
+// @Failure      500     {object}  map[string]string "Internal server error"
 // @Router       /missions/{id}/targets [post]
 func (h *Handler) AddTarget(c *gin.Context) {
 	missionID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -112,23 +293,172 @@ func (h *Handler) AddTarget(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.AddTarget(missionID, req); err != nil {
+	target, err := h.service.AddTarget(missionID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+		case errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTargetLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("add target to mission %d: %v", missionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add target"})
+		}
+		return
+	}
+
+	// the route is mounted as <missions>/:id/targets and targets live at <missions>/targets/:targetId
+	base := strings.TrimSuffix(c.FullPath(), "/:id/targets")
+	c.Header("Location", base+"/targets/"+strconv.FormatInt(target.ID, 10))
+	c.JSON(http.StatusCreated, target)
+}
+
+// GetTarget retrieves a target by ID
+// @Summary      Get a target
+// @Description  Get a target by its ID
+// @Tags         missions
+// @Produce      json
+// @Param        targetId  path      int  true  "Target ID"
+// @Success      200       {object}  Target            "Target information"
+// @Failure      404       {object}  map[string]string "Target not found"
+// @Failure      500       {object}  map[string]string "Internal server error"
+// @Router       /missions/targets/{targetId} [get]
+func (h *Handler) GetTarget(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("targetId"), 10, 64)
+	target, err := h.service.GetTarget(id)
+	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "target not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch target"})
+		return
+	}
+	c.JSON(http.StatusOK, target)
+}
+
+// GetMissionTargets lists the targets of a mission
+// @Summary      List mission targets
+// @Description  Get all targets of a mission ordered by ID
+// @Tags         missions
+// @Produce      json
+// @Param        id   path      int  true  "Mission ID"
+// @Success      200  {array}   Target            "Targets of the mission"
+// @Failure      404  {object}  map[string]string "Mission not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/targets [get]
+func (h *Handler) GetMissionTargets(c *gin.Context) {
+	missionID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	targets, err := h.service.GetMissionTargets(missionID)
+	if err != nil {
+		if errors.Is(err, ErrMissionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch targets"})
+		return
+	}
+	c.JSON(http.StatusOK, targets)
+}
+
+// ListTargets searches targets across missions
+// @Summary      Search targets
+// @Description  Get a page of targets across all missions ordered by ID, optionally filtered. Pass the X-Next-Cursor value as cursor to get the next page.
+// @Tags         missions
+// @Produce      json
+// @Param        country      query     string  false  "Country (case-insensitive)"
+// @Param        status       query     string  false  "Target status" Enums(pending, completed, failed)
+// @Param        is_complete  query     bool    false  "Completion status"
+// @Param        mission_id   query     int     false  "Mission ID"
+// @Param        limit        query     int     false  "Page size (default 50, max 200)"
+// @Param        cursor       query     string  false  "Cursor from a previous page"
+// @Param        include_deleted  query  bool    false  "Also list soft-deleted targets"
+// @Success      200  {array}   Target "List of targets"
+// @Header       200  {int}     X-Total-Count  "Number of targets matching the filters"
+// @Header       200  {string}  X-Next-Cursor  "Cursor of the next page, absent on the last page"
+// @Header       200  {string}  Link           "URL of the next page with rel=next"
+// @Failure      400  {object}  map[string]string "Invalid query"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/targets [get]
+func (h *Handler) ListTargets(c *gin.Context) {
+	var q ListTargetsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": "target added"})
+
+	page, err := h.service.ListTargets(q)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch targets"})
+		return
+	}
+
+	utils.SetPageHeaders(c, page.Total, page.NextCursor)
+	c.JSON(http.StatusOK, page.Targets)
+}
+
+// MoveTarget moves a target to another mission
+// @Summary      Move target
+// @Description  Move a pending target to another open mission. The source mission must keep at least one target and the destination may not exceed three; an active source left without pending targets is closed.
+// @Tags         missions
+// @Accept       json
+// @Produce      json
+// @Param        targetId  path      int                true  "Target ID"
+// @Param        move      body      MoveTargetRequest  true  "Destination mission"
+// @Success      200       {object}  Target            "Moved target"
+// @Failure      400       {object}  map[string]string "Bad request or target limit exceeded"
+// @Failure      404       {object}  map[string]string "Target or mission not found"
+// @Failure      409       {object}  map[string]string "Target or mission is complete, or the destination cat's rank does not allow another target"
+// @Failure      500       {object}  map[string]string "Internal server error"
+// @Router       /missions/targets/{targetId}/move [post]
+func (h *Handler) MoveTarget(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("targetId"), 10, 64)
+	var req MoveTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.service.MoveTarget(id, req.MissionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTargetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "target not found"})
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+		case errors.Is(err, ErrTargetFrozen), errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed),
+			errors.Is(err, ErrTargetMoved), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrTargetLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("move target %d to mission %d: %v", id, req.MissionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move target"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, target)
 }
 
 // UpdateTarget updates a target
 // @Summary      Update target
-// @Description  Update target completion status and notes
+// @Description  Apply a JSON Merge Patch to a target: absent fields are left untouched and `"notes": null` clears the notes. A pending target of an active mission can be completed or failed; once no target is pending the mission is completed, or failed if every target failed. Notes are frozen once the target or its mission is closed, and closed targets cannot be reopened.
 // @Tags         missions
 // @Accept       json
 // @Produce      json
 // @Param        targetId  path      int                   true  "Target ID"
-// @Param        target    body      UpdateTargetRequest   true  "Target update information"
-// @Success      200       {object}  map[string]string     "Target updated successfully"
+// @Param        target    body      UpdateTargetRequest   true  "Target fields to change"
+// @Success      200       {object}  Target                "Updated target"
 // @Failure      400       {object}  map[string]string     "Bad request"
+// @Failure      404       {object}  map[string]string     "Target not found"
+// @Failure      409       {object}  map[string]string     "Target or mission is closed, or the status change is not allowed"
+// @Failure      500       {object}  map[string]string     "Internal server error"
 // @Router       /missions/targets/{targetId} [patch]
 func (h *Handler) UpdateTarget(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("targetId"), 10, 64)
@@ -138,45 +468,126 @@ func (h *Handler) UpdateTarget(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UpdateTarget(id, req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	target, err := h.service.UpdateTarget(id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTargetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "target not found"})
+		case errors.Is(err, ErrTargetFrozen), errors.Is(err, ErrInvalidTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidPatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("update target %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update target"})
+		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "target updated"})
+	c.JSON(http.StatusOK, target)
 }
 
 // DeleteTarget deletes a target
 // @Summary      Delete target
-// @Description  Delete a target by its ID
+// @Description  Soft delete a pending target of an open mission by its ID. The mission must keep at least one target and is closed once no pending targets remain. Deleted targets are hidden until restored and purged after the retention window.
 // @Tags         missions
 // @Param        targetId  path      int  true  "Target ID"
 // @Success      200       {object}  map[string]string "Target deleted successfully"
-// @Failure      400       {object}  map[string]string "Bad request"
+// @Failure      404       {object}  map[string]string "Target not found"
+// @Failure      409       {object}  map[string]string "Target or mission is closed, or it is the mission's last target"
+// @Failure      500       {object}  map[string]string "Internal server error"
 // @Router       /missions/targets/{targetId} [delete]
 func (h *Handler) DeleteTarget(c *gin.Context) {
 	id, _ := strconv.ParseInt(c.Param("targetId"), 10, 64)
 	if err := h.service.DeleteTarget(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, ErrTargetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "target not found"})
+		case errors.Is(err, ErrTargetFrozen), errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed),
+			errors.Is(err, ErrTargetLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete target"})
+		}
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "target deleted"})
 }
 
-// GetAllMissions retrieves all missions
-// @Summary      List all missions
-// @Description  Get a list of all missions in the system
+// RestoreTarget restores a deleted target
+// @Summary      Restore target
+// @Description  Undelete a soft-deleted target that has not been purged yet. Its mission must not be deleted or closed and must have fewer than 3 targets.
 // @Tags         missions
 // @Produce      json
+// @Param        targetId  path      int  true  "Target ID"
+// @Success      200       {object}  Target            "Restored target"
+// @Failure      404       {object}  map[string]string "No deleted target with this ID or its mission is deleted"
+// @Failure      409       {object}  map[string]string "Mission is closed or already has the maximum number of targets for itself or its cat's rank"
+// @Failure      500       {object}  map[string]string "Internal server error"
+// @Router       /missions/targets/{targetId}/restore [post]
+func (h *Handler) RestoreTarget(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("targetId"), 10, 64)
+	target, err := h.service.RestoreTarget(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTargetNotFound), errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed), errors.Is(err, ErrTargetLimit),
+			errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore target"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, target)
+}
+
+// GetAllMissions retrieves missions page by page
+// @Summary      List missions
+// @Description  Get a page of missions, optionally filtered and sorted. Missions without a deadline sort as if it were infinitely far off. Pass the X-Next-Cursor value as cursor to get the next page.
+// @Tags         missions
+// @Produce      json
+// @Param        status          query     string  false  "Mission status" Enums(planned, active, on_hold, completed, aborted, failed)
+// @Param        is_complete     query     bool    false  "Completion status"
+// @Param        cat_id          query     int     false  "Assigned cat ID"
+// @Param        unassigned      query     bool    false  "Only missions without a cat"
+// @Param        target_country  query     string  false  "Missions with a target in this country (case-insensitive)"
+// @Param        name            query     string  false  "Name contains (case-insensitive)"
+// @Param        priority        query     []string  false  "Any of these priorities" collectionFormat(multi) Enums(low, medium, high, critical)
+// @Param        deadline_after  query     string  false  "Deadline on or after this date (YYYY-MM-DD)"
+// @Param        deadline_before query     string  false  "Deadline on or before this date (YYYY-MM-DD)"
+// @Param        overdue         query     bool    false  "Only missions that are, or are not, open past their deadline"
+// @Param        sort            query     string  false  "Sort order, prefix with - for descending" Enums(id, -id, priority, -priority, deadline, -deadline)
+// @Param        include         query     string  false  "Embed related data" Enums(targets)
+// @Param        limit           query     int     false  "Page size (default 50, max 200)"
+// @Param        cursor          query     string  false  "Cursor from a previous page"
+// @Param        include_deleted query     bool    false  "Also list soft-deleted missions"
 // @Success      200  {array}   Mission "List of missions"
+// @Header       200  {int}     X-Total-Count  "Number of missions matching the filters"
+// @Header       200  {string}  X-Next-Cursor  "Cursor of the next page, absent on the last page"
+// @Header       200  {string}  Link           "URL of the next page with rel=next"
+// @Failure      400  {object}  map[string]string "Invalid query"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /missions [get]
 func (h *Handler) GetAllMissions(c *gin.Context) {
-	missions, err := h.service.GetAllMissions()
+	var q ListMissionsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.GetAllMissions(q)
 	if err != nil {
+		if errors.Is(err, ErrInvalidQuery) || errors.Is(err, utils.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch missions"})
 		return
 	}
-	c.JSON(http.StatusOK, missions)
+
+	utils.SetPageHeaders(c, page.Total, page.NextCursor)
+	c.JSON(http.StatusOK, page.Missions)
 }
 
 // GetMissionByID retrieves a specific mission by ID
@@ -205,7 +616,7 @@ func (h *Handler) GetMissionByID(c *gin.Context) {
 
 // AssignCat assigns a cat to a mission
 // @Summary      Assign cat to mission
-// @Description  Assign a spy cat to a mission
+// @Description  Assign a spy cat to an unassigned mission. Missions that already have another cat are reassigned with POST /missions/{id}/reassign.
 // @Tags         missions
 // @Accept       json
 // @Produce      json
@@ -213,7 +624,8 @@ func (h *Handler) GetMissionByID(c *gin.Context) {
 // @Param        request  body      AssignCatRequest  true  "Cat assignment information"
 // @Success      200      {object}  map[string]string "Cat assigned successfully"
 // @Failure      400      {object}  map[string]string "Bad request"
-// @Failure      404      {object}  map[string]string "Mission not found"
+// @Failure      404      {object}  map[string]string "Mission or cat not found"
+// @Failure      409      {object}  map[string]string "Mission is closed or assigned to another cat, or cat already has an active mission or its rank does not allow the mission's targets"
 // @Failure      500      {object}  map[string]string "Internal server error"
 // @Router       /missions/{id}/assign [put]
 func (h *Handler) AssignCat(c *gin.Context) {
@@ -227,13 +639,88 @@ func (h *Handler) AssignCat(c *gin.Context) {
 
 	err := h.service.AssignCat(id, req.CatID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		switch {
+		case errors.Is(err, ErrMissionNotFound), errors.Is(err, sql.ErrNoRows):
 			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
-			return
+		case errors.Is(err, ErrCatNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed), errors.Is(err, ErrCatBusy),
+			errors.Is(err, ErrMissionAssigned), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign cat"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign cat"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "cat assigned successfully"})
 }
+
+// ReassignCat hands a mission to another cat
+// @Summary      Reassign mission
+// @Description  Swap the cat of an assigned mission for another one in a single step. The change is recorded in the mission history.
+// @Tags         missions
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int               true  "Mission ID"
+// @Param        request  body      AssignCatRequest  true  "Cat taking over the mission"
+// @Success      200      {object}  Mission           "Reassigned mission"
+// @Failure      400      {object}  map[string]string "Bad request"
+// @Failure      404      {object}  map[string]string "Mission or cat not found"
+// @Failure      409      {object}  map[string]string "Mission is closed or unassigned, or cat already has an active mission or its rank does not allow the mission's targets"
+// @Failure      500      {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/reassign [post]
+func (h *Handler) ReassignCat(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	var req AssignCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mission, err := h.service.ReassignCat(id, req.CatID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+		case errors.Is(err, ErrCatNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed), errors.Is(err, ErrCatBusy),
+			errors.Is(err, ErrMissionUnassigned), errors.Is(err, ErrRankTooLow):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reassign cat"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, mission)
+}
+
+// UnassignCat releases the cat from a mission
+// @Summary      Unassign cat from mission
+// @Description  Release the cat of a mission. Active missions are put on hold until a cat is assigned again, and closed missions keep their cat. The change is recorded in the mission history.
+// @Tags         missions
+// @Produce      json
+// @Param        id   path      int  true  "Mission ID"
+// @Success      200  {object}  Mission           "Unassigned mission"
+// @Failure      404  {object}  map[string]string "Mission not found"
+// @Failure      409  {object}  map[string]string "Mission is closed"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /missions/{id}/assign [delete]
+func (h *Handler) UnassignCat(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	mission, err := h.service.UnassignCat(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMissionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "mission not found"})
+		case errors.Is(err, ErrMissionComplete), errors.Is(err, ErrMissionClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unassign cat"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, mission)
+}
@@ -5,15 +5,18 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"spy-cats/internal/missions"
+	"spy-cats/internal/utils"
 )
 
 type mockService struct {
@@ -28,24 +31,97 @@ func (m *mockService) CreateMission(req missions.CreateMissionRequest) (*mission
 	return args.Get(0).(*missions.Mission), args.Error(1)
 }
 
-func (m *mockService) DeleteMission(id int64) error {
+func (m *mockService) DeleteMission(id int64, unassign bool) (*missions.DeletedMission, error) {
+	args := m.Called(id, unassign)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.DeletedMission), args.Error(1)
+}
+
+func (m *mockService) RestoreMission(id int64) (*missions.Mission, error) {
 	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Mission), args.Error(1)
+}
+
+func (m *mockService) MarkMissionComplete(id int64, force bool) error {
+	args := m.Called(id, force)
 	return args.Error(0)
 }
 
-func (m *mockService) MarkMissionComplete(id int64) error {
+func (m *mockService) TransitionMission(id int64, to missions.MissionStatus) (*missions.Mission, error) {
+	args := m.Called(id, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Mission), args.Error(1)
+}
+
+func (m *mockService) GetMissionAssignments(id int64) ([]missions.Assignment, error) {
 	args := m.Called(id)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]missions.Assignment), args.Error(1)
+}
+
+func (m *mockService) GetMissionTransitions(id int64) ([]missions.StatusChange, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]missions.StatusChange), args.Error(1)
 }
 
-func (m *mockService) AddTarget(missionID int64, req missions.CreateTarget) error {
+func (m *mockService) AddTarget(missionID int64, req missions.CreateTarget) (*missions.Target, error) {
 	args := m.Called(missionID, req)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Target), args.Error(1)
+}
+
+func (m *mockService) GetTarget(id int64) (*missions.Target, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Target), args.Error(1)
+}
+
+func (m *mockService) GetMissionTargets(missionID int64) ([]missions.Target, error) {
+	args := m.Called(missionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]missions.Target), args.Error(1)
+}
+
+func (m *mockService) ListTargets(q missions.ListTargetsQuery) (*missions.TargetPage, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.TargetPage), args.Error(1)
+}
+
+func (m *mockService) MoveTarget(id, missionID int64) (*missions.Target, error) {
+	args := m.Called(id, missionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Target), args.Error(1)
 }
 
-func (m *mockService) UpdateTarget(id int64, req missions.UpdateTargetRequest) error {
+func (m *mockService) UpdateTarget(id int64, req missions.UpdateTargetRequest) (*missions.Target, error) {
 	args := m.Called(id, req)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Target), args.Error(1)
 }
 
 func (m *mockService) DeleteTarget(id int64) error {
@@ -53,9 +129,20 @@ func (m *mockService) DeleteTarget(id int64) error {
 	return args.Error(0)
 }
 
-func (m *mockService) GetAllMissions() ([]missions.Mission, error) {
-	args := m.Called()
-	return args.Get(0).([]missions.Mission), args.Error(1)
+func (m *mockService) RestoreTarget(id int64) (*missions.Target, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Target), args.Error(1)
+}
+
+func (m *mockService) GetAllMissions(q missions.ListMissionsQuery) (*missions.MissionPage, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.MissionPage), args.Error(1)
 }
 
 func (m *mockService) GetMissionByID(id int64) (*missions.Mission, error) {
@@ -71,6 +158,22 @@ func (m *mockService) AssignCat(missionID, catID int64) error {
 	return args.Error(0)
 }
 
+func (m *mockService) ReassignCat(missionID, catID int64) (*missions.Mission, error) {
+	args := m.Called(missionID, catID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Mission), args.Error(1)
+}
+
+func (m *mockService) UnassignCat(missionID int64) (*missions.Mission, error) {
+	args := m.Called(missionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*missions.Mission), args.Error(1)
+}
+
 func TestCreateMission(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -184,7 +287,104 @@ func TestCreateMission(t *testing.T) {
 			mockReturnMission: nil,
 			mockReturnErr:     errors.New("database connection error"),
 			expectedStatus:    http.StatusInternalServerError,
-			expectedBody:      `"error":"database connection error"`,
+			expectedBody:      `"error":"failed to create mission"`,
+		},
+		{
+			name: "too many targets",
+			body: missions.CreateMissionRequest{
+				Name: "Operation Crowd",
+				Targets: []missions.CreateTarget{
+					{Name: "A", Country: "Peru"},
+					{Name: "B", Country: "Peru"},
+					{Name: "C", Country: "Peru"},
+					{Name: "D", Country: "Peru"},
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "target missing country",
+			body:           `{"name": "Operation Vague", "targets": [{"name": "A"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name: "target limit from service",
+			body: missions.CreateMissionRequest{
+				Name:    "Operation Limit",
+				Targets: []missions.CreateTarget{{Name: "A", Country: "Peru"}},
+			},
+			mockReturnErr:  fmt.Errorf("%w, got 0", missions.ErrTargetLimit),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"a mission must have between 1 and 3 targets, got 0"`,
+		},
+		{
+			name: "cat not found",
+			body: missions.CreateMissionRequest{
+				CatID:   func() *int64 { id := int64(999); return &id }(),
+				Name:    "Operation Ghost",
+				Targets: []missions.CreateTarget{{Name: "Target", Country: "Peru"}},
+			},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+		{
+			name: "cat already on a mission",
+			body: missions.CreateMissionRequest{
+				CatID:   func() *int64 { id := int64(5); return &id }(),
+				Name:    "Operation Double",
+				Targets: []missions.CreateTarget{{Name: "Target", Country: "Peru"}},
+			},
+			mockReturnErr:  fmt.Errorf("%w: cat 5 is on mission 2", missions.ErrCatBusy),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"cat already has an active mission: cat 5 is on mission 2"`,
+		},
+		{
+			name: "success with dossier",
+			body: missions.CreateMissionRequest{
+				Name:        "Operation Embassy",
+				Description: "Recover the stolen plans",
+				Priority:    missions.PriorityCritical,
+				StartDate:   "2030-05-01",
+				Deadline:    "2030-05-31",
+				Targets:     []missions.CreateTarget{{Name: "Target", Country: "Peru"}},
+			},
+			mockReturnMission: &missions.Mission{
+				ID:          3,
+				Name:        "Operation Embassy",
+				Description: "Recover the stolen plans",
+				Priority:    missions.PriorityCritical,
+				StartDate:   func() *string { d := "2030-05-01"; return &d }(),
+				Deadline:    func() *string { d := "2030-05-31"; return &d }(),
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"priority":"critical","start_date":"2030-05-01","deadline":"2030-05-31","overdue":false`,
+		},
+		{
+			name:           "unknown priority",
+			body:           `{"name":"Operation Double","priority":"urgent","targets":[{"name":"Target","country":"Peru"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "malformed deadline",
+			body:           `{"name":"Operation Double","deadline":"31/05/2030","targets":[{"name":"Target","country":"Peru"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name: "deadline before start date",
+			body: missions.CreateMissionRequest{
+				Name:      "Operation Double",
+				StartDate: "2030-05-31",
+				Deadline:  "2030-05-01",
+				Targets:   []missions.CreateTarget{{Name: "Target", Country: "Peru"}},
+			},
+			mockReturnErr:  fmt.Errorf("%w: deadline 2030-05-01 is before start_date 2030-05-31", missions.ErrInvalidSchedule),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid schedule: deadline 2030-05-01 is before start_date 2030-05-31"`,
 		},
 	}
 
@@ -225,33 +425,72 @@ func TestCreateMission(t *testing.T) {
 func TestDeleteMission(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	catID := int64(5)
 	tests := []struct {
-		name           string
-		missionID      string
-		mockReturnErr  error
-		expectedStatus int
-		expectedBody   string
+		name             string
+		target           string
+		callsService     bool
+		expectedUnassign bool
+		mockSummary      *missions.DeletedMission
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
 	}{
 		{
 			name:           "success",
-			missionID:      "1",
-			mockReturnErr:  nil,
+			target:         "1",
+			callsService:   true,
+			mockSummary:    &missions.DeletedMission{ID: 1, Name: "Operation Stealth", Status: missions.MissionPlanned, DeletedTargets: 2},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `"message":"mission deleted"`,
+			expectedBody:   `"deleted_targets":2`,
 		},
 		{
-			name:           "mission not found",
-			missionID:      "999",
-			mockReturnErr:  errors.New("mission not found"),
+			name:             "unassigns cat first",
+			target:           "1?unassign=true",
+			callsService:     true,
+			expectedUnassign: true,
+			mockSummary:      &missions.DeletedMission{ID: 1, Status: missions.MissionCompleted, UnassignedCatID: &catID, DeletedTargets: 1},
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"unassigned_cat_id":5`,
+		},
+		{
+			name:           "invalid unassign flag",
+			target:         "1?unassign=maybe",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `"error":"mission not found"`,
+			expectedBody:   `"error":"invalid unassign flag"`,
+		},
+		{
+			name:           "mission not found",
+			target:         "999",
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found with id 999"`,
+		},
+		{
+			name:           "assigned mission",
+			target:         "1",
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: mission 1 is assigned to cat 5", missions.ErrMissionAssigned),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `mission 1 is assigned to cat 5`,
+		},
+		{
+			name:             "mission in progress",
+			target:           "1?unassign=true",
+			callsService:     true,
+			expectedUnassign: true,
+			mockReturnErr:    fmt.Errorf("%w: active mission 1 must be closed before it is deleted", missions.ErrMissionInProgress),
+			expectedStatus:   http.StatusConflict,
+			expectedBody:     `must be closed before it is deleted`,
 		},
 		{
 			name:           "service error",
-			missionID:      "1",
+			target:         "1",
+			callsService:   true,
 			mockReturnErr:  errors.New("database error"),
-			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `"error":"database error"`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to delete mission"`,
 		},
 	}
 
@@ -264,14 +503,21 @@ func TestDeleteMission(t *testing.T) {
 			r := gin.Default()
 			r.DELETE("/missions/:id", h.DeleteMission)
 
-			mockSvc.On("DeleteMission", mock.AnythingOfType("int64")).Return(tt.mockReturnErr)
+			if tt.callsService {
+				var summary any
+				if tt.mockSummary != nil {
+					summary = tt.mockSummary
+				}
+				mockSvc.On("DeleteMission", mock.AnythingOfType("int64"), tt.expectedUnassign).Return(summary, tt.mockReturnErr)
+			}
 
-			req, _ := http.NewRequest(http.MethodDelete, "/missions/"+tt.missionID, nil)
+			req, _ := http.NewRequest(http.MethodDelete, "/missions/"+tt.target, nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
 		})
 	}
 }
@@ -282,6 +528,8 @@ func TestMarkMissionComplete(t *testing.T) {
 	tests := []struct {
 		name           string
 		missionID      string
+		query          string
+		expectedForce  bool
 		mockReturnErr  error
 		expectedStatus int
 		expectedBody   string
@@ -305,7 +553,22 @@ func TestMarkMissionComplete(t *testing.T) {
 			missionID:      "1",
 			mockReturnErr:  errors.New("database connection error"),
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `"error":"database connection error"`,
+			expectedBody:   `"error":"failed to complete mission"`,
+		},
+		{
+			name:           "open targets",
+			missionID:      "1",
+			mockReturnErr:  fmt.Errorf("%w: 2 target(s) of mission 1 still open", missions.ErrOpenTargets),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission has incomplete targets: 2 target(s) of mission 1 still open"`,
+		},
+		{
+			name:           "forced completion",
+			missionID:      "1",
+			query:          "?force=true",
+			expectedForce:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"message":"mission marked complete"`,
 		},
 	}
 
@@ -318,9 +581,9 @@ func TestMarkMissionComplete(t *testing.T) {
 			r := gin.Default()
 			r.PUT("/missions/:id/complete", h.MarkMissionComplete)
 
-			mockSvc.On("MarkMissionComplete", mock.AnythingOfType("int64")).Return(tt.mockReturnErr)
+			mockSvc.On("MarkMissionComplete", mock.AnythingOfType("int64"), tt.expectedForce).Return(tt.mockReturnErr)
 
-			req, _ := http.NewRequest(http.MethodPut, "/missions/"+tt.missionID+"/complete", nil)
+			req, _ := http.NewRequest(http.MethodPut, "/missions/"+tt.missionID+"/complete"+tt.query, nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
@@ -352,7 +615,7 @@ func TestAddTarget(t *testing.T) {
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `"message":"target added"`,
+			expectedBody:   `"id":7`,
 		},
 		{
 			name:           "invalid JSON body",
@@ -380,9 +643,53 @@ func TestAddTarget(t *testing.T) {
 				Country: "Germany",
 				Notes:   "Service will fail",
 			},
-			mockReturnErr:  errors.New("cannot add target to completed mission"),
+			mockReturnErr:  errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to add target"`,
+		},
+		{
+			name:      "mission full",
+			missionID: "1",
+			body: missions.CreateTarget{
+				Name:    "Target Epsilon",
+				Country: "Spain",
+			},
+			mockReturnErr:  fmt.Errorf("%w, mission 1 already has 3", missions.ErrTargetLimit),
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `"error":"cannot add target to completed mission"`,
+			expectedBody:   `"error":"a mission must have between 1 and 3 targets, mission 1 already has 3"`,
+		},
+		{
+			name:      "mission not found",
+			missionID: "999",
+			body: missions.CreateTarget{
+				Name:    "Target Zeta",
+				Country: "Chile",
+			},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:      "mission complete",
+			missionID: "1",
+			body: missions.CreateTarget{
+				Name:    "Target Eta",
+				Country: "Chile",
+			},
+			mockReturnErr:  fmt.Errorf("%w: cannot add target to completed mission", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is already complete: cannot add target to completed mission"`,
+		},
+		{
+			name:      "cat rank too low",
+			missionID: "1",
+			body: missions.CreateTarget{
+				Name:    "Target Theta",
+				Country: "Chile",
+			},
+			mockReturnErr:  fmt.Errorf("%w: cat 5 is a recruit and may work missions of at most 2 targets, not 3", missions.ErrRankTooLow),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `cat 5 is a recruit`,
 		},
 	}
 
@@ -404,8 +711,11 @@ func TestAddTarget(t *testing.T) {
 				bodyBytes = b
 			}
 
-			if tt.mockReturnErr != nil || tt.expectedStatus == http.StatusCreated {
-				mockSvc.On("AddTarget", mock.AnythingOfType("int64"), mock.Anything).Return(tt.mockReturnErr)
+			if tt.mockReturnErr != nil {
+				mockSvc.On("AddTarget", mock.AnythingOfType("int64"), mock.Anything).Return(nil, tt.mockReturnErr)
+			} else if tt.expectedStatus == http.StatusCreated {
+				mockSvc.On("AddTarget", mock.AnythingOfType("int64"), mock.Anything).
+					Return(&missions.Target{ID: 7, MissionID: 1, Name: "New Target", Country: "France"}, nil)
 			}
 
 			req, _ := http.NewRequest(http.MethodPost, "/missions/"+tt.missionID+"/targets", bytes.NewReader(bodyBytes))
@@ -416,6 +726,9 @@ func TestAddTarget(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.Equal(t, "/missions/targets/7", w.Header().Get("Location"))
+			}
 		})
 	}
 }
@@ -427,6 +740,7 @@ func TestUpdateTarget(t *testing.T) {
 		name           string
 		targetID       string
 		body           any
+		expectedReq    *missions.UpdateTargetRequest
 		mockReturnErr  error
 		expectedStatus int
 		expectedBody   string
@@ -435,23 +749,22 @@ func TestUpdateTarget(t *testing.T) {
 			name:     "success",
 			targetID: "1",
 			body: missions.UpdateTargetRequest{
-				IsComplete: func() *bool { b := true; return &b }(),
-				Notes:      func() *string { s := "Mission accomplished"; return &s }(),
+				IsComplete: utils.Some(true),
+				Notes:      utils.Some("Mission accomplished"),
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `"message":"target updated"`,
+			expectedBody:   `"id":1`,
 		},
 		{
 			name:     "success with partial update",
 			targetID: "2",
 			body: missions.UpdateTargetRequest{
-				IsComplete: func() *bool { b := false; return &b }(),
-				Notes:      nil, // Only updating completion status
+				IsComplete: utils.Some(false), // Only updating completion status
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `"message":"target updated"`,
+			expectedBody:   `"id":1`,
 		},
 		{
 			name:           "invalid JSON body",
@@ -464,12 +777,58 @@ func TestUpdateTarget(t *testing.T) {
 			name:     "service error",
 			targetID: "1",
 			body: missions.UpdateTargetRequest{
-				IsComplete: func() *bool { b := true; return &b }(),
+				IsComplete: utils.Some(true),
+			},
+			mockReturnErr:  errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to update target"`,
+		},
+		{
+			name:     "invalid patch",
+			targetID: "1",
+			body: missions.UpdateTargetRequest{
+				Status: utils.Some(missions.TargetStatus("lost")),
 			},
-			mockReturnErr:  errors.New("target not found"),
+			mockReturnErr:  fmt.Errorf("%w: unknown target status %q", missions.ErrInvalidPatch, "lost"),
 			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid patch: unknown target status \"lost\""`,
+		},
+		{
+			name:     "target not found by typed error",
+			targetID: "999",
+			body: missions.UpdateTargetRequest{
+				IsComplete: utils.Some(true),
+			},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrTargetNotFound),
+			expectedStatus: http.StatusNotFound,
 			expectedBody:   `"error":"target not found"`,
 		},
+		{
+			name:     "notes of completed target are frozen",
+			targetID: "1",
+			body: missions.UpdateTargetRequest{
+				Notes: utils.Some("rewritten"),
+			},
+			mockReturnErr:  fmt.Errorf("%w: notes of completed target 1 cannot be changed", missions.ErrTargetFrozen),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"target is frozen: notes of completed target 1 cannot be changed"`,
+		},
+		{
+			name:           "null notes clears notes and leaves is_complete untouched",
+			targetID:       "1",
+			body:           `{"notes": null}`,
+			expectedReq:    &missions.UpdateTargetRequest{Notes: utils.Null[string]()},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"notes":""`,
+		},
+		{
+			name:           "empty patch leaves every field untouched",
+			targetID:       "1",
+			body:           `{}`,
+			expectedReq:    &missions.UpdateTargetRequest{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":1`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -490,8 +849,15 @@ func TestUpdateTarget(t *testing.T) {
 				bodyBytes = b
 			}
 
-			if tt.mockReturnErr != nil || tt.expectedStatus == http.StatusOK {
-				mockSvc.On("UpdateTarget", mock.AnythingOfType("int64"), mock.Anything).Return(tt.mockReturnErr)
+			var matchReq any = mock.Anything
+			if tt.expectedReq != nil {
+				matchReq = *tt.expectedReq
+			}
+			if tt.mockReturnErr != nil {
+				mockSvc.On("UpdateTarget", mock.AnythingOfType("int64"), matchReq).Return(nil, tt.mockReturnErr)
+			} else if tt.expectedStatus == http.StatusOK {
+				mockSvc.On("UpdateTarget", mock.AnythingOfType("int64"), matchReq).
+					Return(&missions.Target{ID: 1, MissionID: 1, Name: "Agent Smith", Country: "Russia"}, nil)
 			}
 
 			req, _ := http.NewRequest(http.MethodPut, "/missions/1/targets/"+tt.targetID, bytes.NewReader(bodyBytes))
@@ -502,6 +868,7 @@ func TestUpdateTarget(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
 		})
 	}
 }
@@ -526,16 +893,30 @@ func TestDeleteTarget(t *testing.T) {
 		{
 			name:           "target not found",
 			targetID:       "999",
-			mockReturnErr:  errors.New("target not found"),
-			expectedStatus: http.StatusBadRequest,
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrTargetNotFound),
+			expectedStatus: http.StatusNotFound,
 			expectedBody:   `"error":"target not found"`,
 		},
+		{
+			name:           "completed target",
+			targetID:       "1",
+			mockReturnErr:  fmt.Errorf("%w: completed target 1 cannot be deleted", missions.ErrTargetFrozen),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `cannot be deleted`,
+		},
+		{
+			name:           "last target",
+			targetID:       "1",
+			mockReturnErr:  fmt.Errorf("%w, mission 1 would be left without targets", missions.ErrTargetLimit),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `left without targets`,
+		},
 		{
 			name:           "service error",
 			targetID:       "1",
 			mockReturnErr:  errors.New("database error"),
-			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `"error":"database error"`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to delete target"`,
 		},
 	}
 
@@ -563,79 +944,161 @@ func TestDeleteTarget(t *testing.T) {
 func TestGetAllMissions(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	incomplete := false
 	tests := []struct {
-		name               string
-		mockReturnMissions []missions.Mission
-		mockReturnErr      error
-		expectedStatus     int
-		expectedBody       string
+		name           string
+		query          string
+		callsService   bool
+		expectedQuery  missions.ListMissionsQuery
+		mockReturnPage *missions.MissionPage
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+		expectedLink   string
 	}{
 		{
-			name: "success with missions",
-			mockReturnMissions: []missions.Mission{
-				{
-					ID:         1,
-					CatID:      func() *int64 { id := int64(5); return &id }(),
-					Name:       "Operation Alpha",
-					IsComplete: false,
-					Targets: []missions.Target{
-						{ID: 1, MissionID: 1, Name: "Target A", Country: "Russia", Notes: "High priority", IsComplete: false},
+			name:         "success with missions",
+			callsService: true,
+			mockReturnPage: &missions.MissionPage{
+				Missions: []missions.Mission{
+					{
+						ID:         1,
+						CatID:      func() *int64 { id := int64(5); return &id }(),
+						Name:       "Operation Alpha",
+						IsComplete: false,
+						Targets: []missions.Target{
+							{ID: 1, MissionID: 1, Name: "Target A", Country: "Russia", Notes: "High priority", IsComplete: false},
+						},
 					},
-				},
-				{
-					ID:         2,
-					CatID:      nil,
-					Name:       "Operation Beta",
-					IsComplete: true,
-					Targets: []missions.Target{
-						{ID: 2, MissionID: 2, Name: "Target B", Country: "China", Notes: "Completed", IsComplete: true},
+					{
+						ID:         2,
+						CatID:      nil,
+						Name:       "Operation Beta",
+						IsComplete: true,
+						Targets: []missions.Target{
+							{ID: 2, MissionID: 2, Name: "Target B", Country: "China", Notes: "Completed", IsComplete: true},
+						},
 					},
 				},
+				Total: 2,
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"name":"Operation Alpha"`,
 		},
 		{
-			name:               "success with empty list",
-			mockReturnMissions: []missions.Mission{},
-			mockReturnErr:      nil,
-			expectedStatus:     http.StatusOK,
-			expectedBody:       `[]`,
+			name:           "success with empty list",
+			callsService:   true,
+			mockReturnPage: &missions.MissionPage{Missions: []missions.Mission{}},
+			mockReturnErr:  nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
 		},
 		{
-			name:               "service error",
-			mockReturnMissions: []missions.Mission{},
-			mockReturnErr:      errors.New("database connection error"),
-			expectedStatus:     http.StatusInternalServerError,
-			expectedBody:       `"error":"failed to fetch missions"`,
+			name:         "filters, targets and next page",
+			query:        "?is_complete=false&target_country=Russia&include=targets&limit=1",
+			callsService: true,
+			expectedQuery: missions.ListMissionsQuery{
+				IsComplete: &incomplete, TargetCountry: "Russia", Include: "targets", Limit: 1,
+			},
+			mockReturnPage: &missions.MissionPage{
+				Missions: []missions.Mission{{
+					ID:      1,
+					Name:    "Operation Alpha",
+					Targets: []missions.Target{{ID: 1, MissionID: 1, Name: "Target A", Country: "Russia"}},
+				}},
+				Total:      3,
+				NextCursor: "abc",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"country":"Russia"`,
+			expectedLink:   `</missions?cursor=abc&include=targets&is_complete=false&limit=1&target_country=Russia>; rel="next"`,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			mockSvc := new(mockService)
-			h := missions.NewHandler(mockSvc)
-
-			// prepare Gin router
-			r := gin.Default()
-			r.GET("/missions", h.GetAllMissions)
-
-			mockSvc.On("GetAllMissions").Return(tt.mockReturnMissions, tt.mockReturnErr)
-
-			req, _ := http.NewRequest(http.MethodGet, "/missions", nil)
-			w := httptest.NewRecorder()
-			r.ServeHTTP(w, req)
-
-			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.Contains(t, w.Body.String(), tt.expectedBody)
-		})
-	}
-}
-
-func TestGetMissionByID(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
+		{
+			name:           "unknown include",
+			query:          "?include=cats",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:         "priority and deadline filters sorted by priority",
+			query:        "?priority=high&priority=critical&deadline_after=2024-05-01&deadline_before=2024-06-30&overdue=true&sort=-priority",
+			callsService: true,
+			expectedQuery: missions.ListMissionsQuery{
+				Priority: []string{"high", "critical"}, DeadlineAfter: "2024-05-01", DeadlineBefore: "2024-06-30",
+				Overdue: func() *bool { b := true; return &b }(), Sort: "-priority",
+			},
+			mockReturnPage: &missions.MissionPage{
+				Missions: []missions.Mission{{
+					ID:       1,
+					Name:     "Operation Alpha",
+					Priority: missions.PriorityCritical,
+					Deadline: func() *string { d := "2024-05-20"; return &d }(),
+					Overdue:  true,
+				}},
+				Total: 1,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"deadline":"2024-05-20","overdue":true`,
+		},
+		{
+			name:           "unknown sort",
+			query:          "?sort=name",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "unknown priority filter",
+			query:          "?priority=urgent",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "contradictory filters",
+			query:          "?unassigned=true&cat_id=5",
+			callsService:   true,
+			expectedQuery:  missions.ListMissionsQuery{Unassigned: true, CatID: func() *int64 { id := int64(5); return &id }()},
+			mockReturnErr:  fmt.Errorf("%w: unassigned cannot be combined with cat_id", missions.ErrInvalidQuery),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid query: unassigned cannot be combined with cat_id"`,
+		},
+		{
+			name:           "service error",
+			callsService:   true,
+			mockReturnErr:  errors.New("database connection error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch missions"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			// prepare Gin router
+			r := gin.Default()
+			r.GET("/missions", h.GetAllMissions)
+
+			if tt.callsService {
+				mockSvc.On("GetAllMissions", tt.expectedQuery).Return(tt.mockReturnPage, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedLink, w.Header().Get("Link"))
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMissionByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
 	tests := []struct {
 		name              string
 		missionID         string
@@ -756,6 +1219,66 @@ func TestAssignCat(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"error":"failed to assign cat"`,
 		},
+		{
+			name:      "mission not found by typed error",
+			missionID: "999",
+			body: missions.AssignCatRequest{
+				CatID: 5,
+			},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:      "cat not found",
+			missionID: "1",
+			body: missions.AssignCatRequest{
+				CatID: 999,
+			},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+		{
+			name:      "mission already complete",
+			missionID: "1",
+			body: missions.AssignCatRequest{
+				CatID: 5,
+			},
+			mockReturnErr:  fmt.Errorf("%w: mission 1", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is already complete: mission 1"`,
+		},
+		{
+			name:      "cat already on a mission",
+			missionID: "1",
+			body: missions.AssignCatRequest{
+				CatID: 5,
+			},
+			mockReturnErr:  fmt.Errorf("%w: cat 5 is on mission 3", missions.ErrCatBusy),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"cat already has an active mission: cat 5 is on mission 3"`,
+		},
+		{
+			name:      "mission assigned to another cat",
+			missionID: "1",
+			body: missions.AssignCatRequest{
+				CatID: 5,
+			},
+			mockReturnErr:  fmt.Errorf("%w: mission 1 is assigned to cat 2, reassign it instead", missions.ErrMissionAssigned),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `reassign it instead`,
+		},
+		{
+			name:      "cat rank too low",
+			missionID: "1",
+			body: missions.AssignCatRequest{
+				CatID: 5,
+			},
+			mockReturnErr:  fmt.Errorf("%w: cat 5 is a recruit and may work missions of at most 2 targets, not 3", missions.ErrRankTooLow),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"cat rank too low: cat 5 is a recruit and may work missions of at most 2 targets, not 3"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -791,3 +1314,886 @@ func TestAssignCat(t *testing.T) {
 		})
 	}
 }
+
+func TestReassignCat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	catID := int64(7)
+	tests := []struct {
+		name           string
+		body           string
+		callsService   bool
+		mockMission    *missions.Mission
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockMission:    &missions.Mission{ID: 1, CatID: &catID, Status: missions.MissionActive},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"cat_id":7`,
+		},
+		{
+			name:           "missing cat",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "mission not found",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 1", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "cat not found",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 7", missions.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 7"`,
+		},
+		{
+			name:           "mission unassigned",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: mission 1, assign a cat instead", missions.ErrMissionUnassigned),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `assign a cat instead`,
+		},
+		{
+			name:           "cat busy",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: cat 7 is on mission 3", missions.ErrCatBusy),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `cat 7 is on mission 3`,
+		},
+		{
+			name:           "mission complete",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: mission 1", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `mission is already complete`,
+		},
+		{
+			name:           "service error",
+			body:           `{"cat_id": 7}`,
+			callsService:   true,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to reassign cat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/missions/:id/reassign", h.ReassignCat)
+
+			if tt.callsService {
+				var mission any
+				if tt.mockMission != nil {
+					mission = tt.mockMission
+				}
+				mockSvc.On("ReassignCat", int64(1), int64(7)).Return(mission, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/missions/1/reassign", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnassignCat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockMission    *missions.Mission
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			mockMission:    &missions.Mission{ID: 1, Status: missions.MissionOnHold},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"on_hold"`,
+		},
+		{
+			name:           "mission not found",
+			mockReturnErr:  fmt.Errorf("%w with id 1", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "mission complete",
+			mockReturnErr:  fmt.Errorf("%w: mission 1", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is already complete: mission 1"`,
+		},
+		{
+			name:           "mission aborted",
+			mockReturnErr:  fmt.Errorf("%w: mission 1", missions.ErrMissionClosed),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is closed: mission 1"`,
+		},
+		{
+			name:           "service error",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to unassign cat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.DELETE("/missions/:id/assign", h.UnassignCat)
+
+			var mission any
+			if tt.mockMission != nil {
+				mission = tt.mockMission
+			}
+			mockSvc.On("UnassignCat", int64(1)).Return(mission, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/missions/1/assign", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		targetID       string
+		mockTarget     *missions.Target
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			targetID:       "1",
+			mockTarget:     &missions.Target{ID: 1, MissionID: 2, Name: "Agent Smith", Country: "Russia"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"mission_id":2`,
+		},
+		{
+			name:           "not found",
+			targetID:       "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrTargetNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"target not found"`,
+		},
+		{
+			name:           "service error",
+			targetID:       "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch target"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/missions/targets/:targetId", h.GetTarget)
+
+			mockSvc.On("GetTarget", mock.AnythingOfType("int64")).Return(tt.mockTarget, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions/targets/"+tt.targetID, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetMissionTargets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		missionID      string
+		mockTargets    []missions.Target
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "success",
+			missionID: "1",
+			mockTargets: []missions.Target{
+				{ID: 1, MissionID: 1, Name: "Agent Smith", Country: "Russia"},
+				{ID: 2, MissionID: 1, Name: "Agent Jones", Country: "France"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Agent Jones"`,
+		},
+		{
+			name:           "mission without targets",
+			missionID:      "1",
+			mockTargets:    []missions.Target{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "mission not found",
+			missionID:      "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/missions/:id/targets", h.GetMissionTargets)
+
+			var targets any
+			if tt.mockTargets != nil {
+				targets = tt.mockTargets
+			}
+			mockSvc.On("GetMissionTargets", mock.AnythingOfType("int64")).Return(targets, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions/"+tt.missionID+"/targets", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestListTargets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	isComplete := false
+	deletedAt := time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		query           string
+		expectedQuery   *missions.ListTargetsQuery
+		mockPage        *missions.TargetPage
+		mockReturnErr   error
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:          "filters by country and status",
+			query:         "?country=russia&is_complete=false",
+			expectedQuery: &missions.ListTargetsQuery{Country: "russia", IsComplete: &isComplete},
+			mockPage: &missions.TargetPage{
+				Targets: []missions.Target{{ID: 1, MissionID: 1, Name: "Agent Smith", Country: "Russia"}},
+				Total:   1,
+			},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `"country":"Russia"`,
+			expectedHeaders: map[string]string{"X-Total-Count": "1"},
+		},
+		{
+			name:          "next page",
+			query:         "?limit=1",
+			expectedQuery: &missions.ListTargetsQuery{Limit: 1},
+			mockPage: &missions.TargetPage{
+				Targets:    []missions.Target{{ID: 1}},
+				Total:      2,
+				NextCursor: "abc",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":1`,
+			expectedHeaders: map[string]string{
+				"X-Total-Count": "2",
+				"X-Next-Cursor": "abc",
+				"Link":          `</missions/targets?cursor=abc&limit=1>; rel="next"`,
+			},
+		},
+		{
+			name:          "include deleted",
+			query:         "?include_deleted=true",
+			expectedQuery: &missions.ListTargetsQuery{IncludeDeleted: true},
+			mockPage: &missions.TargetPage{
+				Targets: []missions.Target{{ID: 4, MissionID: 1, DeletedAt: &deletedAt}},
+				Total:   1,
+			},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `"deleted_at":"2024-05-03T09:00:00Z"`,
+			expectedHeaders: map[string]string{"X-Total-Count": "1"},
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=500",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=bogus",
+			mockReturnErr:  utils.ErrInvalidCursor,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid cursor"`,
+		},
+		{
+			name:           "service error",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch targets"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/missions/targets", h.ListTargets)
+
+			var matchQuery any = mock.Anything
+			if tt.expectedQuery != nil {
+				matchQuery = *tt.expectedQuery
+			}
+			if tt.mockPage != nil || tt.mockReturnErr != nil {
+				var page any
+				if tt.mockPage != nil {
+					page = tt.mockPage
+				}
+				mockSvc.On("ListTargets", matchQuery).Return(page, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions/targets"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMoveTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		targetID       string
+		body           any
+		mockTarget     *missions.Target
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockTarget:     &missions.Target{ID: 1, MissionID: 2, Name: "Agent Smith", Country: "Russia"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"mission_id":2`,
+		},
+		{
+			name:           "missing mission id",
+			targetID:       "1",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "target not found",
+			targetID:       "999",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrTargetNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"target not found"`,
+		},
+		{
+			name:           "destination not found",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 999},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "completed target",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockReturnErr:  fmt.Errorf("%w: completed target 1 cannot be moved", missions.ErrTargetFrozen),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"target is frozen: completed target 1 cannot be moved"`,
+		},
+		{
+			name:           "completed destination",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockReturnErr:  fmt.Errorf("%w: cannot move target to completed mission 2", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is already complete: cannot move target to completed mission 2"`,
+		},
+		{
+			name:           "destination full",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockReturnErr:  fmt.Errorf("%w, mission 2 already has 3", missions.ErrTargetLimit),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"a mission must have between 1 and 3 targets, mission 2 already has 3"`,
+		},
+		{
+			name:           "service error",
+			targetID:       "1",
+			body:           missions.MoveTargetRequest{MissionID: 2},
+			mockReturnErr:  errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to move target"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/missions/targets/:targetId/move", h.MoveTarget)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				b, _ := json.Marshal(v)
+				bodyBytes = b
+			}
+
+			if tt.mockTarget != nil || tt.mockReturnErr != nil {
+				mockSvc.On("MoveTarget", mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return(tt.mockTarget, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/missions/targets/"+tt.targetID+"/move", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestTransitionMission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		missionID      string
+		body           any
+		mockMission    *missions.Mission
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			missionID:      "1",
+			body:           missions.TransitionRequest{Status: missions.MissionActive},
+			mockMission:    &missions.Mission{ID: 1, Name: "Operation Stealth", Status: missions.MissionActive},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"active"`,
+		},
+		{
+			name:           "unknown status",
+			missionID:      "1",
+			body:           `{"status": "paused"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "missing status",
+			missionID:      "1",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "mission not found",
+			missionID:      "999",
+			body:           missions.TransitionRequest{Status: missions.MissionActive},
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "transition not allowed",
+			missionID:      "1",
+			body:           missions.TransitionRequest{Status: missions.MissionCompleted},
+			mockReturnErr:  fmt.Errorf("%w: mission 1 cannot go from planned to completed", missions.ErrInvalidTransition),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"invalid status transition: mission 1 cannot go from planned to completed"`,
+		},
+		{
+			name:           "closed mission",
+			missionID:      "1",
+			body:           missions.TransitionRequest{Status: missions.MissionActive},
+			mockReturnErr:  fmt.Errorf("%w: cannot move aborted mission 1 to active", missions.ErrMissionClosed),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"error":"mission is closed: cannot move aborted mission 1 to active"`,
+		},
+		{
+			name:           "service error",
+			missionID:      "1",
+			body:           missions.TransitionRequest{Status: missions.MissionOnHold},
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to change mission status"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/missions/:id/transitions", h.TransitionMission)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				b, _ := json.Marshal(v)
+				bodyBytes = b
+			}
+
+			if tt.mockMission != nil || tt.mockReturnErr != nil {
+				mockSvc.On("TransitionMission", mock.AnythingOfType("int64"), mock.Anything).Return(tt.mockMission, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/missions/"+tt.missionID+"/transitions", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetMissionTransitions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		missionID      string
+		mockChanges    []missions.StatusChange
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "success",
+			missionID: "1",
+			mockChanges: []missions.StatusChange{
+				{To: missions.MissionPlanned},
+				{From: missions.MissionPlanned, To: missions.MissionActive},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"from":"planned","to":"active"`,
+		},
+		{
+			name:           "mission not found",
+			missionID:      "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "service error",
+			missionID:      "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch status history"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/missions/:id/transitions", h.GetMissionTransitions)
+
+			var changes any
+			if tt.mockChanges != nil {
+				changes = tt.mockChanges
+			}
+			mockSvc.On("GetMissionTransitions", mock.AnythingOfType("int64")).Return(changes, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions/"+tt.missionID+"/transitions", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestGetMissionAssignments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	released := time.Date(2024, 5, 10, 17, 0, 0, 0, time.UTC)
+	previous, current := int64(5), int64(6)
+
+	tests := []struct {
+		name            string
+		missionID       string
+		mockAssignments []missions.Assignment
+		mockReturnErr   error
+		expectedStatus  int
+		expectedBody    string
+	}{
+		{
+			name:      "success",
+			missionID: "1",
+			mockAssignments: []missions.Assignment{
+				{CatID: &previous, ReleasedAt: &released, Reason: missions.ReleaseReassigned},
+				{CatID: &current},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"released_at":"2024-05-10T17:00:00Z","reason":"reassigned"},{"cat_id":6,"assigned_at":"0001-01-01T00:00:00Z"}]`,
+		},
+		{
+			name:      "purged cat",
+			missionID: "1",
+			mockAssignments: []missions.Assignment{
+				{ReleasedAt: &released, Reason: missions.ReleaseCatDeleted},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"cat_id":null,`,
+		},
+		{
+			name:           "mission not found",
+			missionID:      "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"mission not found"`,
+		},
+		{
+			name:           "service error",
+			missionID:      "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to fetch assignment history"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/missions/:id/assignments", h.GetMissionAssignments)
+
+			var assignments any
+			if tt.mockAssignments != nil {
+				assignments = tt.mockAssignments
+			}
+			mockSvc.On("GetMissionAssignments", mock.AnythingOfType("int64")).Return(assignments, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/missions/"+tt.missionID+"/assignments", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestRestoreMission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		missionID      string
+		mockMission    *missions.Mission
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "success",
+			missionID: "1",
+			mockMission: &missions.Mission{
+				ID:      1,
+				Name:    "Operation Stealth",
+				Status:  missions.MissionPlanned,
+				Targets: []missions.Target{{ID: 1, MissionID: 1, Name: "Agent Smith"}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Agent Smith"`,
+		},
+		{
+			name:           "no deleted mission",
+			missionID:      "2",
+			mockReturnErr:  fmt.Errorf("%w: no deleted mission with id 2", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `no deleted mission with id 2`,
+		},
+		{
+			name:           "service error",
+			missionID:      "1",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to restore mission"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/missions/:id/restore", h.RestoreMission)
+
+			var mission any
+			if tt.mockMission != nil {
+				mission = tt.mockMission
+			}
+			mockSvc.On("RestoreMission", mock.AnythingOfType("int64")).Return(mission, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodPost, "/missions/"+tt.missionID+"/restore", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestRestoreTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		targetID       string
+		mockTarget     *missions.Target
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			targetID:       "4",
+			mockTarget:     &missions.Target{ID: 4, MissionID: 1, Name: "Agent Smith", Status: missions.TargetPending},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":4`,
+		},
+		{
+			name:           "no deleted target",
+			targetID:       "5",
+			mockReturnErr:  fmt.Errorf("%w: no deleted target with id 5", missions.ErrTargetNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `no deleted target with id 5`,
+		},
+		{
+			name:           "mission deleted",
+			targetID:       "4",
+			mockReturnErr:  fmt.Errorf("%w: mission 1 of target 4 is deleted, restore it first", missions.ErrMissionNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `restore it first`,
+		},
+		{
+			name:           "mission full",
+			targetID:       "4",
+			mockReturnErr:  fmt.Errorf("%w, mission 1 already has 3", missions.ErrTargetLimit),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `mission 1 already has 3`,
+		},
+		{
+			name:           "mission closed",
+			targetID:       "4",
+			mockReturnErr:  fmt.Errorf("%w: cannot restore target of completed mission 1", missions.ErrMissionComplete),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `cannot restore target`,
+		},
+		{
+			name:           "service error",
+			targetID:       "4",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to restore target"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := missions.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/missions/targets/:targetId/restore", h.RestoreTarget)
+
+			var target any
+			if tt.mockTarget != nil {
+				target = tt.mockTarget
+			}
+			mockSvc.On("RestoreTarget", mock.AnythingOfType("int64")).Return(target, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodPost, "/missions/targets/"+tt.targetID+"/restore", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -1,30 +1,71 @@
 package missions
 
-// Mission represents a spy mission
+import (
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+// Priority is the urgency of a mission, from low to critical
+type Priority string
+
+const (
+	PriorityLow      Priority = "low"
+	PriorityMedium   Priority = "medium"
+	PriorityHigh     Priority = "high"
+	PriorityCritical Priority = "critical"
+)
+
+// priorities lists the priorities from lowest to highest
+var priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical}
+
+// Mission represents a spy mission. IsComplete mirrors Status for clients
+// that predate the status field, Overdue is set for open missions past their
+// deadline and DeletedAt is set for soft-deleted missions.
 type Mission struct {
-	ID         int64    `json:"id" example:"1"`
-	CatID      *int64   `json:"cat_id,omitempty" example:"5"`
-	Name       string   `json:"name" example:"Operation Stealth"`
-	IsComplete bool     `json:"is_complete" example:"false"`
-	Targets    []Target `json:"targets,omitempty"`
+	ID              int64         `json:"id" example:"1"`
+	CatID           *int64        `json:"cat_id,omitempty" example:"5"`
+	Name            string        `json:"name" example:"Operation Stealth"`
+	Description     string        `json:"description" example:"Recover the stolen plans from the embassy"`
+	Priority        Priority      `json:"priority" enums:"low,medium,high,critical" example:"high"`
+	StartDate       *string       `json:"start_date,omitempty" example:"2024-05-01"`
+	Deadline        *string       `json:"deadline,omitempty" example:"2024-05-31"`
+	Overdue         bool          `json:"overdue" example:"false"`
+	Status          MissionStatus `json:"status" enums:"planned,active,on_hold,completed,aborted,failed" example:"active"`
+	IsComplete      bool          `json:"is_complete" example:"false"`
+	CreatedAt       time.Time     `json:"created_at" example:"2024-05-01T12:00:00Z"`
+	StatusChangedAt time.Time     `json:"status_changed_at" example:"2024-05-02T08:30:00Z"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty" example:"2024-05-03T09:00:00Z"`
+	Targets         []Target      `json:"targets,omitempty"`
 }
 
-// Target represents a mission target
+// Target represents a mission target. IsComplete mirrors Status for clients
+// that predate the status field, and DeletedAt is set for soft-deleted
+// targets.
 type Target struct {
-	ID         int64  `json:"id" example:"1"`
-	MissionID  int64  `json:"mission_id" example:"1"`
-	Name       string `json:"name" example:"Agent Smith"`
-	Country    string `json:"country" example:"Russia"`
-	Notes      string `json:"notes" example:"High priority target"`
-	IsComplete bool   `json:"is_complete" example:"false"`
+	ID              int64        `json:"id" example:"1"`
+	MissionID       int64        `json:"mission_id" example:"1"`
+	Name            string       `json:"name" example:"Agent Smith"`
+	Country         string       `json:"country" example:"Russia"`
+	Notes           string       `json:"notes" example:"High priority target"`
+	Status          TargetStatus `json:"status" enums:"pending,completed,failed" example:"pending"`
+	IsComplete      bool         `json:"is_complete" example:"false"`
+	StatusChangedAt time.Time    `json:"status_changed_at" example:"2024-05-02T08:30:00Z"`
+	DeletedAt       *time.Time   `json:"deleted_at,omitempty" example:"2024-05-03T09:00:00Z"`
 }
 
-// CreateMissionRequest represents the request to create a new mission
+// CreateMissionRequest represents the request to create a new mission. The
+// priority defaults to medium, and the deadline may not precede the start
+// date or, unless the mission is created complete, today.
 type CreateMissionRequest struct {
-	CatID      *int64         `json:"cat_id" example:"5"`
-	Name       string         `json:"name" binding:"required" example:"Operation Stealth"`
-	Targets    []CreateTarget `json:"targets" binding:"required"`
-	IsComplete bool           `json:"is_complete" example:"false"`
+	CatID       *int64         `json:"cat_id" example:"5"`
+	Name        string         `json:"name" binding:"required" example:"Operation Stealth"`
+	Description string         `json:"description" binding:"max=2000" example:"Recover the stolen plans from the embassy"`
+	Priority    Priority       `json:"priority" binding:"omitempty,oneof=low medium high critical" enums:"low,medium,high,critical" example:"high"`
+	StartDate   string         `json:"start_date" binding:"omitempty,datetime=2006-01-02" example:"2024-05-01"`
+	Deadline    string         `json:"deadline" binding:"omitempty,datetime=2006-01-02" example:"2024-05-31"`
+	Targets     []CreateTarget `json:"targets" binding:"required,min=1,max=3,dive"`
+	IsComplete  bool           `json:"is_complete" example:"false"`
 }
 
 // CreateTarget represents the target data when creating a mission
@@ -40,10 +81,25 @@ type UpdateMissionRequest struct {
 	IsComplete *bool `json:"is_complete" example:"true"`
 }
 
-// UpdateTargetRequest represents the request to update a target
+// UpdateTargetRequest is a JSON Merge Patch of a target: absent fields are
+// left untouched and a null notes field clears the notes. is_complete=true
+// is shorthand for status=completed.
 type UpdateTargetRequest struct {
-	IsComplete *bool   `json:"is_complete" example:"true"`
-	Notes      *string `json:"notes" example:"Mission accomplished"`
+	Status     utils.Optional[TargetStatus] `json:"status,omitzero" swaggertype:"string" enums:"pending,completed,failed" example:"completed"`
+	IsComplete utils.Optional[bool]         `json:"is_complete,omitzero" swaggertype:"boolean" example:"true"`
+	Notes      utils.Optional[string]       `json:"notes,omitzero" swaggertype:"string" example:"Mission accomplished"`
+}
+
+// TargetPatch holds the target columns to change. A nil Status leaves the
+// status untouched.
+type TargetPatch struct {
+	Notes  utils.Optional[string]
+	Status *TargetStatus
+}
+
+// TransitionRequest represents the request to move a mission to another status
+type TransitionRequest struct {
+	Status MissionStatus `json:"status" binding:"required,oneof=planned active on_hold completed aborted failed" enums:"active,on_hold,completed,aborted,failed" example:"active"`
 }
 
 // CreateTargetRequest represents the request to create a target
@@ -58,3 +114,75 @@ type CreateTargetRequest struct {
 type AssignCatRequest struct {
 	CatID int64 `json:"cat_id" binding:"required" example:"5"`
 }
+
+// ListMissionsQuery represents the filters, sort order and page of a mission
+// listing. Priority matches any of the given priorities, and missions
+// without a deadline sort as if it were infinitely far off.
+type ListMissionsQuery struct {
+	Status         MissionStatus `form:"status" binding:"omitempty,oneof=planned active on_hold completed aborted failed" example:"active"`
+	IsComplete     *bool         `form:"is_complete" example:"false"`
+	CatID          *int64        `form:"cat_id" example:"5"`
+	Unassigned     bool          `form:"unassigned" example:"true"`
+	TargetCountry  string        `form:"target_country" example:"Russia"`
+	Name           string        `form:"name" example:"Stealth"`
+	Priority       []string      `form:"priority" binding:"omitempty,dive,oneof=low medium high critical" example:"high"`
+	DeadlineAfter  string        `form:"deadline_after" binding:"omitempty,datetime=2006-01-02" example:"2024-05-01"`
+	DeadlineBefore string        `form:"deadline_before" binding:"omitempty,datetime=2006-01-02" example:"2024-06-30"`
+	Overdue        *bool         `form:"overdue" example:"true"`
+	Sort           string        `form:"sort" binding:"omitempty,oneof=id -id priority -priority deadline -deadline" example:"-priority"`
+	Include        string        `form:"include" binding:"omitempty,oneof=targets" example:"targets"`
+	Limit          int           `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Cursor         string        `form:"cursor"`
+	IncludeDeleted bool          `form:"include_deleted" example:"true"`
+}
+
+// Assignment is a period during which a cat worked a mission. ReleasedAt
+// and Reason are unset while the cat is still assigned, and CatID is unset
+// once the cat has been purged.
+type Assignment struct {
+	CatID      *int64        `json:"cat_id" example:"5"`
+	AssignedAt time.Time     `json:"assigned_at" example:"2024-05-01T12:00:00Z"`
+	ReleasedAt *time.Time    `json:"released_at,omitempty" example:"2024-05-10T17:00:00Z"`
+	Reason     ReleaseReason `json:"reason,omitempty" enums:"unassigned,reassigned,completed,aborted,failed,mission_deleted,cat_deleted" example:"completed"`
+}
+
+// DeletedMission summarizes a deleted mission. UnassignedCatID is the cat
+// that was released to delete the mission, if any.
+type DeletedMission struct {
+	ID              int64         `json:"id" example:"1"`
+	Name            string        `json:"name" example:"Operation Stealth"`
+	Status          MissionStatus `json:"status" enums:"planned,active,on_hold,completed,aborted,failed" example:"planned"`
+	UnassignedCatID *int64        `json:"unassigned_cat_id,omitempty" example:"5"`
+	DeletedTargets  int           `json:"deleted_targets" example:"2"`
+	DeletedAt       time.Time     `json:"deleted_at" example:"2024-05-03T09:00:00Z"`
+}
+
+// MissionPage is one page of a mission listing
+type MissionPage struct {
+	Missions   []Mission
+	Total      int
+	NextCursor string
+}
+
+// MoveTargetRequest represents the request to move a target to another mission
+type MoveTargetRequest struct {
+	MissionID int64 `json:"mission_id" binding:"required" example:"2"`
+}
+
+// ListTargetsQuery represents the filters and page of a target search
+type ListTargetsQuery struct {
+	Country        string       `form:"country" example:"Russia"`
+	Status         TargetStatus `form:"status" binding:"omitempty,oneof=pending completed failed" example:"pending"`
+	IsComplete     *bool        `form:"is_complete" example:"false"`
+	MissionID      *int64       `form:"mission_id" example:"1"`
+	Limit          int          `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Cursor         string       `form:"cursor"`
+	IncludeDeleted bool         `form:"include_deleted" example:"true"`
+}
+
+// TargetPage is one page of a target search
+type TargetPage struct {
+	Targets    []Target
+	Total      int
+	NextCursor string
+}
@@ -2,78 +2,375 @@ package missions
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"spy-cats/internal/database"
+	"spy-cats/internal/utils"
+
+	"github.com/lib/pq"
 )
 
+const missionColumns = `id, cat_id, name, description, priority,
+	to_char(start_date, 'YYYY-MM-DD'), to_char(deadline, 'YYYY-MM-DD'), ` + overdue + `,
+	status, created_at, status_changed_at, deleted_at`
+
+// overdue matches open missions whose deadline has passed, in UTC like the
+// dates of the request
+const overdue = `COALESCE(deadline < (now() AT TIME ZONE 'UTC')::date AND status IN ` + openMissionStatuses + `, false)`
+
+// targetColumns selects a target; cleared notes are NULL in the table
+const targetColumns = `id, mission_id, name, country, COALESCE(notes, ''), status, status_changed_at, deleted_at`
+
+// openMissionStatuses matches missions that are not closed
+const openMissionStatuses = `('planned', 'active', 'on_hold')`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMission(row scanner) (*Mission, error) {
+	var m Mission
+	if err := row.Scan(
+		&m.ID, &m.CatID, &m.Name, &m.Description, &m.Priority, &m.StartDate, &m.Deadline, &m.Overdue,
+		&m.Status, &m.CreatedAt, &m.StatusChangedAt, &m.DeletedAt,
+	); err != nil {
+		return nil, err
+	}
+	m.IsComplete = m.Status == MissionCompleted
+	return &m, nil
+}
+
+func scanTarget(row scanner) (*Target, error) {
+	var t Target
+	if err := row.Scan(&t.ID, &t.MissionID, &t.Name, &t.Country, &t.Notes, &t.Status, &t.StatusChangedAt, &t.DeletedAt); err != nil {
+		return nil, err
+	}
+	t.IsComplete = t.Status == TargetCompleted
+	return &t, nil
+}
+
 type Repository struct {
-	db *sql.DB
+	db database.Conn
 }
 
 func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: database.NewConn(db)}
 }
 
+// InTx runs fn with a repository bound to a single transaction, committing
+// if fn succeeds
+func (r *Repository) InTx(fn func(tx MissionRepository) error) error {
+	return r.db.InTx(func(tx database.Conn) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
+// CreateMission inserts the mission and records its initial status
 func (r *Repository) CreateMission(m Mission) (int64, error) {
-	query := `INSERT INTO missions (cat_id, name, is_complete) VALUES ($1, $2, $3) RETURNING id`
+	query := `INSERT INTO missions (cat_id, name, description, priority, start_date, deadline, status)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 	var id int64
-	err := r.db.QueryRow(query, m.CatID, m.Name, m.IsComplete).Scan(&id)
-	return id, err
+	if err := r.db.QueryRow(
+		query, m.CatID, m.Name, m.Description, m.Priority, m.StartDate, m.Deadline, m.Status,
+	).Scan(&id); err != nil {
+		return 0, err
+	}
+	if _, err := r.db.Exec(
+		`INSERT INTO mission_status_changes (mission_id, to_status, to_cat_id) VALUES ($1, $2, $3)`,
+		id, m.Status, m.CatID,
+	); err != nil {
+		return 0, err
+	}
+	if m.CatID != nil {
+		if _, err := r.db.Exec(`INSERT INTO mission_assignments (mission_id, cat_id) VALUES ($1, $2)`, id, *m.CatID); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
 }
 
 func (r *Repository) CreateTarget(t Target) (int64, error) {
-	query := `INSERT INTO targets (mission_id, name, country, notes, is_complete)
+	query := `INSERT INTO targets (mission_id, name, country, notes, status)
 			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
 	var id int64
-	err := r.db.QueryRow(query, t.MissionID, t.Name, t.Country, t.Notes, t.IsComplete).Scan(&id)
+	err := r.db.QueryRow(query, t.MissionID, t.Name, t.Country, t.Notes, t.Status).Scan(&id)
 	return id, err
 }
 
+// GetMissionByID returns the mission with its targets, or sql.ErrNoRows if
+// it does not exist or was deleted
 func (r *Repository) GetMissionByID(id int64) (*Mission, error) {
-	m := Mission{}
-	err := r.db.QueryRow(`SELECT id, cat_id, name, is_complete FROM missions WHERE id = $1`, id).
-		Scan(&m.ID, &m.CatID, &m.Name, &m.IsComplete)
+	m, err := scanMission(r.db.QueryRow(`SELECT `+missionColumns+` FROM missions WHERE id = $1 AND deleted_at IS NULL`, id))
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := r.db.Query(`SELECT id, mission_id, name, country, notes, is_complete FROM targets WHERE mission_id = $1`, id)
+	if m.Targets, err = r.ListMissionTargets(id); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ListMissionTargets returns the targets of a mission that are not deleted
+// ordered by id
+func (r *Repository) ListMissionTargets(missionID int64) ([]Target, error) {
+	rows, err := r.db.Query(
+		`SELECT `+targetColumns+` FROM targets WHERE mission_id = $1 AND deleted_at IS NULL ORDER BY id`,
+		missionID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	targets := []Target{}
 	for rows.Next() {
-		var t Target
-		if err := rows.Scan(&t.ID, &t.MissionID, &t.Name, &t.Country, &t.Notes, &t.IsComplete); err != nil {
+		t, err := scanTarget(rows)
+		if err != nil {
 			return nil, err
 		}
-		m.Targets = append(m.Targets, t)
+		targets = append(targets, *t)
 	}
+	return targets, rows.Err()
+}
 
-	return &m, nil
+// LockMission loads a mission without its targets and locks the row until
+// the end of the transaction. Deleted missions are not found.
+func (r *Repository) LockMission(id int64) (*Mission, error) {
+	return scanMission(r.db.QueryRow(
+		`SELECT `+missionColumns+` FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+	))
 }
 
-func (r *Repository) DeleteMission(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM missions WHERE id = $1 AND cat_id IS NULL`, id)
+// LockCat locks the cat row until the end of the transaction, serializing
+// concurrent assignments of the same cat. It reports whether the cat exists
+// and is not deleted.
+func (r *Repository) LockCat(catID int64) (bool, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT id FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, catID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ActiveMissionID returns the id of a mission that is not closed, other than
+// exceptMissionID assigned to the cat, or nil if there is none
+func (r *Repository) ActiveMissionID(catID, exceptMissionID int64) (*int64, error) {
+	var id int64
+	err := r.db.QueryRow(
+		`SELECT id FROM missions
+		 WHERE cat_id = $1 AND status IN `+openMissionStatuses+` AND id <> $2 AND deleted_at IS NULL
+		 ORDER BY id LIMIT 1`,
+		catID, exceptMissionID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// CatRecord returns the years of experience of the cat and the number of
+// missions it completed, as counted by scoring in cat_stats
+func (r *Repository) CatRecord(catID int64) (years, completed int, err error) {
+	err = r.db.QueryRow(
+		`SELECT c.years_of_experience, COALESCE(s.missions_completed, 0)
+		 FROM cats c LEFT JOIN cat_stats s ON s.cat_id = c.id
+		 WHERE c.id=$1`,
+		catID,
+	).Scan(&years, &completed)
+	return years, completed, err
+}
+
+// CountTargets counts the targets of a mission that are not deleted
+func (r *Repository) CountTargets(missionID int64) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL`, missionID).Scan(&n)
+	return n, err
+}
+
+// DeleteMission soft deletes an unassigned mission along with its targets,
+// stamping both with the same time so a restore can tell them apart from
+// targets deleted earlier. It returns the deletion time, or sql.ErrNoRows
+// if there is no unassigned mission with the id.
+func (r *Repository) DeleteMission(id int64) (time.Time, error) {
+	var deletedAt time.Time
+	err := r.db.QueryRow(
+		`UPDATE missions SET deleted_at = now()
+		 WHERE id = $1 AND cat_id IS NULL AND deleted_at IS NULL
+		 RETURNING deleted_at`,
+		id,
+	).Scan(&deletedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, err = r.db.Exec(
+		`UPDATE targets SET deleted_at = $1 WHERE mission_id = $2 AND deleted_at IS NULL`,
+		deletedAt, id,
+	)
+	return deletedAt, err
+}
+
+// RestoreMission undeletes the mission and the targets deleted with it. It
+// returns sql.ErrNoRows if there is no deleted mission with the id.
+func (r *Repository) RestoreMission(id int64) error {
+	var deletedAt time.Time
+	err := r.db.QueryRow(
+		`SELECT deleted_at FROM missions WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id,
+	).Scan(&deletedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(
+		`UPDATE targets SET deleted_at = NULL WHERE mission_id = $1 AND deleted_at = $2`, id, deletedAt,
+	); err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`UPDATE missions SET deleted_at = NULL WHERE id = $1`, id)
 	return err
 }
 
-func (r *Repository) MarkMissionComplete(id int64) error {
-	res, err := r.db.Exec(`UPDATE missions SET is_complete = TRUE WHERE id = $1`, id)
+// SetMissionStatus moves the mission from one status to another and records
+// the change. It returns sql.ErrNoRows if the mission is not in status from.
+func (r *Repository) SetMissionStatus(id int64, from, to MissionStatus) error {
+	res, err := r.db.Exec(
+		`UPDATE missions SET status = $1, status_changed_at = now() WHERE id = $2 AND status = $3`,
+		to, id, from,
+	)
 	if err != nil {
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	_, err = r.db.Exec(
+		`INSERT INTO mission_status_changes (mission_id, from_status, to_status) VALUES ($1, $2, $3)`,
+		id, from, to,
+	)
+	return err
 }
 
-func (r *Repository) UpdateTarget(t Target) error {
-	query := `UPDATE targets 
-	          SET notes = COALESCE($1, notes), is_complete = COALESCE($2, is_complete)
-	          WHERE id = $3`
-	res, err := r.db.Exec(query, t.Notes, t.IsComplete, t.ID)
+// ListStatusChanges returns the status history of a mission, oldest first
+func (r *Repository) ListStatusChanges(missionID int64) ([]StatusChange, error) {
+	rows, err := r.db.Query(
+		`SELECT COALESCE(from_status, ''), to_status, from_cat_id, to_cat_id, changed_at FROM mission_status_changes
+		 WHERE mission_id = $1 ORDER BY changed_at, id`,
+		missionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []StatusChange{}
+	for rows.Next() {
+		var c StatusChange
+		if err := rows.Scan(&c.From, &c.To, &c.FromCatID, &c.ToCatID, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// UpdateTarget applies the fields present in patch and returns the updated
+// target. A null notes field clears the notes.
+func (r *Repository) UpdateTarget(id int64, patch TargetPatch) (*Target, error) {
+	var sets []string
+	var args []any
+	if patch.Notes.Present {
+		args = append(args, patch.Notes.Ptr())
+		sets = append(sets, fmt.Sprintf("notes = $%d", len(args)))
+	}
+	if patch.Status != nil {
+		args = append(args, *patch.Status)
+		sets = append(sets, fmt.Sprintf("status = $%d, status_changed_at = now()", len(args)))
+	}
+	if len(sets) == 0 {
+		return r.GetTarget(id)
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf(`UPDATE targets SET %s WHERE id = $%d AND deleted_at IS NULL RETURNING `+targetColumns,
+		strings.Join(sets, ", "), len(args))
+	return scanTarget(r.db.QueryRow(query, args...))
+}
+
+// GetTarget returns the target, or sql.ErrNoRows if it does not exist or
+// was deleted
+func (r *Repository) GetTarget(id int64) (*Target, error) {
+	return scanTarget(r.db.QueryRow(`SELECT `+targetColumns+` FROM targets WHERE id = $1 AND deleted_at IS NULL`, id))
+}
+
+// GetDeletedTarget returns a deleted target, or sql.ErrNoRows if there is no
+// deleted target with the id
+func (r *Repository) GetDeletedTarget(id int64) (*Target, error) {
+	return scanTarget(r.db.QueryRow(`SELECT `+targetColumns+` FROM targets WHERE id = $1 AND deleted_at IS NOT NULL`, id))
+}
+
+// CountOpenTargets counts the pending targets of a mission
+func (r *Repository) CountOpenTargets(missionID int64) (int, error) {
+	var n int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND status = 'pending' AND deleted_at IS NULL`,
+		missionID,
+	).Scan(&n)
+	return n, err
+}
+
+// CompleteTargets completes all pending targets of the mission and returns
+// their ids
+func (r *Repository) CompleteTargets(missionID int64) ([]int64, error) {
+	rows, err := r.db.Query(
+		`UPDATE targets SET status = 'completed', status_changed_at = now()
+		 WHERE mission_id = $1 AND status = 'pending' AND deleted_at IS NULL
+		 RETURNING id`,
+		missionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RecordEvent records a completion event for the cat currently on the
+// mission, stamped with when the cat's assignment started. Nothing is
+// recorded if the mission has no cat.
+func (r *Repository) RecordEvent(t EventType, missionID int64, targetID *int64) error {
+	_, err := r.db.Exec(
+		`INSERT INTO completion_events (type, mission_id, target_id, cat_id, started_at)
+		 SELECT $1, m.id, $3, m.cat_id, COALESCE(a.assigned_at, m.created_at)
+		 FROM missions m
+		 LEFT JOIN mission_assignments a ON a.mission_id = m.id AND a.released_at IS NULL
+		 WHERE m.id = $2 AND m.cat_id IS NOT NULL`,
+		t, missionID, targetID,
+	)
+	return err
+}
+
+// DeleteTarget soft deletes a pending target. It returns sql.ErrNoRows if no
+// such target exists.
+func (r *Repository) DeleteTarget(id int64) error {
+	res, err := r.db.Exec(
+		`UPDATE targets SET deleted_at = now() WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL`, id,
+	)
 	if err != nil {
 		return err
 	}
@@ -83,13 +380,15 @@ func (r *Repository) UpdateTarget(t Target) error {
 	return nil
 }
 
-func (r *Repository) DeleteTarget(id int64) error {
-	res, err := r.db.Exec(`DELETE FROM targets WHERE id = $1 AND is_complete = FALSE`, id)
+// RestoreTarget undeletes the target. It returns sql.ErrNoRows if there is
+// no deleted target with the id.
+func (r *Repository) RestoreTarget(id int64) error {
+	res, err := r.db.Exec(`UPDATE targets SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
 	if err != nil {
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
-		return errors.New("cannot delete completed target")
+		return sql.ErrNoRows
 	}
 	return nil
 }
@@ -99,34 +398,308 @@ func (r *Repository) AddTarget(t Target) error {
 	return err
 }
 
-func (r *Repository) GetAllMissions() ([]Mission, error) {
-	rows, err := r.db.Query(`SELECT id, cat_id, name, is_complete FROM missions`)
+// missionSortColumns maps the sortable fields to the expressions they order
+// by; missions without a deadline sort as if it were infinitely far off
+var missionSortColumns = map[string]string{
+	"id":       "id",
+	"priority": "priority_rank",
+	"deadline": "COALESCE(deadline, 'infinity'::date)",
+}
+
+// missionSortValues extracts the cursor value of each sortable field
+var missionSortValues = map[string]func(Mission) string{
+	"id":       func(m Mission) string { return strconv.FormatInt(m.ID, 10) },
+	"priority": func(m Mission) string { return strconv.Itoa(slices.Index(priorities, m.Priority)) },
+	"deadline": func(m Mission) string {
+		if m.Deadline == nil {
+			return "infinity"
+		}
+		return *m.Deadline
+	},
+}
+
+// ListMissions returns one page of missions matching q, ordered by q.Sort
+// with id as a tiebreaker, along with the number of missions matching the
+// filters. Deleted missions are only listed if q.IncludeDeleted is set.
+func (r *Repository) ListMissions(q ListMissionsQuery, cursor *utils.Cursor) (*MissionPage, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if !q.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if q.Status != "" {
+		add("status = $%d", q.Status)
+	}
+	if q.IsComplete != nil {
+		add("(status = 'completed') = $%d", *q.IsComplete)
+	}
+	if q.CatID != nil {
+		add("cat_id = $%d", *q.CatID)
+	}
+	if q.Unassigned {
+		where = append(where, "cat_id IS NULL")
+	}
+	if q.TargetCountry != "" {
+		add(`EXISTS (
+			SELECT 1 FROM targets t
+			WHERE t.mission_id = missions.id AND lower(t.country) = lower($%d)
+			AND (t.deleted_at IS NULL OR t.deleted_at = missions.deleted_at)
+		)`, q.TargetCountry)
+	}
+	if q.Name != "" {
+		add(`name ILIKE '%%' || $%d || '%%'`, utils.EscapeLike(q.Name))
+	}
+	if len(q.Priority) > 0 {
+		add("priority = ANY($%d)", pq.Array(q.Priority))
+	}
+	if q.DeadlineAfter != "" {
+		add("deadline >= $%d::date", q.DeadlineAfter)
+	}
+	if q.DeadlineBefore != "" {
+		add("deadline <= $%d::date", q.DeadlineBefore)
+	}
+	if q.Overdue != nil {
+		add(overdue+" = $%d", *q.Overdue)
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	page := MissionPage{Missions: []Mission{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM missions`+filter, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	field, desc := utils.SortSpec(q.Sort)
+	column := missionSortColumns[field]
+	if cursor != nil {
+		where = append(where, utils.KeysetCondition(column, desc, len(args)+1))
+		args = append(args, cursor.Value, cursor.ID)
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	args = append(args, q.Limit+1)
+	query := fmt.Sprintf(
+		`SELECT %s FROM missions%s ORDER BY %s %s, id %s LIMIT $%d`,
+		missionColumns, filter, column, dir, dir, len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		m, err := scanMission(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Missions = append(page.Missions, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Missions) > q.Limit {
+		page.Missions = page.Missions[:q.Limit]
+		last := page.Missions[q.Limit-1]
+		page.NextCursor = utils.EncodeCursor(utils.Cursor{Sort: q.Sort, Value: missionSortValues[field](last), ID: last.ID})
+	}
+	return &page, nil
+}
+
+// LoadTargets fills in the targets of all given missions with a single
+// query. Deleted missions get the targets deleted along with them.
+func (r *Repository) LoadTargets(missions []Mission) error {
+	if len(missions) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(missions))
+	byID := make(map[int64]*Mission, len(missions))
+	for i := range missions {
+		ids[i] = missions[i].ID
+		byID[missions[i].ID] = &missions[i]
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+targetColumns+` FROM targets t
+		 WHERE mission_id = ANY($1) AND (deleted_at IS NULL OR deleted_at = (
+			SELECT m.deleted_at FROM missions m WHERE m.id = t.mission_id
+		 ))
+		 ORDER BY id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTarget(rows)
+		if err != nil {
+			return err
+		}
+		m := byID[t.MissionID]
+		m.Targets = append(m.Targets, *t)
+	}
+	return rows.Err()
+}
+
+// ListTargets returns one page of targets across missions ordered by id.
+// Deleted targets are only listed if q.IncludeDeleted is set.
+func (r *Repository) ListTargets(q ListTargetsQuery, cursor *utils.Cursor) (*TargetPage, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if !q.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if q.Country != "" {
+		add("lower(country) = lower($%d)", q.Country)
+	}
+	if q.Status != "" {
+		add("status = $%d", q.Status)
+	}
+	if q.IsComplete != nil {
+		add("(status = 'completed') = $%d", *q.IsComplete)
+	}
+	if q.MissionID != nil {
+		add("mission_id = $%d", *q.MissionID)
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	page := TargetPage{Targets: []Target{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM targets`+filter, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		where = append(where, fmt.Sprintf("id > $%d", len(args)))
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, q.Limit+1)
+	query := fmt.Sprintf(`SELECT %s FROM targets%s ORDER BY id LIMIT $%d`, targetColumns, filter, len(args))
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var missions []Mission
 	for rows.Next() {
-		var m Mission
-		err := rows.Scan(&m.ID, &m.CatID, &m.Name, &m.IsComplete)
+		t, err := scanTarget(rows)
 		if err != nil {
 			return nil, err
 		}
-		missions = append(missions, m)
+		page.Targets = append(page.Targets, *t)
 	}
-	return missions, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Targets) > q.Limit {
+		page.Targets = page.Targets[:q.Limit]
+		last := page.Targets[q.Limit-1]
+		page.NextCursor = utils.EncodeCursor(utils.Cursor{Sort: "id", Value: strconv.FormatInt(last.ID, 10), ID: last.ID})
+	}
+	return &page, nil
 }
 
-func (r *Repository) AssignCat(missionID, catID int64) error {
-	query := `UPDATE missions SET cat_id=$1 WHERE id=$2`
-	res, err := r.db.Exec(query, catID, missionID)
+// MoveTarget moves the target to another mission
+func (r *Repository) MoveTarget(id, missionID int64) error {
+	res, err := r.db.Exec(`UPDATE targets SET mission_id = $1 WHERE id = $2 AND deleted_at IS NULL`, missionID, id)
 	if err != nil {
 		return err
 	}
-	count, _ := res.RowsAffected()
-	if count == 0 {
+	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
 	return nil
 }
+
+// SetMissionCat hands the mission from one cat to another and records the
+// change, ending the assignment of from with reason and starting one for to.
+// A nil cat means unassigned. It returns sql.ErrNoRows if the mission is not
+// assigned to from.
+func (r *Repository) SetMissionCat(id int64, from, to *int64, reason ReleaseReason) error {
+	res, err := r.db.Exec(
+		`UPDATE missions SET cat_id = $1 WHERE id = $2 AND cat_id IS NOT DISTINCT FROM $3 AND deleted_at IS NULL`,
+		to, id, from,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO mission_status_changes (mission_id, from_status, to_status, from_cat_id, to_cat_id)
+		 SELECT id, status, status, $2, $3 FROM missions WHERE id = $1`,
+		id, from, to,
+	)
+	if err != nil {
+		return err
+	}
+	if from != nil {
+		if err := r.ReleaseAssignment(id, reason); err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		_, err = r.db.Exec(`INSERT INTO mission_assignments (mission_id, cat_id) VALUES ($1, $2)`, id, *to)
+	}
+	return err
+}
+
+// ReleaseAssignment ends the current assignment of the mission, if any
+func (r *Repository) ReleaseAssignment(missionID int64, reason ReleaseReason) error {
+	_, err := r.db.Exec(
+		`UPDATE mission_assignments SET released_at = now(), reason = $2 WHERE mission_id = $1 AND released_at IS NULL`,
+		missionID, reason,
+	)
+	return err
+}
+
+// ListAssignments returns the assignment history of a mission, oldest first
+func (r *Repository) ListAssignments(missionID int64) ([]Assignment, error) {
+	rows, err := r.db.Query(
+		`SELECT cat_id, assigned_at, released_at, COALESCE(reason, '') FROM mission_assignments
+		 WHERE mission_id = $1 ORDER BY assigned_at, id`,
+		missionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []Assignment{}
+	for rows.Next() {
+		var a Assignment
+		if err := rows.Scan(&a.CatID, &a.AssignedAt, &a.ReleasedAt, &a.Reason); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
@@ -3,22 +3,35 @@ package missions
 import (
 	"database/sql"
 
+	"spy-cats/internal/utils"
+
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.RouterGroup, db *sql.DB) {
+func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, events Notifier, ranks *utils.RankLadder) {
 	repo := NewRepository(db)
-	service := NewService(repo)
+	service := NewService(repo, events, ranks)
 	handler := NewHandler(service)
 
 	r.POST("/", handler.CreateMission)
 	r.GET("/", handler.GetAllMissions)
 	r.GET("/:id", handler.GetMissionByID)
 	r.PUT("/:id/assign", handler.AssignCat)
+	r.DELETE("/:id/assign", handler.UnassignCat)
+	r.POST("/:id/reassign", handler.ReassignCat)
 	r.DELETE("/:id", handler.DeleteMission)
+	r.POST("/:id/restore", handler.RestoreMission)
 	r.PATCH("/:id/complete", handler.MarkMissionComplete)
+	r.POST("/:id/transitions", handler.TransitionMission)
+	r.GET("/:id/transitions", handler.GetMissionTransitions)
+	r.GET("/:id/assignments", handler.GetMissionAssignments)
 
 	r.POST("/:id/targets", handler.AddTarget)
+	r.GET("/:id/targets", handler.GetMissionTargets)
+	r.GET("/targets", handler.ListTargets)
+	r.GET("/targets/:targetId", handler.GetTarget)
 	r.PATCH("/targets/:targetId", handler.UpdateTarget)
+	r.POST("/targets/:targetId/move", handler.MoveTarget)
 	r.DELETE("/targets/:targetId", handler.DeleteTarget)
+	r.POST("/targets/:targetId/restore", handler.RestoreTarget)
 }
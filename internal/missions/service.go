@@ -1,91 +1,1029 @@
 package missions
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+var (
+	// ErrInvalidQuery is returned for contradictory list filters
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrMissionNotFound is returned when the requested mission does not exist
+	ErrMissionNotFound = errors.New("mission not found")
+	// ErrCatNotFound is returned when assigning a cat that does not exist
+	ErrCatNotFound = errors.New("cat not found")
+	// ErrMissionComplete is returned when modifying a completed mission
+	ErrMissionComplete = errors.New("mission is already complete")
+	// ErrMissionClosed is returned when modifying an aborted or failed mission
+	ErrMissionClosed = errors.New("mission is closed")
+	// ErrInvalidTransition is returned for status changes the lifecycle does not allow
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrCatBusy is returned when a cat already has an incomplete mission
+	ErrCatBusy = errors.New("cat already has an active mission")
+	// ErrTargetNotFound is returned when the requested target does not exist
+	ErrTargetNotFound = errors.New("target not found")
+	// ErrInvalidPatch is returned for target updates that set a field to an invalid value
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTargetFrozen is returned when changing a target that can no longer change
+	ErrTargetFrozen = errors.New("target is frozen")
+	// ErrTargetMoved is returned when a target changes mission while it is being moved
+	ErrTargetMoved = errors.New("target was moved by another request")
+	// ErrOpenTargets is returned when completing a mission whose targets are not all complete
+	ErrOpenTargets = errors.New("mission has incomplete targets")
+	// ErrMissionAssigned is returned when deleting a mission that still has a cat
+	// assigned, or assigning a cat to a mission that has another one
+	ErrMissionAssigned = errors.New("mission is assigned to a cat")
+	// ErrMissionInProgress is returned when deleting an active or on hold mission
+	ErrMissionInProgress = errors.New("mission is in progress")
+	// ErrMissionUnassigned is returned when reassigning a mission that has no cat
+	ErrMissionUnassigned = errors.New("mission has no cat assigned")
+	// ErrRankTooLow is returned when a cat would work a mission with more
+	// targets than its rank allows
+	ErrRankTooLow = errors.New("cat rank too low")
+	// ErrInvalidSchedule is returned for deadlines before the start date or
+	// in the past
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrTargetLimit is returned when a mission would end up with too few or too many targets
+	ErrTargetLimit = fmt.Errorf("a mission must have between %d and %d targets", MinTargets, MaxTargets)
+)
+
+const (
+	MinTargets = 1
+	MaxTargets = 3
 )
 
+// MissionRepository is the persistence used by Service. *Repository
+// implements it on top of PostgreSQL.
+type MissionRepository interface {
+	// InTx runs fn in a single transaction
+	InTx(fn func(tx MissionRepository) error) error
+
+	CreateMission(m Mission) (int64, error)
+	GetMissionByID(id int64) (*Mission, error)
+	ListMissions(q ListMissionsQuery, cursor *utils.Cursor) (*MissionPage, error)
+	LockMission(id int64) (*Mission, error)
+	SetMissionStatus(id int64, from, to MissionStatus) error
+	ListStatusChanges(missionID int64) ([]StatusChange, error)
+	DeleteMission(id int64) (time.Time, error)
+	RestoreMission(id int64) error
+	SetMissionCat(id int64, from, to *int64, reason ReleaseReason) error
+	ReleaseAssignment(missionID int64, reason ReleaseReason) error
+	ListAssignments(missionID int64) ([]Assignment, error)
+	LockCat(catID int64) (bool, error)
+	ActiveMissionID(catID, exceptMissionID int64) (*int64, error)
+	CatRecord(catID int64) (years, completed int, err error)
+	RecordEvent(t EventType, missionID int64, targetID *int64) error
+
+	CreateTarget(t Target) (int64, error)
+	GetTarget(id int64) (*Target, error)
+	ListMissionTargets(missionID int64) ([]Target, error)
+	ListTargets(q ListTargetsQuery, cursor *utils.Cursor) (*TargetPage, error)
+	LoadTargets(missions []Mission) error
+	CountTargets(missionID int64) (int, error)
+	CountOpenTargets(missionID int64) (int, error)
+	CompleteTargets(missionID int64) ([]int64, error)
+	UpdateTarget(id int64, patch TargetPatch) (*Target, error)
+	MoveTarget(id, missionID int64) error
+	DeleteTarget(id int64) error
+	GetDeletedTarget(id int64) (*Target, error)
+	RestoreTarget(id int64) error
+}
+
+// Service manages missions and their targets. events, if set, is notified
+// after completion events are committed, and ranks limits the number of
+// targets of the missions a cat works.
 type Service struct {
-	repo *Repository
+	repo   MissionRepository
+	events Notifier
+	ranks  *utils.RankLadder
+	now    func() time.Time
+}
+
+func NewService(repo MissionRepository, events Notifier, ranks *utils.RankLadder) *Service {
+	return &Service{repo: repo, events: events, ranks: ranks, now: time.Now}
 }
 
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// today returns the current UTC date, the calendar of start dates and
+// deadlines
+func (s *Service) today() string {
+	return s.now().UTC().Format(time.DateOnly)
 }
 
+// notify tells the notifier that completion events may have been committed
+func (s *Service) notify() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
+// CreateMission writes the mission and its targets in one transaction. The
+// deadline may not precede the start date or, for missions that are not
+// created complete, today.
 func (s *Service) CreateMission(req CreateMissionRequest) (*Mission, error) {
+	if len(req.Targets) < MinTargets || len(req.Targets) > MaxTargets {
+		return nil, fmt.Errorf("%w, got %d", ErrTargetLimit, len(req.Targets))
+	}
+	for i, t := range req.Targets {
+		if req.IsComplete && !t.IsComplete {
+			return nil, fmt.Errorf("%w: target %d of a complete mission is not complete", ErrOpenTargets, i+1)
+		}
+		if !req.IsComplete && t.IsComplete {
+			return nil, fmt.Errorf("%w: target %d cannot be complete in a planned mission", ErrInvalidTransition, i+1)
+		}
+	}
+
 	mission := Mission{
-		CatID:      req.CatID,
-		Name:       req.Name,
-		IsComplete: req.IsComplete,
+		CatID:       req.CatID,
+		Name:        req.Name,
+		Description: req.Description,
+		Priority:    req.Priority,
+		Status:      MissionPlanned,
+	}
+	if mission.Priority == "" {
+		mission.Priority = PriorityMedium
 	}
-	missionID, err := s.repo.CreateMission(mission)
+	if req.IsComplete {
+		mission.Status = MissionCompleted
+	}
+	if req.StartDate != "" {
+		mission.StartDate = &req.StartDate
+	}
+	if req.Deadline != "" {
+		// dates are YYYY-MM-DD, so they compare as strings
+		if req.StartDate != "" && req.Deadline < req.StartDate {
+			return nil, fmt.Errorf("%w: deadline %s is before start_date %s", ErrInvalidSchedule, req.Deadline, req.StartDate)
+		}
+		if !req.IsComplete && req.Deadline < s.today() {
+			return nil, fmt.Errorf("%w: deadline %s is in the past", ErrInvalidSchedule, req.Deadline)
+		}
+		mission.Deadline = &req.Deadline
+	}
+
+	var missionID int64
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		if mission.CatID != nil {
+			if err := ensureCatAvailable(tx, *mission.CatID, 0, !mission.Status.Closed()); err != nil {
+				return err
+			}
+			if !mission.Status.Closed() {
+				if err := s.checkRank(tx, mission.CatID, len(req.Targets)); err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		if missionID, err = tx.CreateMission(mission); err != nil {
+			return err
+		}
+		mission.ID = missionID
+		for _, t := range req.Targets {
+			target := Target{
+				MissionID: missionID,
+				Name:      t.Name,
+				Country:   t.Country,
+				Notes:     t.Notes,
+				Status:    TargetPending,
+			}
+			if t.IsComplete {
+				target.Status = TargetCompleted
+			}
+			targetID, err := tx.CreateTarget(target)
+			if err != nil {
+				return err
+			}
+			if t.IsComplete {
+				if err := recordEvent(tx, &mission, EventTargetCompleted, &targetID); err != nil {
+					return err
+				}
+			}
+		}
+		if !mission.Status.Closed() {
+			return nil
+		}
+
+		// a mission created complete credits its cat like one completed later
+		if err := recordEvent(tx, &mission, EventMissionCompleted, nil); err != nil {
+			return err
+		}
+		if mission.CatID == nil {
+			return nil
+		}
+		return tx.ReleaseAssignment(missionID, ReleaseReason(mission.Status))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mission.Status.Closed() {
+		s.notify()
+	}
+	return s.repo.GetMissionByID(missionID)
+}
+
+// DeleteMission soft deletes the mission with its targets and returns a
+// summary of what was deleted. An assigned mission is only deleted if
+// unassign is set, which releases the cat first. Active and on hold missions
+// have to be finished or aborted before they can be deleted.
+func (s *Service) DeleteMission(id int64, unassign bool) (*DeletedMission, error) {
+	var summary *DeletedMission
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		mission, err := tx.LockMission(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+		if mission.Status == MissionActive || mission.Status == MissionOnHold {
+			return fmt.Errorf("%w: %s mission %d must be closed before it is deleted", ErrMissionInProgress, mission.Status, id)
+		}
+
+		if mission.CatID != nil {
+			if !unassign {
+				return fmt.Errorf("%w: mission %d is assigned to cat %d", ErrMissionAssigned, id, *mission.CatID)
+			}
+			if err := tx.SetMissionCat(id, mission.CatID, nil, ReleaseMissionDeleted); err != nil {
+				return err
+			}
+		}
+
+		n, err := tx.CountTargets(id)
+		if err != nil {
+			return err
+		}
+		deletedAt, err := tx.DeleteMission(id)
+		if err != nil {
+			return err
+		}
+		summary = &DeletedMission{
+			ID:              mission.ID,
+			Name:            mission.Name,
+			Status:          mission.Status,
+			UnassignedCatID: mission.CatID,
+			DeletedTargets:  n,
+			DeletedAt:       deletedAt,
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return summary, nil
+}
 
-	for _, t := range req.Targets {
-		target := Target{
-			MissionID:  missionID,
-			Name:       t.Name,
-			Country:    t.Country,
-			Notes:      t.Notes,
-			IsComplete: t.IsComplete,
+// RestoreMission undeletes a soft-deleted mission together with the targets
+// that were deleted with it
+func (s *Service) RestoreMission(id int64) (*Mission, error) {
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		if err := tx.RestoreMission(id); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: no deleted mission with id %d", ErrMissionNotFound, id)
+		} else if err != nil {
+			return err
 		}
-		if _, err := s.repo.CreateTarget(target); err != nil {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetMissionByID(id)
+}
+
+// MarkMissionComplete completes the mission. A mission with incomplete
+// targets is only completed when force is set, which completes the targets too.
+func (s *Service) MarkMissionComplete(id int64, force bool) error {
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		_, err := transition(tx, id, MissionCompleted, force)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// TransitionMission moves the mission to another status following the
+// lifecycle: planned -> active -> completed, with active <-> on_hold and
+// aborting or failing along the way. Moving to the current status is a no-op.
+func (s *Service) TransitionMission(id int64, to MissionStatus) (*Mission, error) {
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		_, err := transition(tx, id, to, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return s.repo.GetMissionByID(id)
+}
+
+// GetMissionAssignments returns the cats that worked a mission, oldest first
+func (s *Service) GetMissionAssignments(id int64) ([]Assignment, error) {
+	if _, err := s.repo.GetMissionByID(id); errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+	} else if err != nil {
+		return nil, err
+	}
+	return s.repo.ListAssignments(id)
+}
+
+// GetMissionTransitions returns the status history of a mission
+func (s *Service) GetMissionTransitions(id int64) ([]StatusChange, error) {
+	if _, err := s.repo.GetMissionByID(id); errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+	} else if err != nil {
+		return nil, err
+	}
+	return s.repo.ListStatusChanges(id)
+}
+
+// transition locks the mission and moves it to status to. Starting a mission
+// needs an assigned cat, and completing one needs all targets closed unless
+// force is set, which completes the pending ones.
+func transition(tx MissionRepository, id int64, to MissionStatus, force bool) (*Mission, error) {
+	mission, err := tx.LockMission(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mission.Status == to {
+		return mission, nil
+	}
+	if mission.Status.Closed() {
+		return nil, fmt.Errorf("%w: cannot move %s mission %d to %s", missionClosed(mission), mission.Status, id, to)
+	}
+	if !mission.Status.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: mission %d cannot go from %s to %s", ErrInvalidTransition, id, mission.Status, to)
+	}
+
+	switch to {
+	case MissionActive:
+		if mission.CatID == nil {
+			return nil, fmt.Errorf("%w: mission %d has no cat assigned", ErrInvalidTransition, id)
+		}
+	case MissionCompleted:
+		open, err := tx.CountOpenTargets(id)
+		if err != nil {
 			return nil, err
 		}
+		if open > 0 {
+			if !force {
+				return nil, fmt.Errorf("%w: %d target(s) of mission %d still open", ErrOpenTargets, open, id)
+			}
+			completed, err := tx.CompleteTargets(id)
+			if err != nil {
+				return nil, err
+			}
+			for _, targetID := range completed {
+				if err := recordEvent(tx, mission, EventTargetCompleted, &targetID); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
-	return s.repo.GetMissionByID(missionID)
+
+	if err := setStatus(tx, mission, to); err != nil {
+		return nil, err
+	}
+	mission.Status = to
+	mission.IsComplete = to == MissionCompleted
+	return mission, nil
 }
 
-func (s *Service) DeleteMission(id int64) error {
-	return s.repo.DeleteMission(id)
+// closeIfDone closes an active mission once none of its targets is pending:
+// it is completed if any target was completed and failed otherwise
+func closeIfDone(tx MissionRepository, mission *Mission) error {
+	if mission.Status != MissionActive {
+		return nil
+	}
+	targets, err := tx.ListMissionTargets(mission.ID)
+	if err != nil || len(targets) == 0 {
+		return err
+	}
+
+	to := MissionFailed
+	for _, t := range targets {
+		switch t.Status {
+		case TargetPending:
+			return nil
+		case TargetCompleted:
+			to = MissionCompleted
+		}
+	}
+	return setStatus(tx, mission, to)
 }
 
-func (s *Service) MarkMissionComplete(id int64) error {
-	return s.repo.MarkMissionComplete(id)
+// setStatus moves the mission to status to. Closing the mission ends the
+// assignment of its cat, who stays on record as the cat that worked it and
+// is credited with the completion or failure.
+func setStatus(tx MissionRepository, mission *Mission, to MissionStatus) error {
+	if err := tx.SetMissionStatus(mission.ID, mission.Status, to); err != nil {
+		return err
+	}
+	if !to.Closed() {
+		return nil
+	}
+
+	switch to {
+	case MissionCompleted:
+		if err := recordEvent(tx, mission, EventMissionCompleted, nil); err != nil {
+			return err
+		}
+	case MissionFailed:
+		if err := recordEvent(tx, mission, EventMissionFailed, nil); err != nil {
+			return err
+		}
+	}
+	return tx.ReleaseAssignment(mission.ID, ReleaseReason(to))
 }
 
-func (s *Service) UpdateTarget(id int64, req UpdateTargetRequest) error {
-	target := Target{
-		ID:         id,
-		IsComplete: req.IsComplete != nil && *req.IsComplete,
+// recordEvent records a completion event for the cat working the mission.
+// Nothing is recorded for missions without a cat.
+func recordEvent(tx MissionRepository, mission *Mission, t EventType, targetID *int64) error {
+	if mission.CatID == nil {
+		return nil
 	}
-	if req.Notes != nil {
-		target.Notes = *req.Notes
+	return tx.RecordEvent(t, mission.ID, targetID)
+}
+
+// missionClosed returns the error for changing a closed mission
+func missionClosed(m *Mission) error {
+	if m.Status == MissionCompleted {
+		return ErrMissionComplete
 	}
-	return s.repo.UpdateTarget(target)
+	return ErrMissionClosed
 }
 
+// UpdateTarget applies a merge patch to the target. Only targets of active
+// missions change status, and the mission is closed once no target is
+// pending. Notes are frozen once the target or its mission is closed, and
+// closed targets cannot be reopened.
+func (s *Service) UpdateTarget(id int64, req UpdateTargetRequest) (*Target, error) {
+	next, err := req.status()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *Target
+	err = s.repo.InTx(func(tx MissionRepository) error {
+		current, mission, err := lockTarget(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// a null notes field clears the notes, which reads back as ""
+		if req.Notes.Present && req.Notes.Value != current.Notes {
+			if current.Status.Closed() {
+				return fmt.Errorf("%w: notes of %s target %d cannot be changed", ErrTargetFrozen, current.Status, id)
+			}
+			if mission.Status.Closed() {
+				return fmt.Errorf("%w: mission %d is %s", ErrTargetFrozen, mission.ID, mission.Status)
+			}
+		}
+
+		patch := TargetPatch{Notes: req.Notes}
+		if next != nil && *next != current.Status {
+			switch {
+			case current.Status.Closed() && *next == TargetPending:
+				return fmt.Errorf("%w: %s target %d cannot be reopened", ErrTargetFrozen, current.Status, id)
+			case !current.Status.CanTransitionTo(*next):
+				return fmt.Errorf("%w: target %d cannot go from %s to %s", ErrInvalidTransition, id, current.Status, *next)
+			case mission.Status != MissionActive:
+				return fmt.Errorf("%w: targets of %s mission %d cannot change status", ErrInvalidTransition, mission.Status, mission.ID)
+			}
+			patch.Status = next
+		}
+
+		if updated, err = tx.UpdateTarget(id, patch); err != nil {
+			return err
+		}
+		if patch.Status == nil {
+			return nil
+		}
+		if *patch.Status == TargetCompleted {
+			if err := recordEvent(tx, mission, EventTargetCompleted, &id); err != nil {
+				return err
+			}
+		}
+		return closeIfDone(tx, mission)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return updated, nil
+}
+
+// status returns the target status requested by the patch, if any.
+// is_complete=true means completed and is_complete=false means pending.
+func (req UpdateTargetRequest) status() (*TargetStatus, error) {
+	if req.Status.Present && req.Status.Null {
+		return nil, fmt.Errorf("%w: status cannot be null", ErrInvalidPatch)
+	}
+	if req.IsComplete.Present && req.IsComplete.Null {
+		return nil, fmt.Errorf("%w: is_complete cannot be null", ErrInvalidPatch)
+	}
+
+	var status *TargetStatus
+	if req.Status.Present {
+		switch req.Status.Value {
+		case TargetPending, TargetCompleted, TargetFailed:
+		default:
+			return nil, fmt.Errorf("%w: unknown target status %q", ErrInvalidPatch, req.Status.Value)
+		}
+		status = &req.Status.Value
+	}
+	if req.IsComplete.Present {
+		fromFlag := TargetPending
+		if req.IsComplete.Value {
+			fromFlag = TargetCompleted
+		}
+		if status != nil && *status != fromFlag {
+			return nil, fmt.Errorf("%w: is_complete contradicts status %q", ErrInvalidPatch, *status)
+		}
+		status = &fromFlag
+	}
+	return status, nil
+}
+
+// lockTarget locks the target's mission and returns both with the target
+// read under that lock, so concurrent updates agree on the target state
+func lockTarget(tx MissionRepository, id int64) (*Target, *Mission, error) {
+	target, err := tx.GetTarget(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w with id %d", ErrTargetNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mission, err := tx.LockMission(target.MissionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target, err = tx.GetTarget(id); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w with id %d", ErrTargetNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, mission, nil
+}
+
+// DeleteTarget soft deletes a pending target of an open mission. The mission
+// must keep at least one target and is closed if no pending targets remain.
 func (s *Service) DeleteTarget(id int64) error {
-	return s.repo.DeleteTarget(id)
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		target, mission, err := lockTarget(tx, id)
+		if err != nil {
+			return err
+		}
+		if target.Status.Closed() {
+			return fmt.Errorf("%w: %s target %d cannot be deleted", ErrTargetFrozen, target.Status, id)
+		}
+		if mission.Status.Closed() {
+			return fmt.Errorf("%w: cannot delete target of %s mission %d", missionClosed(mission), mission.Status, mission.ID)
+		}
+
+		n, err := tx.CountTargets(mission.ID)
+		if err != nil {
+			return err
+		}
+		if n <= MinTargets {
+			return fmt.Errorf("%w, mission %d would be left without targets", ErrTargetLimit, mission.ID)
+		}
+
+		if err := tx.DeleteTarget(id); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w with id %d", ErrTargetNotFound, id)
+		} else if err != nil {
+			return err
+		}
+		return closeIfDone(tx, mission)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
 }
 
-func (s *Service) AddTarget(missionID int64, req CreateTarget) error {
-	mission, err := s.repo.GetMissionByID(missionID)
+// RestoreTarget undeletes a soft-deleted target. Its mission must not be
+// deleted or closed and must have room for the target, within the limit of
+// its cat's rank.
+func (s *Service) RestoreTarget(id int64) (*Target, error) {
+	var target *Target
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		deleted, err := tx.GetDeletedTarget(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: no deleted target with id %d", ErrTargetNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		mission, err := tx.LockMission(deleted.MissionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: mission %d of target %d is deleted, restore it first", ErrMissionNotFound, deleted.MissionID, id)
+		}
+		if err != nil {
+			return err
+		}
+		if mission.Status.Closed() {
+			return fmt.Errorf("%w: cannot restore target of %s mission %d", missionClosed(mission), mission.Status, mission.ID)
+		}
+
+		n, err := tx.CountTargets(mission.ID)
+		if err != nil {
+			return err
+		}
+		if n >= MaxTargets {
+			return fmt.Errorf("%w, mission %d already has %d", ErrTargetLimit, mission.ID, n)
+		}
+		if err := s.checkRank(tx, mission.CatID, n+1); err != nil {
+			return err
+		}
+
+		if err := tx.RestoreTarget(id); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: no deleted target with id %d", ErrTargetNotFound, id)
+		} else if err != nil {
+			return err
+		}
+		target, err = tx.GetTarget(id)
+		return err
+	})
 	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// AddTarget adds a target to an incomplete mission and returns it. The
+// mission's cat must be of a rank that allows the extra target.
+func (s *Service) AddTarget(missionID int64, req CreateTarget) (*Target, error) {
+	var target *Target
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		mission, err := tx.LockMission(missionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w with id %d", ErrMissionNotFound, missionID)
+		}
+		if err != nil {
+			return err
+		}
+		if mission.Status.Closed() {
+			return fmt.Errorf("%w: cannot add target to %s mission", missionClosed(mission), mission.Status)
+		}
+
+		n, err := tx.CountTargets(missionID)
+		if err != nil {
+			return err
+		}
+		if n >= MaxTargets {
+			return fmt.Errorf("%w, mission %d already has %d", ErrTargetLimit, missionID, n)
+		}
+		if err := s.checkRank(tx, mission.CatID, n+1); err != nil {
+			return err
+		}
+
+		id, err := tx.CreateTarget(Target{
+			MissionID: missionID,
+			Name:      req.Name,
+			Country:   req.Country,
+			Notes:     req.Notes,
+			Status:    TargetPending,
+		})
+		if err != nil {
+			return err
+		}
+		target, err = tx.GetTarget(id)
 		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (s *Service) GetTarget(id int64) (*Target, error) {
+	target, err := s.repo.GetTarget(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrTargetNotFound, id)
+	}
+	return target, err
+}
+
+// GetMissionTargets returns all targets of a mission
+func (s *Service) GetMissionTargets(missionID int64) ([]Target, error) {
+	if _, err := s.repo.GetMissionByID(missionID); errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, missionID)
+	} else if err != nil {
+		return nil, err
+	}
+	return s.repo.ListMissionTargets(missionID)
+}
+
+func (s *Service) ListTargets(q ListTargetsQuery) (*TargetPage, error) {
+	if q.Limit == 0 {
+		q.Limit = utils.DefaultPageLimit
 	}
-	if mission.IsComplete {
-		return errors.New("cannot add target to completed mission")
+
+	var cursor *utils.Cursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = utils.DecodeCursor(q.Cursor, "id"); err != nil {
+			return nil, err
+		}
 	}
-	_, err = s.repo.CreateTarget(Target{
-		MissionID: missionID,
-		Name:      req.Name,
-		Country:   req.Country,
-		Notes:     req.Notes,
+	return s.repo.ListTargets(q, cursor)
+}
+
+// MoveTarget moves a pending target to another open mission with room for
+// it, within the limit of its cat's rank. The source mission must keep at least one target and is closed if no
+// pending targets remain.
+func (s *Service) MoveTarget(id, missionID int64) (*Target, error) {
+	var moved *Target
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		current, err := tx.GetTarget(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w with id %d", ErrTargetNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+		if current.MissionID == missionID {
+			moved = current
+			return nil
+		}
+
+		source, dest, err := lockMissionPair(tx, current.MissionID, missionID)
+		if err != nil {
+			return err
+		}
+		if current, err = tx.GetTarget(id); err != nil {
+			return err
+		}
+		if current.MissionID != source.ID {
+			return fmt.Errorf("%w: target %d", ErrTargetMoved, id)
+		}
+		if current.Status.Closed() {
+			return fmt.Errorf("%w: %s target %d cannot be moved", ErrTargetFrozen, current.Status, id)
+		}
+		if source.Status.Closed() {
+			return fmt.Errorf("%w: cannot move target out of %s mission %d", missionClosed(source), source.Status, source.ID)
+		}
+		if dest.Status.Closed() {
+			return fmt.Errorf("%w: cannot move target to %s mission %d", missionClosed(dest), dest.Status, dest.ID)
+		}
+
+		n, err := tx.CountTargets(source.ID)
+		if err != nil {
+			return err
+		}
+		if n <= MinTargets {
+			return fmt.Errorf("%w, mission %d would be left without targets", ErrTargetLimit, source.ID)
+		}
+		if n, err = tx.CountTargets(dest.ID); err != nil {
+			return err
+		}
+		if n >= MaxTargets {
+			return fmt.Errorf("%w, mission %d already has %d", ErrTargetLimit, dest.ID, n)
+		}
+		if err := s.checkRank(tx, dest.CatID, n+1); err != nil {
+			return err
+		}
+
+		if err := tx.MoveTarget(id, dest.ID); err != nil {
+			return err
+		}
+		if err := closeIfDone(tx, source); err != nil {
+			return err
+		}
+		moved, err = tx.GetTarget(id)
+		return err
 	})
-	return err
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return moved, nil
 }
 
-func (s *Service) GetAllMissions() ([]Mission, error) {
-	return s.repo.GetAllMissions()
+// lockMissionPair locks both missions in id order so that concurrent moves
+// in opposite directions cannot deadlock
+func lockMissionPair(tx MissionRepository, sourceID, destID int64) (source, dest *Mission, err error) {
+	lock := func(id int64) (*Mission, error) {
+		m, err := tx.LockMission(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+		}
+		return m, err
+	}
+
+	if sourceID < destID {
+		if source, err = lock(sourceID); err != nil {
+			return nil, nil, err
+		}
+		dest, err = lock(destID)
+	} else {
+		if dest, err = lock(destID); err != nil {
+			return nil, nil, err
+		}
+		source, err = lock(sourceID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, dest, nil
+}
+
+func (s *Service) GetAllMissions(q ListMissionsQuery) (*MissionPage, error) {
+	if q.Limit == 0 {
+		q.Limit = utils.DefaultPageLimit
+	}
+	if q.Sort == "" {
+		q.Sort = "id"
+	}
+	if q.Unassigned && q.CatID != nil {
+		return nil, fmt.Errorf("%w: unassigned cannot be combined with cat_id", ErrInvalidQuery)
+	}
+	if q.DeadlineAfter != "" && q.DeadlineBefore != "" && q.DeadlineAfter > q.DeadlineBefore {
+		return nil, fmt.Errorf("%w: deadline_after must not be after deadline_before", ErrInvalidQuery)
+	}
+
+	var cursor *utils.Cursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = utils.DecodeCursor(q.Cursor, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := s.repo.ListMissions(q, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if q.Include == "targets" {
+		if err := s.repo.LoadTargets(page.Missions); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
 }
 
 func (s *Service) GetMissionByID(id int64) (*Mission, error) {
 	return s.repo.GetMissionByID(id)
 }
 
+// AssignCat assigns the cat to an unassigned mission. A cat may only have
+// one open mission at a time, of no more targets than its rank allows, and
+// closed missions cannot be reassigned; missions that already have another
+// cat go through ReassignCat.
 func (s *Service) AssignCat(missionID, catID int64) error {
-	return s.repo.AssignCat(missionID, catID)
+	return s.repo.InTx(func(tx MissionRepository) error {
+		mission, err := lockOpenMission(tx, missionID)
+		if err != nil {
+			return err
+		}
+		if mission.CatID != nil {
+			if *mission.CatID == catID {
+				return nil
+			}
+			return fmt.Errorf("%w: mission %d is assigned to cat %d, reassign it instead", ErrMissionAssigned, missionID, *mission.CatID)
+		}
+
+		if err := ensureCatAvailable(tx, catID, missionID, true); err != nil {
+			return err
+		}
+		if err := s.checkMissionRank(tx, catID, missionID); err != nil {
+			return err
+		}
+		return tx.SetMissionCat(missionID, nil, &catID, "")
+	})
+}
+
+// ReassignCat hands an assigned mission to another cat in one step, so the
+// mission is never left without a cat
+func (s *Service) ReassignCat(missionID, catID int64) (*Mission, error) {
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		mission, err := lockOpenMission(tx, missionID)
+		if err != nil {
+			return err
+		}
+		if mission.CatID == nil {
+			return fmt.Errorf("%w: mission %d, assign a cat instead", ErrMissionUnassigned, missionID)
+		}
+		if *mission.CatID == catID {
+			return nil
+		}
+
+		if err := ensureCatAvailable(tx, catID, missionID, true); err != nil {
+			return err
+		}
+		if err := s.checkMissionRank(tx, catID, missionID); err != nil {
+			return err
+		}
+		return tx.SetMissionCat(missionID, mission.CatID, &catID, ReleaseReassigned)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetMissionByID(missionID)
+}
+
+// UnassignCat releases the cat from the mission. Closed missions keep their
+// cat, and an active mission is put on hold since it cannot go on without
+// one. Unassigning an unassigned mission is a no-op.
+func (s *Service) UnassignCat(missionID int64) (*Mission, error) {
+	err := s.repo.InTx(func(tx MissionRepository) error {
+		mission, err := lockOpenMission(tx, missionID)
+		if err != nil {
+			return err
+		}
+		if mission.CatID == nil {
+			return nil
+		}
+
+		if mission.Status == MissionActive {
+			if _, err := transition(tx, missionID, MissionOnHold, false); err != nil {
+				return err
+			}
+		}
+		return tx.SetMissionCat(missionID, mission.CatID, nil, ReleaseUnassigned)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetMissionByID(missionID)
+}
+
+// lockOpenMission locks the mission and checks it is not closed
+func lockOpenMission(tx MissionRepository, id int64) (*Mission, error) {
+	mission, err := tx.LockMission(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrMissionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mission.Status.Closed() {
+		return nil, fmt.Errorf("%w: mission %d", missionClosed(mission), id)
+	}
+	return mission, nil
+}
+
+// ensureCatAvailable locks the cat and checks it exists and, if active is
+// set, that it has no open mission other than exceptMissionID
+func ensureCatAvailable(tx MissionRepository, catID, exceptMissionID int64, active bool) error {
+	exists, err := tx.LockCat(catID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w with id %d", ErrCatNotFound, catID)
+	}
+	if !active {
+		return nil
+	}
+
+	activeID, err := tx.ActiveMissionID(catID, exceptMissionID)
+	if err != nil {
+		return err
+	}
+	if activeID != nil {
+		return fmt.Errorf("%w: cat %d is on mission %d", ErrCatBusy, catID, *activeID)
+	}
+	return nil
+}
+
+// checkMissionRank checks that the rank of the cat allows the targets the
+// mission has
+func (s *Service) checkMissionRank(tx MissionRepository, catID, missionID int64) error {
+	n, err := tx.CountTargets(missionID)
+	if err != nil {
+		return err
+	}
+	return s.checkRank(tx, &catID, n)
+}
+
+// checkRank checks that the rank of the cat, if any, allows a mission with
+// the given number of targets
+func (s *Service) checkRank(tx MissionRepository, catID *int64, targets int) error {
+	if catID == nil {
+		return nil
+	}
+	years, completed, err := tx.CatRecord(*catID)
+	if err != nil {
+		return err
+	}
+	tier := s.ranks.Rank(years, completed)
+	if targets > tier.MaxTargets {
+		return fmt.Errorf("%w: cat %d is a %s and may work missions of at most %d targets, not %d",
+			ErrRankTooLow, *catID, tier.Name, tier.MaxTargets, targets)
+	}
+	return nil
 }
@@ -0,0 +1,1631 @@
+package missions_test
+
+import (
+	"database/sql"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/database/dbtest"
+	"spy-cats/internal/missions"
+	"spy-cats/internal/utils"
+)
+
+// fakeRepo is an in-memory MissionRepository. InTx restores the previous
+// state when fn fails, like a rolled back transaction. Deletes stamp rows
+// with a clock that advances by a second per delete.
+type fakeRepo struct {
+	missions map[int64]missions.Mission
+	targets  map[int64]missions.Target
+	cats     map[int64]bool
+	// records holds the experience of cats that are not veterans
+	records map[int64]catRecord
+	changes []missions.StatusChange
+	// assignments holds the assignment history of each mission
+	assignments map[int64][]missions.Assignment
+	events      []event
+	nextID      int64
+	clock       time.Time
+}
+
+// catRecord is the years of experience and completed missions of a cat
+type catRecord struct {
+	years, completed int
+}
+
+// veteran is the record of cats without one, which holds the highest rank
+var veteran = catRecord{years: 10, completed: 30}
+
+// ranks is the rank ladder the services under test use
+var ranks = utils.DefaultRankLadder()
+
+// event is a recorded completion event
+type event struct {
+	Type      missions.EventType
+	MissionID int64
+	TargetID  *int64
+	CatID     int64
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		missions:    make(map[int64]missions.Mission),
+		targets:     make(map[int64]missions.Target),
+		cats:        make(map[int64]bool),
+		records:     make(map[int64]catRecord),
+		assignments: make(map[int64][]missions.Assignment),
+		clock:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (r *fakeRepo) now() *time.Time {
+	r.clock = r.clock.Add(time.Second)
+	now := r.clock
+	return &now
+}
+
+func (r *fakeRepo) id() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *fakeRepo) InTx(fn func(tx missions.MissionRepository) error) error {
+	return dbtest.InTx(func() error { return fn(r) },
+		&r.missions, &r.targets, &r.assignments, &r.changes, &r.events, &r.nextID)
+}
+
+func (r *fakeRepo) CreateMission(m missions.Mission) (int64, error) {
+	m.ID = r.id()
+	m.Targets = nil
+	m.IsComplete = m.Status == missions.MissionCompleted
+	r.missions[m.ID] = m
+	r.changes = append(r.changes, missions.StatusChange{To: m.Status, ToCatID: m.CatID})
+	if m.CatID != nil {
+		r.assign(m.ID, *m.CatID)
+	}
+	return m.ID, nil
+}
+
+func (r *fakeRepo) GetMissionByID(id int64) (*missions.Mission, error) {
+	m, ok := r.missions[id]
+	if !ok || m.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	m.Targets = r.targetsOf(id)
+	return &m, nil
+}
+
+func (r *fakeRepo) ListMissions(q missions.ListMissionsQuery, cursor *utils.Cursor) (*missions.MissionPage, error) {
+	page := &missions.MissionPage{Missions: []missions.Mission{}}
+	for _, m := range r.missions {
+		page.Missions = append(page.Missions, m)
+	}
+	sort.Slice(page.Missions, func(i, j int) bool { return page.Missions[i].ID < page.Missions[j].ID })
+	page.Total = len(page.Missions)
+	return page, nil
+}
+
+func (r *fakeRepo) LockMission(id int64) (*missions.Mission, error) {
+	m, ok := r.missions[id]
+	if !ok || m.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	return &m, nil
+}
+
+func (r *fakeRepo) SetMissionStatus(id int64, from, to missions.MissionStatus) error {
+	m, ok := r.missions[id]
+	if !ok || m.Status != from {
+		return sql.ErrNoRows
+	}
+	m.Status, m.IsComplete = to, to == missions.MissionCompleted
+	r.missions[id] = m
+	r.changes = append(r.changes, missions.StatusChange{From: from, To: to})
+	return nil
+}
+
+func (r *fakeRepo) ListStatusChanges(missionID int64) ([]missions.StatusChange, error) {
+	return r.changes, nil
+}
+
+func (r *fakeRepo) DeleteMission(id int64) (time.Time, error) {
+	m, ok := r.missions[id]
+	if !ok || m.CatID != nil || m.DeletedAt != nil {
+		return time.Time{}, sql.ErrNoRows
+	}
+	m.DeletedAt = r.now()
+	r.missions[id] = m
+	for _, t := range r.targetsOf(id) {
+		t.DeletedAt = m.DeletedAt
+		r.targets[t.ID] = t
+	}
+	return *m.DeletedAt, nil
+}
+
+func (r *fakeRepo) RestoreMission(id int64) error {
+	m, ok := r.missions[id]
+	if !ok || m.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+	for _, t := range r.targets {
+		if t.MissionID == id && t.DeletedAt != nil && t.DeletedAt.Equal(*m.DeletedAt) {
+			t.DeletedAt = nil
+			r.targets[t.ID] = t
+		}
+	}
+	m.DeletedAt = nil
+	r.missions[id] = m
+	return nil
+}
+
+func (r *fakeRepo) SetMissionCat(id int64, from, to *int64, reason missions.ReleaseReason) error {
+	m, ok := r.missions[id]
+	if !ok || !equalCat(m.CatID, from) {
+		return sql.ErrNoRows
+	}
+	m.CatID = to
+	r.missions[id] = m
+	r.changes = append(r.changes, missions.StatusChange{From: m.Status, To: m.Status, FromCatID: from, ToCatID: to})
+	if from != nil {
+		r.ReleaseAssignment(id, reason)
+	}
+	if to != nil {
+		r.assign(id, *to)
+	}
+	return nil
+}
+
+func (r *fakeRepo) assign(missionID, catID int64) {
+	r.assignments[missionID] = append(r.assignments[missionID], missions.Assignment{CatID: &catID, AssignedAt: *r.now()})
+}
+
+func (r *fakeRepo) ReleaseAssignment(missionID int64, reason missions.ReleaseReason) error {
+	as := r.assignments[missionID]
+	if n := len(as); n > 0 && as[n-1].ReleasedAt == nil {
+		as[n-1].ReleasedAt, as[n-1].Reason = r.now(), reason
+	}
+	return nil
+}
+
+func (r *fakeRepo) ListAssignments(missionID int64) ([]missions.Assignment, error) {
+	return append([]missions.Assignment{}, r.assignments[missionID]...), nil
+}
+
+func equalCat(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (r *fakeRepo) CatRecord(catID int64) (years, completed int, err error) {
+	if !r.cats[catID] {
+		return 0, 0, sql.ErrNoRows
+	}
+	rec, ok := r.records[catID]
+	if !ok {
+		rec = veteran
+	}
+	return rec.years, rec.completed, nil
+}
+
+func (r *fakeRepo) LockCat(catID int64) (bool, error) {
+	return r.cats[catID], nil
+}
+
+func (r *fakeRepo) ActiveMissionID(catID, exceptMissionID int64) (*int64, error) {
+	for id, m := range r.missions {
+		if id != exceptMissionID && !m.Status.Closed() && m.CatID != nil && *m.CatID == catID {
+			return &id, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) CreateTarget(t missions.Target) (int64, error) {
+	t.ID = r.id()
+	t.IsComplete = t.Status == missions.TargetCompleted
+	r.targets[t.ID] = t
+	return t.ID, nil
+}
+
+func (r *fakeRepo) GetTarget(id int64) (*missions.Target, error) {
+	t, ok := r.targets[id]
+	if !ok || t.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+func (r *fakeRepo) GetDeletedTarget(id int64) (*missions.Target, error) {
+	t, ok := r.targets[id]
+	if !ok || t.DeletedAt == nil {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+func (r *fakeRepo) ListMissionTargets(missionID int64) ([]missions.Target, error) {
+	return r.targetsOf(missionID), nil
+}
+
+func (r *fakeRepo) ListTargets(q missions.ListTargetsQuery, cursor *utils.Cursor) (*missions.TargetPage, error) {
+	page := &missions.TargetPage{Targets: []missions.Target{}}
+	for _, t := range r.targets {
+		page.Targets = append(page.Targets, t)
+	}
+	sort.Slice(page.Targets, func(i, j int) bool { return page.Targets[i].ID < page.Targets[j].ID })
+	page.Total = len(page.Targets)
+	return page, nil
+}
+
+func (r *fakeRepo) MoveTarget(id, missionID int64) error {
+	t, ok := r.targets[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	t.MissionID = missionID
+	r.targets[id] = t
+	return nil
+}
+
+func (r *fakeRepo) LoadTargets(ms []missions.Mission) error {
+	for i := range ms {
+		ms[i].Targets = r.targetsOf(ms[i].ID)
+	}
+	return nil
+}
+
+func (r *fakeRepo) CountTargets(missionID int64) (int, error) {
+	return len(r.targetsOf(missionID)), nil
+}
+
+func (r *fakeRepo) CountOpenTargets(missionID int64) (int, error) {
+	n := 0
+	for _, t := range r.targetsOf(missionID) {
+		if t.Status == missions.TargetPending {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeRepo) CompleteTargets(missionID int64) ([]int64, error) {
+	ids := []int64{}
+	for _, t := range r.targetsOf(missionID) {
+		if t.Status == missions.TargetPending {
+			t.Status, t.IsComplete = missions.TargetCompleted, true
+			r.targets[t.ID] = t
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeRepo) RecordEvent(t missions.EventType, missionID int64, targetID *int64) error {
+	if m := r.missions[missionID]; m.CatID != nil {
+		r.events = append(r.events, event{Type: t, MissionID: missionID, TargetID: targetID, CatID: *m.CatID})
+	}
+	return nil
+}
+
+func (r *fakeRepo) UpdateTarget(id int64, patch missions.TargetPatch) (*missions.Target, error) {
+	t, ok := r.targets[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if patch.Notes.Present {
+		t.Notes = patch.Notes.Value
+	}
+	if patch.Status != nil {
+		t.Status, t.IsComplete = *patch.Status, *patch.Status == missions.TargetCompleted
+	}
+	r.targets[id] = t
+	return &t, nil
+}
+
+func (r *fakeRepo) DeleteTarget(id int64) error {
+	t, ok := r.targets[id]
+	if !ok || t.DeletedAt != nil || t.Status != missions.TargetPending {
+		return sql.ErrNoRows
+	}
+	t.DeletedAt = r.now()
+	r.targets[id] = t
+	return nil
+}
+
+func (r *fakeRepo) RestoreTarget(id int64) error {
+	t, ok := r.targets[id]
+	if !ok || t.DeletedAt == nil {
+		return sql.ErrNoRows
+	}
+	t.DeletedAt = nil
+	r.targets[id] = t
+	return nil
+}
+
+// targetsOf returns the targets of a mission that are not deleted
+func (r *fakeRepo) targetsOf(missionID int64) []missions.Target {
+	var ts []missions.Target
+	for _, t := range r.targets {
+		if t.MissionID == missionID && t.DeletedAt == nil {
+			ts = append(ts, t)
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].ID < ts[j].ID })
+	return ts
+}
+
+// seedMission stores an active or completed mission with one pending or
+// completed target per flag and returns the mission id and target ids
+func (r *fakeRepo) seedMission(complete bool, targets ...bool) (int64, []int64) {
+	status := missions.MissionActive
+	if complete {
+		status = missions.MissionCompleted
+	}
+	statuses := make([]missions.TargetStatus, len(targets))
+	for i, done := range targets {
+		statuses[i] = missions.TargetPending
+		if done {
+			statuses[i] = missions.TargetCompleted
+		}
+	}
+	return r.seed(status, statuses...)
+}
+
+// seed stores a mission with the given status and one target per target
+// status and returns the mission id and target ids
+func (r *fakeRepo) seed(status missions.MissionStatus, targets ...missions.TargetStatus) (int64, []int64) {
+	catID := int64(1)
+	r.cats[catID] = true
+	missionID, _ := r.CreateMission(missions.Mission{Name: "Operation Stealth", CatID: &catID, Status: status})
+	ids := make([]int64, len(targets))
+	for i, ts := range targets {
+		ids[i], _ = r.CreateTarget(missions.Target{
+			MissionID: missionID,
+			Name:      "Agent Smith",
+			Country:   "Russia",
+			Notes:     "original",
+			Status:    ts,
+		})
+	}
+	return missionID, ids
+}
+
+func TestServiceUpdateTarget(t *testing.T) {
+	tests := []struct {
+		name             string
+		missionComplete  bool
+		targets          []bool
+		req              missions.UpdateTargetRequest
+		wantErr          error
+		wantNotes        string
+		wantComplete     bool
+		wantMissionState bool
+	}{
+		{
+			name:             "updates notes of open target",
+			targets:          []bool{false, false},
+			req:              missions.UpdateTargetRequest{Notes: utils.Some("new intel")},
+			wantNotes:        "new intel",
+			wantComplete:     false,
+			wantMissionState: false,
+		},
+		{
+			name:             "null notes clears notes",
+			targets:          []bool{false, false},
+			req:              missions.UpdateTargetRequest{Notes: utils.Null[string]()},
+			wantNotes:        "",
+			wantComplete:     false,
+			wantMissionState: false,
+		},
+		{
+			name:             "omitted fields keep their values",
+			targets:          []bool{false, false},
+			req:              missions.UpdateTargetRequest{},
+			wantNotes:        "original",
+			wantComplete:     false,
+			wantMissionState: false,
+		},
+		{
+			name:             "completes target with final notes",
+			targets:          []bool{false, false},
+			req:              missions.UpdateTargetRequest{IsComplete: utils.Some(true), Notes: utils.Some("done")},
+			wantNotes:        "done",
+			wantComplete:     true,
+			wantMissionState: false,
+		},
+		{
+			name:             "completing last open target completes mission",
+			targets:          []bool{false, true},
+			req:              missions.UpdateTargetRequest{IsComplete: utils.Some(true)},
+			wantNotes:        "original",
+			wantComplete:     true,
+			wantMissionState: true,
+		},
+		{
+			name:             "rejects notes on completed target",
+			targets:          []bool{true, false},
+			req:              missions.UpdateTargetRequest{Notes: utils.Some("rewritten")},
+			wantErr:          missions.ErrTargetFrozen,
+			wantNotes:        "original",
+			wantComplete:     true,
+			wantMissionState: false,
+		},
+		{
+			name:             "rejects notes when mission is complete",
+			missionComplete:  true,
+			targets:          []bool{false, false},
+			req:              missions.UpdateTargetRequest{Notes: utils.Some("rewritten")},
+			wantErr:          missions.ErrTargetFrozen,
+			wantNotes:        "original",
+			wantComplete:     false,
+			wantMissionState: true,
+		},
+		{
+			name:             "rejects clearing notes on completed target",
+			targets:          []bool{true, false},
+			req:              missions.UpdateTargetRequest{Notes: utils.Null[string]()},
+			wantErr:          missions.ErrTargetFrozen,
+			wantNotes:        "original",
+			wantComplete:     true,
+			wantMissionState: false,
+		},
+		{
+			name:             "rejects reopening completed target",
+			targets:          []bool{true, false},
+			req:              missions.UpdateTargetRequest{IsComplete: utils.Some(false)},
+			wantErr:          missions.ErrTargetFrozen,
+			wantNotes:        "original",
+			wantComplete:     true,
+			wantMissionState: false,
+		},
+		{
+			name:             "allows unchanged notes on completed target",
+			targets:          []bool{true, false},
+			req:              missions.UpdateTargetRequest{IsComplete: utils.Some(true), Notes: utils.Some("original")},
+			wantNotes:        "original",
+			wantComplete:     true,
+			wantMissionState: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			missionID, targetIDs := repo.seedMission(tt.missionComplete, tt.targets...)
+			svc := missions.NewService(repo, nil, ranks)
+
+			updated, err := svc.UpdateTarget(targetIDs[0], tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantNotes, updated.Notes)
+			}
+
+			target, _ := repo.GetTarget(targetIDs[0])
+			assert.Equal(t, tt.wantNotes, target.Notes)
+			assert.Equal(t, tt.wantComplete, target.IsComplete)
+
+			mission, _ := repo.GetMissionByID(missionID)
+			assert.Equal(t, tt.wantMissionState, mission.IsComplete)
+		})
+	}
+
+	t.Run("unknown target", func(t *testing.T) {
+		svc := missions.NewService(newFakeRepo(), nil, ranks)
+		_, err := svc.UpdateTarget(42, missions.UpdateTargetRequest{IsComplete: utils.Some(true)})
+		assert.ErrorIs(t, err, missions.ErrTargetNotFound)
+	})
+
+	t.Run("null is_complete is rejected", func(t *testing.T) {
+		svc := missions.NewService(newFakeRepo(), nil, ranks)
+		_, err := svc.UpdateTarget(1, missions.UpdateTargetRequest{IsComplete: utils.Null[bool]()})
+		assert.ErrorIs(t, err, missions.ErrInvalidPatch)
+	})
+}
+
+func TestServiceMarkMissionComplete(t *testing.T) {
+	t.Run("rejects open targets without force", func(t *testing.T) {
+		repo := newFakeRepo()
+		missionID, _ := repo.seedMission(false, false, true)
+
+		err := missions.NewService(repo, nil, ranks).MarkMissionComplete(missionID, false)
+		assert.ErrorIs(t, err, missions.ErrOpenTargets)
+
+		mission, _ := repo.GetMissionByID(missionID)
+		assert.False(t, mission.IsComplete)
+	})
+
+	t.Run("force completes open targets", func(t *testing.T) {
+		repo := newFakeRepo()
+		missionID, _ := repo.seedMission(false, false, true)
+
+		require.NoError(t, missions.NewService(repo, nil, ranks).MarkMissionComplete(missionID, true))
+
+		mission, _ := repo.GetMissionByID(missionID)
+		assert.True(t, mission.IsComplete)
+		for _, target := range mission.Targets {
+			assert.True(t, target.IsComplete)
+		}
+	})
+}
+
+func TestServiceMoveTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       []bool
+		destComplete bool
+		dest         []bool
+		moveIndex    int
+		wantErr      error
+		wantMoved    bool
+		wantSourceOK bool
+	}{
+		{
+			name:      "moves open target",
+			source:    []bool{false, false},
+			dest:      []bool{false},
+			wantMoved: true,
+		},
+		{
+			name:         "completes source left with completed targets",
+			source:       []bool{false, true},
+			dest:         []bool{false},
+			wantMoved:    true,
+			wantSourceOK: true,
+		},
+		{
+			name:      "rejects completed target",
+			source:    []bool{true, false},
+			dest:      []bool{false},
+			wantErr:   missions.ErrTargetFrozen,
+			wantMoved: false,
+		},
+		{
+			name:         "rejects completed destination",
+			source:       []bool{false, false},
+			destComplete: true,
+			dest:         []bool{true},
+			wantErr:      missions.ErrMissionComplete,
+		},
+		{
+			name:    "rejects full destination",
+			source:  []bool{false, false},
+			dest:    []bool{false, false, false},
+			wantErr: missions.ErrTargetLimit,
+		},
+		{
+			name:    "rejects leaving source without targets",
+			source:  []bool{false},
+			dest:    []bool{false},
+			wantErr: missions.ErrTargetLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			sourceID, sourceTargets := repo.seedMission(false, tt.source...)
+			destID, _ := repo.seedMission(tt.destComplete, tt.dest...)
+			svc := missions.NewService(repo, nil, ranks)
+
+			moved, err := svc.MoveTarget(sourceTargets[0], destID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, destID, moved.MissionID)
+			}
+
+			target, _ := repo.GetTarget(sourceTargets[0])
+			if tt.wantMoved {
+				assert.Equal(t, destID, target.MissionID)
+			} else {
+				assert.Equal(t, sourceID, target.MissionID)
+			}
+			source, _ := repo.GetMissionByID(sourceID)
+			assert.Equal(t, tt.wantSourceOK, source.IsComplete)
+		})
+	}
+
+	t.Run("unknown destination", func(t *testing.T) {
+		repo := newFakeRepo()
+		_, targets := repo.seedMission(false, false, false)
+		_, err := missions.NewService(repo, nil, ranks).MoveTarget(targets[0], 42)
+		assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+	})
+}
+
+func TestServiceDeleteTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     missions.MissionStatus
+		targets    []missions.TargetStatus
+		wantErr    error
+		wantStatus missions.MissionStatus
+	}{
+		{
+			name:       "deletes pending target",
+			status:     missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending, missions.TargetPending},
+			wantStatus: missions.MissionActive,
+		},
+		{
+			name:       "completes mission left with completed targets",
+			status:     missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending, missions.TargetCompleted},
+			wantStatus: missions.MissionCompleted,
+		},
+		{
+			name:       "fails mission left with failed targets",
+			status:     missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending, missions.TargetFailed},
+			wantStatus: missions.MissionFailed,
+		},
+		{
+			name:       "rejects completed target",
+			status:     missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetCompleted, missions.TargetPending},
+			wantErr:    missions.ErrTargetFrozen,
+			wantStatus: missions.MissionActive,
+		},
+		{
+			name:       "rejects target of closed mission",
+			status:     missions.MissionAborted,
+			targets:    []missions.TargetStatus{missions.TargetPending, missions.TargetPending},
+			wantErr:    missions.ErrMissionClosed,
+			wantStatus: missions.MissionAborted,
+		},
+		{
+			name:       "rejects last target",
+			status:     missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			wantErr:    missions.ErrTargetLimit,
+			wantStatus: missions.MissionActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			missionID, targetIDs := repo.seed(tt.status, tt.targets...)
+			svc := missions.NewService(repo, nil, ranks)
+
+			err := svc.DeleteTarget(targetIDs[0])
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				_, err := repo.GetTarget(targetIDs[0])
+				assert.NoError(t, err)
+			} else {
+				require.NoError(t, err)
+				_, err := repo.GetTarget(targetIDs[0])
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+			}
+			mission, _ := repo.GetMissionByID(missionID)
+			assert.Equal(t, tt.wantStatus, mission.Status)
+		})
+	}
+
+	t.Run("unknown target", func(t *testing.T) {
+		err := missions.NewService(newFakeRepo(), nil, ranks).DeleteTarget(42)
+		assert.ErrorIs(t, err, missions.ErrTargetNotFound)
+	})
+}
+
+func TestServiceAddTarget(t *testing.T) {
+	repo := newFakeRepo()
+	missionID, _ := repo.seedMission(false, false)
+
+	target, err := missions.NewService(repo, nil, ranks).AddTarget(missionID, missions.CreateTarget{Name: "Agent Jones", Country: "France"})
+	require.NoError(t, err)
+	assert.Equal(t, missionID, target.MissionID)
+	assert.Equal(t, "Agent Jones", target.Name)
+	assert.NotZero(t, target.ID)
+}
+
+func TestServiceTransitionMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       missions.MissionStatus
+		unassigned bool
+		targets    []missions.TargetStatus
+		to         missions.MissionStatus
+		wantErr    error
+		wantStatus missions.MissionStatus
+	}{
+		{
+			name:       "starts planned mission",
+			from:       missions.MissionPlanned,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionActive,
+			wantStatus: missions.MissionActive,
+		},
+		{
+			name:       "cannot start mission without cat",
+			from:       missions.MissionPlanned,
+			unassigned: true,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionActive,
+			wantErr:    missions.ErrInvalidTransition,
+			wantStatus: missions.MissionPlanned,
+		},
+		{
+			name:       "puts active mission on hold",
+			from:       missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionOnHold,
+			wantStatus: missions.MissionOnHold,
+		},
+		{
+			name:       "resumes mission on hold",
+			from:       missions.MissionOnHold,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionActive,
+			wantStatus: missions.MissionActive,
+		},
+		{
+			name:       "planned mission cannot be completed",
+			from:       missions.MissionPlanned,
+			targets:    []missions.TargetStatus{missions.TargetCompleted},
+			to:         missions.MissionCompleted,
+			wantErr:    missions.ErrInvalidTransition,
+			wantStatus: missions.MissionPlanned,
+		},
+		{
+			name:       "completes mission with closed targets",
+			from:       missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetCompleted, missions.TargetFailed},
+			to:         missions.MissionCompleted,
+			wantStatus: missions.MissionCompleted,
+		},
+		{
+			name:       "cannot complete mission with pending targets",
+			from:       missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionCompleted,
+			wantErr:    missions.ErrOpenTargets,
+			wantStatus: missions.MissionActive,
+		},
+		{
+			name:       "aborts mission on hold",
+			from:       missions.MissionOnHold,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionAborted,
+			wantStatus: missions.MissionAborted,
+		},
+		{
+			name:       "planned mission cannot fail",
+			from:       missions.MissionPlanned,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionFailed,
+			wantErr:    missions.ErrInvalidTransition,
+			wantStatus: missions.MissionPlanned,
+		},
+		{
+			name:       "aborted mission is final",
+			from:       missions.MissionAborted,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionActive,
+			wantErr:    missions.ErrMissionClosed,
+			wantStatus: missions.MissionAborted,
+		},
+		{
+			name:       "completed mission is final",
+			from:       missions.MissionCompleted,
+			targets:    []missions.TargetStatus{missions.TargetCompleted},
+			to:         missions.MissionFailed,
+			wantErr:    missions.ErrMissionComplete,
+			wantStatus: missions.MissionCompleted,
+		},
+		{
+			name:       "same status is a no-op",
+			from:       missions.MissionActive,
+			targets:    []missions.TargetStatus{missions.TargetPending},
+			to:         missions.MissionActive,
+			wantStatus: missions.MissionActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			missionID, _ := repo.seed(tt.from, tt.targets...)
+			if tt.unassigned {
+				m := repo.missions[missionID]
+				m.CatID = nil
+				repo.missions[missionID] = m
+			}
+			svc := missions.NewService(repo, nil, ranks)
+
+			mission, err := svc.TransitionMission(missionID, tt.to)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, mission.Status)
+			}
+
+			stored, _ := repo.GetMissionByID(missionID)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+			assert.Equal(t, tt.wantStatus == missions.MissionCompleted, stored.IsComplete)
+		})
+	}
+
+	t.Run("records each change", func(t *testing.T) {
+		repo := newFakeRepo()
+		missionID, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+		svc := missions.NewService(repo, nil, ranks)
+
+		for _, to := range []missions.MissionStatus{missions.MissionActive, missions.MissionOnHold, missions.MissionActive} {
+			_, err := svc.TransitionMission(missionID, to)
+			require.NoError(t, err)
+		}
+
+		changes, err := svc.GetMissionTransitions(missionID)
+		require.NoError(t, err)
+		catID := int64(1)
+		assert.Equal(t, []missions.StatusChange{
+			{To: missions.MissionPlanned, ToCatID: &catID},
+			{From: missions.MissionPlanned, To: missions.MissionActive},
+			{From: missions.MissionActive, To: missions.MissionOnHold},
+			{From: missions.MissionOnHold, To: missions.MissionActive},
+		}, changes)
+	})
+
+	t.Run("unknown mission", func(t *testing.T) {
+		_, err := missions.NewService(newFakeRepo(), nil, ranks).TransitionMission(42, missions.MissionActive)
+		assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+	})
+}
+
+func TestServiceUpdateTargetStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		mission           missions.MissionStatus
+		targets           []missions.TargetStatus
+		req               missions.UpdateTargetRequest
+		wantErr           error
+		wantStatus        missions.TargetStatus
+		wantMissionStatus missions.MissionStatus
+	}{
+		{
+			name:              "fails target",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetPending, missions.TargetPending},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetFailed)},
+			wantStatus:        missions.TargetFailed,
+			wantMissionStatus: missions.MissionActive,
+		},
+		{
+			name:              "mission fails when every target failed",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetPending, missions.TargetFailed},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetFailed)},
+			wantStatus:        missions.TargetFailed,
+			wantMissionStatus: missions.MissionFailed,
+		},
+		{
+			name:              "mission completes when the last target closes with any completed",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetPending, missions.TargetCompleted},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetFailed)},
+			wantStatus:        missions.TargetFailed,
+			wantMissionStatus: missions.MissionCompleted,
+		},
+		{
+			name:              "targets of planned missions cannot change status",
+			mission:           missions.MissionPlanned,
+			targets:           []missions.TargetStatus{missions.TargetPending},
+			req:               missions.UpdateTargetRequest{IsComplete: utils.Some(true)},
+			wantErr:           missions.ErrInvalidTransition,
+			wantStatus:        missions.TargetPending,
+			wantMissionStatus: missions.MissionPlanned,
+		},
+		{
+			name:              "targets of missions on hold cannot change status",
+			mission:           missions.MissionOnHold,
+			targets:           []missions.TargetStatus{missions.TargetPending},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetCompleted)},
+			wantErr:           missions.ErrInvalidTransition,
+			wantStatus:        missions.TargetPending,
+			wantMissionStatus: missions.MissionOnHold,
+		},
+		{
+			name:              "failed target cannot be completed",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetFailed, missions.TargetPending},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetCompleted)},
+			wantErr:           missions.ErrInvalidTransition,
+			wantStatus:        missions.TargetFailed,
+			wantMissionStatus: missions.MissionActive,
+		},
+		{
+			name:              "is_complete contradicting status is rejected",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetPending},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetFailed), IsComplete: utils.Some(true)},
+			wantErr:           missions.ErrInvalidPatch,
+			wantStatus:        missions.TargetPending,
+			wantMissionStatus: missions.MissionActive,
+		},
+		{
+			name:              "unknown status is rejected",
+			mission:           missions.MissionActive,
+			targets:           []missions.TargetStatus{missions.TargetPending},
+			req:               missions.UpdateTargetRequest{Status: utils.Some(missions.TargetStatus("lost"))},
+			wantErr:           missions.ErrInvalidPatch,
+			wantStatus:        missions.TargetPending,
+			wantMissionStatus: missions.MissionActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			missionID, targetIDs := repo.seed(tt.mission, tt.targets...)
+			svc := missions.NewService(repo, nil, ranks)
+
+			_, err := svc.UpdateTarget(targetIDs[0], tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			target, _ := repo.GetTarget(targetIDs[0])
+			assert.Equal(t, tt.wantStatus, target.Status)
+			mission, _ := repo.GetMissionByID(missionID)
+			assert.Equal(t, tt.wantMissionStatus, mission.Status)
+		})
+	}
+}
+
+func TestServiceRestoreMission(t *testing.T) {
+	repo := newFakeRepo()
+	svc := missions.NewService(repo, nil, ranks)
+
+	missionID, _ := repo.CreateMission(missions.Mission{Name: "Operation Stealth", Status: missions.MissionPlanned})
+	kept, _ := repo.CreateTarget(missions.Target{MissionID: missionID, Name: "Agent Smith", Status: missions.TargetPending})
+	dropped, _ := repo.CreateTarget(missions.Target{MissionID: missionID, Name: "Agent Jones", Status: missions.TargetPending})
+
+	require.NoError(t, svc.DeleteTarget(dropped))
+	_, err := svc.DeleteMission(missionID, false)
+	require.NoError(t, err)
+
+	_, err = svc.GetTarget(kept)
+	assert.ErrorIs(t, err, missions.ErrTargetNotFound)
+	_, err = svc.GetMissionTargets(missionID)
+	assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+
+	// the target deleted on its own stays deleted
+	_, err = svc.RestoreTarget(dropped)
+	assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+
+	restored, err := svc.RestoreMission(missionID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	require.Len(t, restored.Targets, 1)
+	assert.Equal(t, kept, restored.Targets[0].ID)
+
+	_, err = svc.RestoreMission(missionID)
+	assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+
+	target, err := svc.RestoreTarget(dropped)
+	require.NoError(t, err)
+	assert.Nil(t, target.DeletedAt)
+	n, _ := repo.CountTargets(missionID)
+	assert.Equal(t, 2, n)
+}
+
+func TestServiceRestoreTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  missions.MissionStatus
+		targets []missions.TargetStatus
+		wantErr error
+	}{
+		{
+			name:    "restores into open mission",
+			status:  missions.MissionActive,
+			targets: []missions.TargetStatus{missions.TargetPending, missions.TargetPending},
+		},
+		{
+			name:    "mission full",
+			status:  missions.MissionActive,
+			targets: []missions.TargetStatus{missions.TargetPending, missions.TargetPending, missions.TargetPending, missions.TargetPending},
+			wantErr: missions.ErrTargetLimit,
+		},
+		{
+			name:    "mission completed",
+			status:  missions.MissionCompleted,
+			targets: []missions.TargetStatus{missions.TargetPending, missions.TargetCompleted},
+			wantErr: missions.ErrMissionComplete,
+		},
+		{
+			name:    "mission aborted",
+			status:  missions.MissionAborted,
+			targets: []missions.TargetStatus{missions.TargetPending, missions.TargetPending},
+			wantErr: missions.ErrMissionClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			svc := missions.NewService(repo, nil, ranks)
+			_, ids := repo.seed(tt.status, tt.targets...)
+			require.NoError(t, repo.DeleteTarget(ids[0]))
+
+			target, err := svc.RestoreTarget(ids[0])
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				_, err := repo.GetDeletedTarget(ids[0])
+				assert.NoError(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ids[0], target.ID)
+		})
+	}
+
+	t.Run("not deleted", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		_, ids := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		_, err := svc.RestoreTarget(ids[0])
+		assert.ErrorIs(t, err, missions.ErrTargetNotFound)
+	})
+}
+
+func TestServiceDeleteMission(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   missions.MissionStatus
+		unassign bool
+		wantErr  error
+	}{
+		{
+			name:    "assigned mission needs unassign",
+			status:  missions.MissionPlanned,
+			wantErr: missions.ErrMissionAssigned,
+		},
+		{
+			name:     "unassigns and deletes planned mission",
+			status:   missions.MissionPlanned,
+			unassign: true,
+		},
+		{
+			name:     "unassigns and deletes completed mission",
+			status:   missions.MissionCompleted,
+			unassign: true,
+		},
+		{
+			name:     "active mission cannot be deleted",
+			status:   missions.MissionActive,
+			unassign: true,
+			wantErr:  missions.ErrMissionInProgress,
+		},
+		{
+			name:     "on hold mission cannot be deleted",
+			status:   missions.MissionOnHold,
+			unassign: true,
+			wantErr:  missions.ErrMissionInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			svc := missions.NewService(repo, nil, ranks)
+			missionID, _ := repo.seed(tt.status, missions.TargetPending, missions.TargetPending)
+
+			summary, err := svc.DeleteMission(missionID, tt.unassign)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				m, err := repo.GetMissionByID(missionID)
+				require.NoError(t, err)
+				assert.NotNil(t, m.CatID)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, missionID, summary.ID)
+			assert.Equal(t, tt.status, summary.Status)
+			assert.Equal(t, 2, summary.DeletedTargets)
+			require.NotNil(t, summary.UnassignedCatID)
+			assert.Equal(t, int64(1), *summary.UnassignedCatID)
+			assert.False(t, summary.DeletedAt.IsZero())
+
+			_, err = repo.GetMissionByID(missionID)
+			assert.ErrorIs(t, err, sql.ErrNoRows)
+			assert.Nil(t, repo.missions[missionID].CatID)
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		svc := missions.NewService(newFakeRepo(), nil, ranks)
+		_, err := svc.DeleteMission(42, true)
+		assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+	})
+}
+
+func TestServiceAssignment(t *testing.T) {
+	catID, otherCat := int64(1), int64(2)
+
+	t.Run("assigns unassigned mission", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[otherCat] = true
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.CreateMission(missions.Mission{Name: "Operation Stealth", Status: missions.MissionPlanned})
+
+		require.NoError(t, svc.AssignCat(missionID, otherCat))
+		assert.Equal(t, &otherCat, repo.missions[missionID].CatID)
+	})
+
+	t.Run("assign rejects mission with another cat", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[otherCat] = true
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+
+		assert.ErrorIs(t, svc.AssignCat(missionID, otherCat), missions.ErrMissionAssigned)
+		assert.NoError(t, svc.AssignCat(missionID, catID))
+		assert.Equal(t, &catID, repo.missions[missionID].CatID)
+	})
+
+	t.Run("reassign swaps cats and records it", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[otherCat] = true
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		mission, err := svc.ReassignCat(missionID, otherCat)
+		require.NoError(t, err)
+		assert.Equal(t, &otherCat, mission.CatID)
+		assert.Equal(t, missions.MissionActive, mission.Status)
+
+		changes, _ := svc.GetMissionTransitions(missionID)
+		assert.Equal(t, missions.StatusChange{
+			From: missions.MissionActive, To: missions.MissionActive, FromCatID: &catID, ToCatID: &otherCat,
+		}, changes[len(changes)-1])
+	})
+
+	t.Run("reassign needs an assigned mission", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[otherCat] = true
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.CreateMission(missions.Mission{Name: "Operation Stealth", Status: missions.MissionPlanned})
+
+		_, err := svc.ReassignCat(missionID, otherCat)
+		assert.ErrorIs(t, err, missions.ErrMissionUnassigned)
+	})
+
+	t.Run("reassign to busy cat", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+		repo.cats[otherCat] = true
+		repo.CreateMission(missions.Mission{Name: "Operation Night", CatID: &otherCat, Status: missions.MissionPlanned})
+
+		_, err := svc.ReassignCat(missionID, otherCat)
+		assert.ErrorIs(t, err, missions.ErrCatBusy)
+		assert.Equal(t, &catID, repo.missions[missionID].CatID)
+	})
+
+	t.Run("unassigning active mission puts it on hold", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		mission, err := svc.UnassignCat(missionID)
+		require.NoError(t, err)
+		assert.Nil(t, mission.CatID)
+		assert.Equal(t, missions.MissionOnHold, mission.Status)
+
+		changes, _ := svc.GetMissionTransitions(missionID)
+		assert.Equal(t, []missions.StatusChange{
+			{To: missions.MissionActive, ToCatID: &catID},
+			{From: missions.MissionActive, To: missions.MissionOnHold},
+			{From: missions.MissionOnHold, To: missions.MissionOnHold, FromCatID: &catID},
+		}, changes)
+
+		_, err = svc.TransitionMission(missionID, missions.MissionActive)
+		assert.ErrorIs(t, err, missions.ErrInvalidTransition)
+	})
+
+	t.Run("unassigning planned mission keeps its status", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+
+		mission, err := svc.UnassignCat(missionID)
+		require.NoError(t, err)
+		assert.Nil(t, mission.CatID)
+		assert.Equal(t, missions.MissionPlanned, mission.Status)
+
+		_, err = svc.UnassignCat(missionID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("closed missions keep their cat", func(t *testing.T) {
+		for status, wantErr := range map[missions.MissionStatus]error{
+			missions.MissionCompleted: missions.ErrMissionComplete,
+			missions.MissionAborted:   missions.ErrMissionClosed,
+		} {
+			repo := newFakeRepo()
+			svc := missions.NewService(repo, nil, ranks)
+			missionID, _ := repo.seed(status, missions.TargetCompleted)
+
+			_, err := svc.UnassignCat(missionID)
+			assert.ErrorIs(t, err, wantErr)
+			assert.Equal(t, &catID, repo.missions[missionID].CatID)
+		}
+	})
+}
+
+func TestServiceMissionAssignments(t *testing.T) {
+	catID, otherCat := int64(1), int64(2)
+
+	reasons := func(as []missions.Assignment) []missions.ReleaseReason {
+		var rs []missions.ReleaseReason
+		for _, a := range as {
+			rs = append(rs, a.Reason)
+		}
+		return rs
+	}
+
+	t.Run("reassign then complete", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[otherCat] = true
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		_, err := svc.ReassignCat(missionID, otherCat)
+		require.NoError(t, err)
+		require.NoError(t, svc.MarkMissionComplete(missionID, true))
+
+		as, err := svc.GetMissionAssignments(missionID)
+		require.NoError(t, err)
+		require.Len(t, as, 2)
+		assert.Equal(t, &catID, as[0].CatID)
+		assert.Equal(t, &otherCat, as[1].CatID)
+		assert.Equal(t, []missions.ReleaseReason{missions.ReleaseReassigned, missions.ReleaseCompleted}, reasons(as))
+		assert.NotNil(t, as[1].ReleasedAt)
+	})
+
+	t.Run("unassign and abort", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+
+		_, err := svc.UnassignCat(missionID)
+		require.NoError(t, err)
+		require.NoError(t, svc.AssignCat(missionID, catID))
+		_, err = svc.TransitionMission(missionID, missions.MissionAborted)
+		require.NoError(t, err)
+
+		as, _ := svc.GetMissionAssignments(missionID)
+		assert.Equal(t, []missions.ReleaseReason{missions.ReleaseUnassigned, missions.ReleaseAborted}, reasons(as))
+	})
+
+	t.Run("delete releases the cat", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+
+		_, err := svc.DeleteMission(missionID, true)
+		require.NoError(t, err)
+
+		as, _ := repo.ListAssignments(missionID)
+		assert.Equal(t, []missions.ReleaseReason{missions.ReleaseMissionDeleted}, reasons(as))
+	})
+
+	t.Run("mission created closed", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[catID] = true
+		svc := missions.NewService(repo, nil, ranks)
+
+		mission, err := svc.CreateMission(missions.CreateMissionRequest{
+			CatID:      &catID,
+			Name:       "Operation Stealth",
+			Targets:    []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia", IsComplete: true}},
+			IsComplete: true,
+		})
+		require.NoError(t, err)
+
+		as, _ := svc.GetMissionAssignments(mission.ID)
+		assert.Equal(t, []missions.ReleaseReason{missions.ReleaseCompleted}, reasons(as))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc := missions.NewService(newFakeRepo(), nil, ranks)
+		_, err := svc.GetMissionAssignments(42)
+		assert.ErrorIs(t, err, missions.ErrMissionNotFound)
+	})
+}
+
+// notifier counts notifications
+type notifier int
+
+func (n *notifier) Notify() { *n++ }
+
+func TestServiceCompletionEvents(t *testing.T) {
+	catID := int64(1)
+
+	t.Run("targets then mission", func(t *testing.T) {
+		repo := newFakeRepo()
+		var n notifier
+		svc := missions.NewService(repo, &n, ranks)
+		missionID, ids := repo.seed(missions.MissionActive, missions.TargetPending, missions.TargetPending)
+
+		_, err := svc.UpdateTarget(ids[0], missions.UpdateTargetRequest{Status: utils.Optional[missions.TargetStatus]{Present: true, Value: missions.TargetFailed}})
+		require.NoError(t, err)
+		_, err = svc.UpdateTarget(ids[1], missions.UpdateTargetRequest{Status: utils.Optional[missions.TargetStatus]{Present: true, Value: missions.TargetCompleted}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []event{
+			{Type: missions.EventTargetCompleted, MissionID: missionID, TargetID: &ids[1], CatID: catID},
+			{Type: missions.EventMissionCompleted, MissionID: missionID, CatID: catID},
+		}, repo.events)
+		assert.Equal(t, notifier(2), n)
+	})
+
+	t.Run("forced completion", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, ids := repo.seed(missions.MissionActive, missions.TargetCompleted, missions.TargetPending)
+
+		require.NoError(t, svc.MarkMissionComplete(missionID, true))
+		assert.Equal(t, []event{
+			{Type: missions.EventTargetCompleted, MissionID: missionID, TargetID: &ids[1], CatID: catID},
+			{Type: missions.EventMissionCompleted, MissionID: missionID, CatID: catID},
+		}, repo.events)
+	})
+
+	t.Run("failed mission", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		_, err := svc.TransitionMission(missionID, missions.MissionFailed)
+		require.NoError(t, err)
+		assert.Equal(t, []event{{Type: missions.EventMissionFailed, MissionID: missionID, CatID: catID}}, repo.events)
+	})
+
+	t.Run("mission created complete", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[catID] = true
+		var n notifier
+		svc := missions.NewService(repo, &n, ranks)
+
+		mission, err := svc.CreateMission(missions.CreateMissionRequest{
+			CatID:      &catID,
+			Name:       "Operation Past",
+			Targets:    []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia", IsComplete: true}},
+			IsComplete: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, repo.events, 2)
+		assert.Equal(t, missions.EventTargetCompleted, repo.events[0].Type)
+		assert.Equal(t, event{Type: missions.EventMissionCompleted, MissionID: mission.ID, CatID: catID}, repo.events[1])
+		assert.Equal(t, notifier(1), n)
+	})
+
+	t.Run("create rejects target status contradicting the mission", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.cats[catID] = true
+		svc := missions.NewService(repo, nil, ranks)
+
+		_, err := svc.CreateMission(missions.CreateMissionRequest{
+			CatID:      &catID,
+			Name:       "Operation Past",
+			Targets:    []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia", IsComplete: true}, {Name: "Agent Jones", Country: "France"}},
+			IsComplete: true,
+		})
+		assert.ErrorIs(t, err, missions.ErrOpenTargets)
+
+		_, err = svc.CreateMission(missions.CreateMissionRequest{
+			CatID:   &catID,
+			Name:    "Operation Stealth",
+			Targets: []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia", IsComplete: true}},
+		})
+		assert.ErrorIs(t, err, missions.ErrInvalidTransition)
+		assert.Empty(t, repo.missions)
+		assert.Empty(t, repo.events)
+	})
+
+	t.Run("deleting or moving the last pending target", func(t *testing.T) {
+		repo := newFakeRepo()
+		var n notifier
+		svc := missions.NewService(repo, &n, ranks)
+		deleted, deletedTargets := repo.seed(missions.MissionActive, missions.TargetPending, missions.TargetCompleted)
+		moved, movedTargets := repo.seed(missions.MissionActive, missions.TargetPending, missions.TargetCompleted)
+		dest, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		require.NoError(t, svc.DeleteTarget(deletedTargets[0]))
+		assert.Equal(t, notifier(1), n)
+		_, err := svc.MoveTarget(movedTargets[0], dest)
+		require.NoError(t, err)
+		assert.Equal(t, notifier(2), n)
+
+		assert.Equal(t, []event{
+			{Type: missions.EventMissionCompleted, MissionID: deleted, CatID: catID},
+			{Type: missions.EventMissionCompleted, MissionID: moved, CatID: catID},
+		}, repo.events)
+	})
+
+	t.Run("aborted and rolled back missions record nothing", func(t *testing.T) {
+		repo := newFakeRepo()
+		var n notifier
+		svc := missions.NewService(repo, &n, ranks)
+		aborted, _ := repo.seed(missions.MissionPlanned, missions.TargetPending)
+		open, _ := repo.seed(missions.MissionActive, missions.TargetPending)
+
+		_, err := svc.TransitionMission(aborted, missions.MissionAborted)
+		require.NoError(t, err)
+		assert.ErrorIs(t, svc.MarkMissionComplete(open, false), missions.ErrOpenTargets)
+
+		assert.Empty(t, repo.events)
+		assert.Equal(t, notifier(1), n)
+	})
+}
+
+func TestServiceRankLimits(t *testing.T) {
+	recruit, senior := int64(2), int64(3)
+	newRepo := func() *fakeRepo {
+		repo := newFakeRepo()
+		repo.cats[recruit], repo.cats[senior] = true, true
+		repo.records[recruit] = catRecord{years: 1, completed: 0}
+		repo.records[senior] = catRecord{years: 5, completed: 10}
+		return repo
+	}
+	// unassigned stores a planned mission without a cat with n pending targets
+	unassigned := func(repo *fakeRepo, n int) int64 {
+		missionID, _ := repo.CreateMission(missions.Mission{Name: "Operation Stealth", Status: missions.MissionPlanned})
+		for range n {
+			repo.CreateTarget(missions.Target{MissionID: missionID, Name: "Agent Smith", Country: "Russia", Status: missions.TargetPending})
+		}
+		return missionID
+	}
+	targets := func(n int) []missions.CreateTarget {
+		ts := make([]missions.CreateTarget, n)
+		for i := range ts {
+			ts[i] = missions.CreateTarget{Name: "Agent Smith", Country: "Russia"}
+		}
+		return ts
+	}
+
+	t.Run("assign needs a rank that allows the targets", func(t *testing.T) {
+		repo := newRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID := unassigned(repo, 3)
+
+		assert.ErrorIs(t, svc.AssignCat(missionID, recruit), missions.ErrRankTooLow)
+		assert.Nil(t, repo.missions[missionID].CatID)
+		require.NoError(t, svc.AssignCat(missionID, senior))
+		assert.Equal(t, &senior, repo.missions[missionID].CatID)
+	})
+
+	t.Run("recruits take missions of two targets", func(t *testing.T) {
+		repo := newRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID := unassigned(repo, 2)
+
+		require.NoError(t, svc.AssignCat(missionID, recruit))
+
+		_, err := svc.AddTarget(missionID, missions.CreateTarget{Name: "Agent Jones", Country: "France"})
+		assert.ErrorIs(t, err, missions.ErrRankTooLow)
+		assert.Len(t, repo.targetsOf(missionID), 2)
+	})
+
+	t.Run("reassign checks the new cat", func(t *testing.T) {
+		repo := newRepo()
+		svc := missions.NewService(repo, nil, ranks)
+		missionID, _ := repo.seed(missions.MissionActive, missions.TargetPending, missions.TargetPending, missions.TargetPending)
+
+		_, err := svc.ReassignCat(missionID, recruit)
+		assert.ErrorIs(t, err, missions.ErrRankTooLow)
+		assert.Equal(t, int64(1), *repo.missions[missionID].CatID)
+	})
+
+	t.Run("create checks the cat unless the mission is complete", func(t *testing.T) {
+		repo := newRepo()
+		svc := missions.NewService(repo, nil, ranks)
+
+		_, err := svc.CreateMission(missions.CreateMissionRequest{CatID: &recruit, Name: "Operation Stealth", Targets: targets(3)})
+		assert.ErrorIs(t, err, missions.ErrRankTooLow)
+		assert.Empty(t, repo.missions)
+
+		done := targets(3)
+		for i := range done {
+			done[i].IsComplete = true
+		}
+		_, err = svc.CreateMission(missions.CreateMissionRequest{CatID: &recruit, Name: "Operation Past", Targets: done, IsComplete: true})
+		assert.NoError(t, err)
+	})
+}
+
+func TestServiceCreateMissionDossier(t *testing.T) {
+	today := time.Now().UTC()
+	date := func(days int) string { return today.AddDate(0, 0, days).Format(time.DateOnly) }
+	target := []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia"}}
+
+	t.Run("stores the dossier with medium priority by default", func(t *testing.T) {
+		svc := missions.NewService(newFakeRepo(), nil, ranks)
+		start, deadline := date(1), date(30)
+
+		mission, err := svc.CreateMission(missions.CreateMissionRequest{
+			Name:        "Operation Embassy",
+			Description: "Recover the stolen plans",
+			StartDate:   start,
+			Deadline:    deadline,
+			Targets:     target,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Recover the stolen plans", mission.Description)
+		assert.Equal(t, missions.PriorityMedium, mission.Priority)
+		assert.Equal(t, &start, mission.StartDate)
+		assert.Equal(t, &deadline, mission.Deadline)
+	})
+
+	for name, tt := range map[string]struct {
+		req     missions.CreateMissionRequest
+		wantErr error
+	}{
+		"deadline before start date": {
+			req:     missions.CreateMissionRequest{StartDate: date(10), Deadline: date(5)},
+			wantErr: missions.ErrInvalidSchedule,
+		},
+		"deadline in the past": {
+			req:     missions.CreateMissionRequest{Deadline: date(-1)},
+			wantErr: missions.ErrInvalidSchedule,
+		},
+		"deadline today": {
+			req: missions.CreateMissionRequest{Deadline: date(0)},
+		},
+		"past deadline of a complete mission": {
+			req: missions.CreateMissionRequest{StartDate: date(-30), Deadline: date(-1), IsComplete: true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := newFakeRepo()
+			svc := missions.NewService(repo, nil, ranks)
+			tt.req.Name, tt.req.Targets = "Operation Stealth", target
+			if tt.req.IsComplete {
+				tt.req.Targets = []missions.CreateTarget{{Name: "Agent Smith", Country: "Russia", IsComplete: true}}
+			}
+
+			_, err := svc.CreateMission(tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, repo.missions)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestServiceGetAllMissionsSort(t *testing.T) {
+	svc := missions.NewService(newFakeRepo(), nil, ranks)
+
+	t.Run("deadline range must not be reversed", func(t *testing.T) {
+		_, err := svc.GetAllMissions(missions.ListMissionsQuery{DeadlineAfter: "2024-06-30", DeadlineBefore: "2024-05-01"})
+		assert.ErrorIs(t, err, missions.ErrInvalidQuery)
+	})
+
+	t.Run("cursor must match the sort", func(t *testing.T) {
+		cursor := utils.EncodeCursor(utils.Cursor{Sort: "id", Value: "3", ID: 3})
+		_, err := svc.GetAllMissions(missions.ListMissionsQuery{Sort: "-priority", Cursor: cursor})
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+
+		_, err = svc.GetAllMissions(missions.ListMissionsQuery{Cursor: cursor})
+		assert.NoError(t, err)
+	})
+}
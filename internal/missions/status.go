@@ -0,0 +1,101 @@
+package missions
+
+import (
+	"slices"
+	"time"
+)
+
+// MissionStatus is the lifecycle state of a mission
+type MissionStatus string
+
+const (
+	MissionPlanned   MissionStatus = "planned"
+	MissionActive    MissionStatus = "active"
+	MissionOnHold    MissionStatus = "on_hold"
+	MissionCompleted MissionStatus = "completed"
+	MissionAborted   MissionStatus = "aborted"
+	MissionFailed    MissionStatus = "failed"
+)
+
+// missionTransitions lists the statuses a mission may move to from each
+// status. Completed, aborted and failed missions are closed for good.
+var missionTransitions = map[MissionStatus][]MissionStatus{
+	MissionPlanned: {MissionActive, MissionAborted},
+	MissionActive:  {MissionOnHold, MissionCompleted, MissionAborted, MissionFailed},
+	MissionOnHold:  {MissionActive, MissionAborted},
+}
+
+// Closed reports whether the mission has reached a final status
+func (s MissionStatus) Closed() bool {
+	return s == MissionCompleted || s == MissionAborted || s == MissionFailed
+}
+
+func (s MissionStatus) CanTransitionTo(next MissionStatus) bool {
+	return slices.Contains(missionTransitions[s], next)
+}
+
+// TargetStatus is the lifecycle state of a target
+type TargetStatus string
+
+const (
+	TargetPending   TargetStatus = "pending"
+	TargetCompleted TargetStatus = "completed"
+	TargetFailed    TargetStatus = "failed"
+)
+
+// targetTransitions lists the statuses a target may move to from each
+// status. Completed and failed targets are closed for good.
+var targetTransitions = map[TargetStatus][]TargetStatus{
+	TargetPending: {TargetCompleted, TargetFailed},
+}
+
+// Closed reports whether the target has reached a final status
+func (s TargetStatus) Closed() bool {
+	return s == TargetCompleted || s == TargetFailed
+}
+
+func (s TargetStatus) CanTransitionTo(next TargetStatus) bool {
+	return slices.Contains(targetTransitions[s], next)
+}
+
+// ReleaseReason tells why a cat's assignment to a mission ended
+type ReleaseReason string
+
+const (
+	ReleaseUnassigned     ReleaseReason = "unassigned"
+	ReleaseReassigned     ReleaseReason = "reassigned"
+	ReleaseCompleted      ReleaseReason = "completed"
+	ReleaseAborted        ReleaseReason = "aborted"
+	ReleaseFailed         ReleaseReason = "failed"
+	ReleaseMissionDeleted ReleaseReason = "mission_deleted"
+	ReleaseCatDeleted     ReleaseReason = "cat_deleted"
+)
+
+// StatusChange is one entry of a mission's history. From is empty for the
+// status the mission was created with. Entries that assign, release or swap
+// the cat carry FromCatID and ToCatID; From equals To when only the cat
+// changed.
+type StatusChange struct {
+	From      MissionStatus `json:"from,omitempty" example:"planned"`
+	To        MissionStatus `json:"to" example:"active"`
+	FromCatID *int64        `json:"from_cat_id,omitempty" example:"5"`
+	ToCatID   *int64        `json:"to_cat_id,omitempty" example:"7"`
+	ChangedAt time.Time     `json:"changed_at" example:"2024-05-01T12:00:00Z"`
+}
+
+// EventType is the kind of a completion event. Completion events are
+// recorded in the transaction that completes a mission or target worked by
+// a cat, for the scoring of cats.
+type EventType string
+
+const (
+	EventMissionCompleted EventType = "mission_completed"
+	EventMissionFailed    EventType = "mission_failed"
+	EventTargetCompleted  EventType = "target_completed"
+)
+
+// Notifier is told when completion events have been committed so they can
+// be consumed without waiting for the next poll
+type Notifier interface {
+	Notify()
+}
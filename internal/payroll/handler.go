@@ -0,0 +1,83 @@
+package payroll
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayrollService interface {
+	CreateRun(req CreateRunRequest) (*Run, error)
+	GetRun(id int64) (*Run, error)
+}
+
+type Handler struct {
+	service PayrollService
+}
+
+func NewHandler(service PayrollService) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateRun runs payroll for a pay period
+// @Summary      Run payroll
+// @Description  Pay every cat that is not deleted and is paid in the run's currency for a monthly or biweekly pay period: the yearly salary prorated to the period, a bonus per mission completed in the period, minus deductions. The currency defaults to DEFAULT_CURRENCY. Only periods that ended before today (UTC) can be run. Runs are immutable and a period that overlaps an earlier run in the same currency cannot be run again.
+// @Tags         payroll
+// @Accept       json
+// @Produce      json
+// @Param        run  body      CreateRunRequest  true  "Pay period"
+// @Success      201  {object}  Run               "Payroll run with its line items"
+// @Header       201  {string}  Location          "URL of the payroll run"
+// @Failure      400  {object}  map[string]string "Invalid input, pay period or currency"
+// @Failure      409  {object}  map[string]string "Pay period already run"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /payroll/runs [post]
+func (h *Handler) CreateRun(c *gin.Context) {
+	var req CreateRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run, err := h.service.CreateRun(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPeriod), errors.Is(err, ErrInvalidCurrency):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrPeriodClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to run payroll"})
+		}
+		return
+	}
+
+	c.Header("Location", c.FullPath()+"/"+strconv.FormatInt(run.ID, 10))
+	c.JSON(http.StatusCreated, run)
+}
+
+// GetRun retrieves a payroll run by ID
+// @Summary      Get a payroll run
+// @Description  Get a payroll run with the line item of every cat it paid
+// @Tags         payroll
+// @Produce      json
+// @Param        id   path      int  true  "Payroll run ID"
+// @Success      200  {object}  Run               "Payroll run"
+// @Failure      404  {object}  map[string]string "Payroll run not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /payroll/runs/{id} [get]
+func (h *Handler) GetRun(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	run, err := h.service.GetRun(id)
+	if err != nil {
+		if errors.Is(err, ErrRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "payroll run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get payroll run"})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
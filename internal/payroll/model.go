@@ -0,0 +1,72 @@
+package payroll
+
+import (
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+// Frequency is how often cats are paid; it sets the length of a pay period
+type Frequency string
+
+const (
+	Monthly  Frequency = "monthly"
+	Biweekly Frequency = "biweekly"
+)
+
+// Run is a payroll run over one pay period for the cats paid in Currency.
+// Runs are immutable once created. PeriodStart and PeriodEnd are the first
+// and last day paid, and all amounts are in Currency.
+type Run struct {
+	ID              int64        `json:"id" example:"1"`
+	Frequency       Frequency    `json:"frequency" enums:"monthly,biweekly" example:"monthly"`
+	PeriodStart     string       `json:"period_start" example:"2024-05-01"`
+	PeriodEnd       string       `json:"period_end" example:"2024-05-31"`
+	Currency        string       `json:"currency" example:"USD"`
+	TotalGross      utils.Amount `json:"total_gross" swaggertype:"string" example:"5666.67"`
+	TotalDeductions utils.Amount `json:"total_deductions" swaggertype:"string" example:"0.00"`
+	TotalNet        utils.Amount `json:"total_net" swaggertype:"string" example:"5666.67"`
+	CreatedAt       time.Time    `json:"created_at" example:"2024-06-01T09:00:00Z"`
+	Items           []LineItem   `json:"items"`
+}
+
+// LineItem is the pay of one cat in a run. BaseSalary is the cat's yearly
+// salary prorated to the pay period, by day if it changed during the period,
+// and MissionsCompleted counts the missions the cat completed on days it was
+// paid in the run's currency.
+type LineItem struct {
+	CatID             int64        `json:"cat_id" example:"1"`
+	CatName           string       `json:"cat_name" example:"Whiskers"`
+	BaseSalary        utils.Amount `json:"base_salary" swaggertype:"string" example:"4166.67"`
+	MissionsCompleted int          `json:"missions_completed" example:"3"`
+	MissionBonus      utils.Amount `json:"mission_bonus" swaggertype:"string" example:"1500.00"`
+	Deductions        utils.Amount `json:"deductions" swaggertype:"string" example:"0.00"`
+	NetPay            utils.Amount `json:"net_pay" swaggertype:"string" example:"5666.67"`
+}
+
+// CreateRunRequest represents the request to run payroll for a pay period.
+// Monthly periods start on the first day of a month. A run pays the cats
+// whose salary is in Currency, the default currency if it is omitted.
+type CreateRunRequest struct {
+	Frequency   Frequency `json:"frequency" binding:"required,oneof=monthly biweekly" enums:"monthly,biweekly" example:"monthly"`
+	PeriodStart string    `json:"period_start" binding:"required,datetime=2006-01-02" example:"2024-05-01"`
+	Currency    string    `json:"currency" binding:"omitempty,len=3" example:"USD"`
+}
+
+// PayableCat is a cat that may be paid in a run. Salary is its current
+// salary and Changes are the salary changes applied to it that took effect
+// after the start of the period, in effective date order.
+type PayableCat struct {
+	ID      int64
+	Name    string
+	Salary  utils.Money
+	Changes []AppliedSalaryChange
+}
+
+// AppliedSalaryChange replaced the Old salary of a cat with New from
+// EffectiveDate, a YYYY-MM-DD date
+type AppliedSalaryChange struct {
+	EffectiveDate string
+	Old           utils.Money
+	New           utils.Money
+}
@@ -0,0 +1,232 @@
+package payroll_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"spy-cats/internal/payroll"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) CreateRun(req payroll.CreateRunRequest) (*payroll.Run, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payroll.Run), args.Error(1)
+}
+
+func (m *mockService) GetRun(id int64) (*payroll.Run, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payroll.Run), args.Error(1)
+}
+
+func TestCreateRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := &payroll.Run{
+		ID:          3,
+		Frequency:   payroll.Monthly,
+		PeriodStart: "2024-05-01",
+		PeriodEnd:   "2024-05-31",
+		TotalGross:  4666_67,
+		TotalNet:    4666_67,
+		Items: []payroll.LineItem{
+			{CatID: 1, CatName: "Whiskers", BaseSalary: 4166_67, MissionsCompleted: 1, MissionBonus: 500_00, NetPay: 4666_67},
+		},
+	}
+	tests := []struct {
+		name             string
+		body             any
+		callsService     bool
+		mockReturnRun    *payroll.Run
+		mockReturnErr    error
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:             "success",
+			body:             payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"},
+			callsService:     true,
+			mockReturnRun:    run,
+			expectedStatus:   http.StatusCreated,
+			expectedBody:     `"items":[{"cat_id":1,"cat_name":"Whiskers","base_salary":"4166.67","missions_completed":1,"mission_bonus":"500.00","deductions":"0.00","net_pay":"4666.67"}]`,
+			expectedLocation: "/payroll/runs/3",
+		},
+		{
+			name:           "missing frequency",
+			body:           `{"period_start": "2024-05-01"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "unknown frequency",
+			body:           `{"frequency": "weekly", "period_start": "2024-05-01"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "malformed period start",
+			body:           `{"frequency": "monthly", "period_start": "May 2024"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "invalid period",
+			body:           payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-15"},
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: monthly periods start on the first day of a month", payroll.ErrInvalidPeriod),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid pay period: monthly periods start on the first day of a month"`,
+		},
+		{
+			name:           "invalid currency",
+			body:           payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "E1R"},
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: %q is not an ISO 4217 currency code with at most 2 decimal places", payroll.ErrInvalidCurrency, "E1R"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid currency`,
+		},
+		{
+			name:           "period closed",
+			body:           payroll.CreateRunRequest{Frequency: payroll.Biweekly, PeriodStart: "2024-05-20"},
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w: 2024-05-20 to 2024-06-02 overlaps payroll run 3", payroll.ErrPeriodClosed),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `overlaps payroll run 3`,
+		},
+		{
+			name:           "service error",
+			body:           payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"},
+			callsService:   true,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to run payroll"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := payroll.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.POST("/payroll/runs", h.CreateRun)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			if tt.callsService {
+				var ret any
+				if tt.mockReturnRun != nil {
+					ret = tt.mockReturnRun
+				}
+				mockSvc.On("CreateRun", mock.AnythingOfType("payroll.CreateRunRequest")).Return(ret, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/payroll/runs", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		runID          string
+		mockReturnRun  *payroll.Run
+		mockReturnErr  error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			runID:          "3",
+			mockReturnRun:  &payroll.Run{ID: 3, Frequency: payroll.Biweekly, PeriodStart: "2024-05-06", PeriodEnd: "2024-05-19", Items: []payroll.LineItem{}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"frequency":"biweekly","period_start":"2024-05-06","period_end":"2024-05-19"`,
+		},
+		{
+			name:           "run not found",
+			runID:          "999",
+			mockReturnErr:  fmt.Errorf("%w with id 999", payroll.ErrRunNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"payroll run not found"`,
+		},
+		{
+			name:           "service error",
+			runID:          "3",
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to get payroll run"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := payroll.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/payroll/runs/:id", h.GetRun)
+
+			var ret any
+			if tt.mockReturnRun != nil {
+				ret = tt.mockReturnRun
+			}
+			mockSvc.On("GetRun", mock.AnythingOfType("int64")).Return(ret, tt.mockReturnErr)
+
+			req, _ := http.NewRequest(http.MethodGet, "/payroll/runs/"+tt.runID, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PAYROLL_MISSION_BONUS", "")
+	t.Setenv("PAYROLL_WITHHOLDING_RATE", "")
+	t.Setenv("DEFAULT_CURRENCY", "")
+	assert.Equal(t, payroll.Config{MissionBonus: payroll.DefaultMissionBonus, Currency: "USD"}, payroll.ConfigFromEnv())
+
+	t.Setenv("PAYROLL_MISSION_BONUS", "250")
+	t.Setenv("PAYROLL_WITHHOLDING_RATE", "0.2")
+	t.Setenv("DEFAULT_CURRENCY", "eur")
+	assert.Equal(t, payroll.Config{MissionBonus: 250_00, WithholdingRate: 2000, Currency: "EUR"}, payroll.ConfigFromEnv())
+
+	t.Setenv("PAYROLL_WITHHOLDING_RATE", "1.5")
+	assert.Equal(t, payroll.Config{MissionBonus: 250_00, Currency: "EUR"}, payroll.ConfigFromEnv())
+}
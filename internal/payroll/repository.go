@@ -0,0 +1,204 @@
+package payroll
+
+import (
+	"database/sql"
+
+	"spy-cats/internal/database"
+)
+
+type Repository struct {
+	db database.Conn
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: database.NewConn(db)}
+}
+
+// InTx runs fn with a repository bound to a single transaction, committing
+// if fn succeeds
+func (r *Repository) InTx(fn func(tx RunRepository) error) error {
+	return r.db.InTx(func(tx database.Conn) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
+// LockRuns blocks other payroll runs until the end of the transaction so
+// that two runs cannot pay the same period
+func (r *Repository) LockRuns() error {
+	_, err := r.db.Exec(`LOCK TABLE payroll_runs IN SHARE ROW EXCLUSIVE MODE`)
+	return err
+}
+
+// OverlappingRunID returns the id of a run in currency whose period shares
+// a day with start..end, or nil if there is none
+func (r *Repository) OverlappingRunID(currency, start, end string) (*int64, error) {
+	var id int64
+	err := r.db.QueryRow(
+		`SELECT id FROM payroll_runs
+		 WHERE currency = $1 AND period_start <= $3::date AND period_end >= $2::date
+		 ORDER BY id LIMIT 1`,
+		currency, start, end,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ListPayableCats returns the cats that are not deleted and were paid in
+// currency at some point since start, ordered by id, with the salary changes
+// applied to them that took effect after start
+func (r *Repository) ListPayableCats(currency, start string) ([]PayableCat, error) {
+	rows, err := r.db.Query(
+		`SELECT c.id, c.name, c.salary, c.currency FROM cats c
+		 WHERE c.deleted_at IS NULL AND (c.currency = $1 OR EXISTS (
+		     SELECT 1 FROM salary_changes s
+		     WHERE s.cat_id = c.id AND s.applied_at IS NOT NULL
+		     AND s.effective_date > $2::date AND s.old_currency = $1
+		 ))
+		 ORDER BY c.id`,
+		currency, start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cats := []PayableCat{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var c PayableCat
+		if err := rows.Scan(&c.ID, &c.Name, &c.Salary.Amount, &c.Salary.Currency); err != nil {
+			return nil, err
+		}
+		index[c.ID] = len(cats)
+		cats = append(cats, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changes, err := r.db.Query(
+		`SELECT cat_id, to_char(effective_date, 'YYYY-MM-DD'), old_salary, old_currency, new_salary, new_currency
+		 FROM salary_changes
+		 WHERE applied_at IS NOT NULL AND effective_date > $1::date
+		 ORDER BY effective_date, id`,
+		start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer changes.Close()
+
+	for changes.Next() {
+		var catID int64
+		var ch AppliedSalaryChange
+		if err := changes.Scan(
+			&catID, &ch.EffectiveDate, &ch.Old.Amount, &ch.Old.Currency, &ch.New.Amount, &ch.New.Currency,
+		); err != nil {
+			return nil, err
+		}
+		if i, ok := index[catID]; ok {
+			cats[i].Changes = append(cats[i].Changes, ch)
+		}
+	}
+	return cats, changes.Err()
+}
+
+// CompletedMissions returns per cat the UTC dates, in order, of the missions
+// credited to it in its completion events between the start and end dates,
+// both included. Missions that were deleted since do not count.
+func (r *Repository) CompletedMissions(start, end string) (map[int64][]string, error) {
+	rows, err := r.db.Query(
+		`SELECT e.cat_id, to_char(e.occurred_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') FROM completion_events e
+		 WHERE e.type = 'mission_completed'
+		 AND e.occurred_at >= ($1::date)::timestamp AT TIME ZONE 'UTC'
+		 AND e.occurred_at < ($2::date + 1)::timestamp AT TIME ZONE 'UTC'
+		 AND NOT EXISTS (SELECT 1 FROM missions m WHERE m.id = e.mission_id AND m.deleted_at IS NOT NULL)
+		 ORDER BY e.occurred_at, e.id`,
+		start, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dates := make(map[int64][]string)
+	for rows.Next() {
+		var catID int64
+		var date string
+		if err := rows.Scan(&catID, &date); err != nil {
+			return nil, err
+		}
+		dates[catID] = append(dates[catID], date)
+	}
+	return dates, rows.Err()
+}
+
+// CreateRun stores the run with its line items and returns its id and
+// creation time
+func (r *Repository) CreateRun(run Run) (int64, error) {
+	var id int64
+	if err := r.db.QueryRow(
+		`INSERT INTO payroll_runs (frequency, period_start, period_end, currency, total_gross, total_deductions, total_net)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		run.Frequency, run.PeriodStart, run.PeriodEnd, run.Currency, run.TotalGross, run.TotalDeductions, run.TotalNet,
+	).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	for _, item := range run.Items {
+		if _, err := r.db.Exec(
+			`INSERT INTO payroll_items
+			 (run_id, cat_id, cat_name, base_salary, missions_completed, mission_bonus, deductions, net_pay)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			id, item.CatID, item.CatName, item.BaseSalary, item.MissionsCompleted,
+			item.MissionBonus, item.Deductions, item.NetPay,
+		); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// GetRun returns the run with its line items ordered by cat id
+func (r *Repository) GetRun(id int64) (*Run, error) {
+	var run Run
+	if err := r.db.QueryRow(
+		`SELECT id, frequency, to_char(period_start, 'YYYY-MM-DD'), to_char(period_end, 'YYYY-MM-DD'), currency,
+		        total_gross, total_deductions, total_net, created_at
+		 FROM payroll_runs WHERE id = $1`,
+		id,
+	).Scan(
+		&run.ID, &run.Frequency, &run.PeriodStart, &run.PeriodEnd, &run.Currency,
+		&run.TotalGross, &run.TotalDeductions, &run.TotalNet, &run.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT cat_id, cat_name, base_salary, missions_completed, mission_bonus, deductions, net_pay
+		 FROM payroll_items WHERE run_id = $1 ORDER BY cat_id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	run.Items = []LineItem{}
+	for rows.Next() {
+		var item LineItem
+		if err := rows.Scan(
+			&item.CatID, &item.CatName, &item.BaseSalary, &item.MissionsCompleted,
+			&item.MissionBonus, &item.Deductions, &item.NetPay,
+		); err != nil {
+			return nil, err
+		}
+		run.Items = append(run.Items, item)
+	}
+	return &run, rows.Err()
+}
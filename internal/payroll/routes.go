@@ -0,0 +1,16 @@
+package payroll
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(rg *gin.RouterGroup, db *sql.DB, cfg Config) {
+	repo := NewRepository(db)
+	service := NewService(repo, cfg)
+	handler := NewHandler(service)
+
+	rg.POST("/runs", handler.CreateRun)
+	rg.GET("/runs/:id", handler.GetRun)
+}
@@ -0,0 +1,267 @@
+package payroll
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"spy-cats/internal/utils"
+)
+
+var (
+	// ErrRunNotFound is returned when the requested payroll run does not exist
+	ErrRunNotFound = errors.New("payroll run not found")
+	// ErrInvalidPeriod is returned for pay periods that cannot be run
+	ErrInvalidPeriod = errors.New("invalid pay period")
+	// ErrInvalidCurrency is returned for runs in a malformed currency code
+	ErrInvalidCurrency = errors.New("invalid currency")
+	// ErrPeriodClosed is returned when a pay period overlaps one that was
+	// already run
+	ErrPeriodClosed = errors.New("pay period is closed")
+)
+
+const DefaultMissionBonus utils.Amount = 500_00
+
+// Config sets the bonus paid per mission a cat completed in the period, the
+// share of gross pay withheld as deductions and the currency of runs that do
+// not name one. The bonus is a flat amount in the currency the cat was paid
+// in on the day it completed the mission, and only that currency's run pays
+// it.
+type Config struct {
+	MissionBonus    utils.Amount
+	WithholdingRate utils.Rate
+	Currency        string
+}
+
+// ConfigFromEnv builds the payroll config from PAYROLL_* variables
+func ConfigFromEnv() Config {
+	cfg := Config{MissionBonus: DefaultMissionBonus, Currency: utils.DefaultCurrencyFromEnv()}
+	if bonus, err := utils.ParseAmount(os.Getenv("PAYROLL_MISSION_BONUS")); err == nil && bonus >= 0 {
+		cfg.MissionBonus = bonus
+	}
+	if rate, err := utils.ParseRate(os.Getenv("PAYROLL_WITHHOLDING_RATE")); err == nil && rate >= 0 && rate <= 10_000 {
+		cfg.WithholdingRate = rate
+	}
+	return cfg
+}
+
+// periodsPerYear prorates yearly salaries to one pay period
+var periodsPerYear = map[Frequency]int64{
+	Monthly:  12,
+	Biweekly: 26,
+}
+
+// RunRepository is the persistence used by Service. *Repository implements
+// it on top of PostgreSQL.
+type RunRepository interface {
+	// InTx runs fn in a single transaction
+	InTx(fn func(tx RunRepository) error) error
+
+	LockRuns() error
+	OverlappingRunID(currency, start, end string) (*int64, error)
+	ListPayableCats(currency, start string) ([]PayableCat, error)
+	CompletedMissions(start, end string) (map[int64][]string, error)
+	CreateRun(run Run) (int64, error)
+	GetRun(id int64) (*Run, error)
+}
+
+type Service struct {
+	repo RunRepository
+	cfg  Config
+	now  func() time.Time
+}
+
+func NewService(repo RunRepository, cfg Config) *Service {
+	if cfg.Currency == "" {
+		cfg.Currency = utils.DefaultCurrency
+	}
+	return &Service{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// CreateRun pays every cat that is not deleted for the days of the pay
+// period starting on req.PeriodStart on which it was paid in the run's
+// currency and stores the run. A period that overlaps an earlier run in the same currency is closed
+// and cannot be run again.
+func (s *Service) CreateRun(req CreateRunRequest) (*Run, error) {
+	start, end, err := s.period(req.Frequency, req.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = s.cfg.Currency
+	}
+	if !utils.ValidCurrency(currency) {
+		return nil, fmt.Errorf("%w: %q is not an ISO 4217 currency code with at most 2 decimal places", ErrInvalidCurrency, req.Currency)
+	}
+
+	var runID int64
+	err = s.repo.InTx(func(tx RunRepository) error {
+		if err := tx.LockRuns(); err != nil {
+			return err
+		}
+		closedBy, err := tx.OverlappingRunID(currency, start, end)
+		if err != nil {
+			return err
+		}
+		if closedBy != nil {
+			return fmt.Errorf("%w: %s to %s overlaps %s payroll run %d", ErrPeriodClosed, start, end, currency, *closedBy)
+		}
+
+		cats, err := tx.ListPayableCats(currency, start)
+		if err != nil {
+			return err
+		}
+		completed, err := tx.CompletedMissions(start, end)
+		if err != nil {
+			return err
+		}
+
+		run := Run{Frequency: req.Frequency, PeriodStart: start, PeriodEnd: end, Currency: currency, Items: make([]LineItem, len(cats))}
+		for i, cat := range cats {
+			base, err := basePay(cat, currency, req.Frequency, start, end)
+			if err != nil {
+				return err
+			}
+			missions := 0
+			for _, date := range completed[cat.ID] {
+				if salaryOn(cat, date).Currency == currency {
+					missions++
+				}
+			}
+			run.Items[i] = s.lineItem(cat, currency, base, missions)
+			run.TotalGross += run.Items[i].BaseSalary + run.Items[i].MissionBonus
+			run.TotalDeductions += run.Items[i].Deductions
+			run.TotalNet += run.Items[i].NetPay
+		}
+
+		runID, err = tx.CreateRun(run)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetRun(runID)
+}
+
+// period returns the first and last day of the pay period starting on start.
+// Only periods that ended before today (UTC) can be run, so that a run pays
+// every mission and salary change of its period.
+func (s *Service) period(freq Frequency, start string) (string, string, error) {
+	first, err := time.Parse(time.DateOnly, start)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: period_start %q is not a date", ErrInvalidPeriod, start)
+	}
+
+	var last time.Time
+	switch freq {
+	case Monthly:
+		if first.Day() != 1 {
+			return "", "", fmt.Errorf("%w: monthly periods start on the first day of a month", ErrInvalidPeriod)
+		}
+		last = first.AddDate(0, 1, -1)
+	case Biweekly:
+		last = first.AddDate(0, 0, 13)
+	default:
+		return "", "", fmt.Errorf("%w: unknown frequency %q", ErrInvalidPeriod, freq)
+	}
+	end := last.Format(time.DateOnly)
+	if today := s.now().UTC().Format(time.DateOnly); end >= today {
+		return "", "", fmt.Errorf("%w: the period %s to %s has not ended yet", ErrInvalidPeriod, start, end)
+	}
+	return start, end, nil
+}
+
+// basePay prorates the yearly salaries of the cat to the period from start
+// to end, paying each day the salary in effect on it if that salary was in
+// currency. It rounds once to the minor unit of currency, half away from
+// zero, so a salary that did not change is paid exactly 1/periodsPerYear of
+// it.
+func basePay(cat PayableCat, currency string, freq Frequency, start, end string) (utils.Amount, error) {
+	first, err := time.Parse(time.DateOnly, start)
+	if err != nil {
+		return 0, err
+	}
+	last, err := time.Parse(time.DateOnly, end)
+	if err != nil {
+		return 0, err
+	}
+	days := daysBetween(first, last) + 1
+
+	salary := cat.Salary
+	if len(cat.Changes) > 0 {
+		salary = cat.Changes[0].Old
+	}
+	// weighted adds up the daily salaries paid, times periodsPerYear*days
+	var weighted utils.Amount
+	from := 0
+	for _, ch := range cat.Changes {
+		effective, err := time.Parse(time.DateOnly, ch.EffectiveDate)
+		if err != nil {
+			return 0, err
+		}
+		day := min(daysBetween(first, effective), days)
+		if salary.Currency == currency {
+			weighted += salary.Amount * utils.Amount(day-from)
+		}
+		from, salary = day, ch.New
+	}
+	if salary.Currency == currency {
+		weighted += salary.Amount * utils.Amount(days-from)
+	}
+	unit := utils.MinorUnit(currency)
+	return weighted.MulDiv(1, periodsPerYear[freq]*int64(days)*int64(unit)) * unit, nil
+}
+
+// salaryOn returns the salary the cat was paid on date, a YYYY-MM-DD date
+// in the period the cat was listed for
+func salaryOn(cat PayableCat, date string) utils.Money {
+	salary := cat.Salary
+	if len(cat.Changes) > 0 {
+		salary = cat.Changes[0].Old
+	}
+	// both dates are YYYY-MM-DD, so they compare as strings
+	for _, ch := range cat.Changes {
+		if ch.EffectiveDate > date {
+			break
+		}
+		salary = ch.New
+	}
+	return salary
+}
+
+// daysBetween returns the number of days from a to b
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// lineItem computes the pay of a cat in minor units of currency from its
+// prorated base salary. The bonus is rounded to the minor unit, the
+// deductions are rounded once, half away from zero, and the net pay is
+// exactly the gross pay minus the deductions.
+func (s *Service) lineItem(cat PayableCat, currency string, base utils.Amount, missionsCompleted int) LineItem {
+	unit := utils.MinorUnit(currency)
+	item := LineItem{
+		CatID:             cat.ID,
+		CatName:           cat.Name,
+		BaseSalary:        base,
+		MissionsCompleted: missionsCompleted,
+		MissionBonus:      s.cfg.MissionBonus.MulDiv(1, int64(unit)) * unit * utils.Amount(missionsCompleted),
+	}
+	gross := item.BaseSalary + item.MissionBonus
+	item.Deductions = s.cfg.WithholdingRate.Of(gross/unit) * unit
+	item.NetPay = gross - item.Deductions
+	return item
+}
+
+// GetRun returns the payroll run with its line items
+func (s *Service) GetRun(id int64) (*Run, error) {
+	run, err := s.repo.GetRun(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrRunNotFound, id)
+	}
+	return run, err
+}
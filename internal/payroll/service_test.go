@@ -0,0 +1,267 @@
+package payroll_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/database/dbtest"
+	"spy-cats/internal/payroll"
+	"spy-cats/internal/utils"
+)
+
+// fakeRepo is an in-memory RunRepository. Cats hold every applied salary
+// change, runs are kept in creation order and completed missions are the
+// dates they were completed on per cat.
+type fakeRepo struct {
+	cats      []payroll.PayableCat
+	completed map[int64][]string
+	runs      []payroll.Run
+}
+
+func (r *fakeRepo) InTx(fn func(tx payroll.RunRepository) error) error {
+	return dbtest.InTx(func() error { return fn(r) }, &r.runs)
+}
+
+func (r *fakeRepo) LockRuns() error { return nil }
+
+func (r *fakeRepo) OverlappingRunID(currency, start, end string) (*int64, error) {
+	for _, run := range r.runs {
+		if run.Currency == currency && run.PeriodStart <= end && run.PeriodEnd >= start {
+			return &run.ID, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) ListPayableCats(currency, start string) ([]payroll.PayableCat, error) {
+	cats := []payroll.PayableCat{}
+	for _, cat := range r.cats {
+		payable := cat.Salary.Currency == currency
+		var changes []payroll.AppliedSalaryChange
+		for _, ch := range cat.Changes {
+			if ch.EffectiveDate > start {
+				changes = append(changes, ch)
+				payable = payable || ch.Old.Currency == currency
+			}
+		}
+		if payable {
+			cat.Changes = changes
+			cats = append(cats, cat)
+		}
+	}
+	return cats, nil
+}
+
+func (r *fakeRepo) CompletedMissions(start, end string) (map[int64][]string, error) {
+	dates := make(map[int64][]string)
+	for catID, completed := range r.completed {
+		for _, date := range completed {
+			if date >= start && date <= end {
+				dates[catID] = append(dates[catID], date)
+			}
+		}
+	}
+	return dates, nil
+}
+
+func (r *fakeRepo) CreateRun(run payroll.Run) (int64, error) {
+	run.ID = int64(len(r.runs) + 1)
+	r.runs = append(r.runs, run)
+	return run.ID, nil
+}
+
+func (r *fakeRepo) GetRun(id int64) (*payroll.Run, error) {
+	if id < 1 || id > int64(len(r.runs)) {
+		return nil, sql.ErrNoRows
+	}
+	run := r.runs[id-1]
+	return &run, nil
+}
+
+func TestServiceCreateRun(t *testing.T) {
+	newRepo := func() *fakeRepo {
+		return &fakeRepo{
+			cats: []payroll.PayableCat{
+				{ID: 1, Name: "Whiskers", Salary: usd(50000_00)},
+				{ID: 2, Name: "Shadow", Salary: usd(26000_00)},
+				{ID: 3, Name: "Luna", Salary: eur(60000_00)},
+			},
+			completed: map[int64][]string{1: {"2024-04-30", "2024-05-01", "2024-05-31"}},
+		}
+	}
+
+	t.Run("monthly with bonuses and deductions", func(t *testing.T) {
+		svc := payroll.NewService(newRepo(), payroll.Config{MissionBonus: 500_00, WithholdingRate: 1000})
+
+		run, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "2024-05-31", run.PeriodEnd)
+		assert.Equal(t, "USD", run.Currency)
+		assert.Equal(t, []payroll.LineItem{
+			{CatID: 1, CatName: "Whiskers", BaseSalary: 4166_67, MissionsCompleted: 2, MissionBonus: 1000_00, Deductions: 516_67, NetPay: 4650_00},
+			{CatID: 2, CatName: "Shadow", BaseSalary: 2166_67, Deductions: 216_67, NetPay: 1950_00},
+		}, run.Items)
+		assert.Equal(t, utils.Amount(7333_34), run.TotalGross)
+		assert.Equal(t, utils.Amount(733_34), run.TotalDeductions)
+		assert.Equal(t, utils.Amount(6600_00), run.TotalNet)
+	})
+
+	t.Run("salaries changed during the period are prorated by day", func(t *testing.T) {
+		repo := newRepo()
+		repo.cats = []payroll.PayableCat{
+			{ID: 1, Name: "Whiskers", Salary: usd(72000_00), Changes: []payroll.AppliedSalaryChange{
+				{EffectiveDate: "2024-04-01", Old: usd(50000_00), New: usd(60000_00)},
+				{EffectiveDate: "2024-05-16", Old: usd(60000_00), New: usd(72000_00)},
+			}},
+			{ID: 2, Name: "Shadow", Salary: usd(60000_00), Changes: []payroll.AppliedSalaryChange{
+				{EffectiveDate: "2024-06-10", Old: usd(48000_00), New: usd(60000_00)},
+			}},
+			{ID: 3, Name: "Luna", Salary: usd(66000_00), Changes: []payroll.AppliedSalaryChange{
+				{EffectiveDate: "2024-05-11", Old: eur(60000_00), New: usd(66000_00)},
+			}},
+		}
+		svc := payroll.NewService(repo, payroll.Config{})
+
+		run, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		require.NoError(t, err)
+		// 15 days at 60000 and 16 at 72000; the whole month at the salary
+		// before the June change; 21 days at 66000 after the switch to USD
+		assert.Equal(t, []payroll.LineItem{
+			{CatID: 1, CatName: "Whiskers", BaseSalary: 5516_13, MissionsCompleted: 2, NetPay: 5516_13},
+			{CatID: 2, CatName: "Shadow", BaseSalary: 4000_00, NetPay: 4000_00},
+			{CatID: 3, CatName: "Luna", BaseSalary: 3725_81, NetPay: 3725_81},
+		}, run.Items)
+
+		run, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "EUR"})
+		require.NoError(t, err)
+		assert.Equal(t, []payroll.LineItem{{CatID: 3, CatName: "Luna", BaseSalary: 1612_90, NetPay: 1612_90}}, run.Items)
+
+		run, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-06-01"})
+		require.NoError(t, err)
+		// 9 days at 48000 and 21 at 60000
+		assert.Equal(t, utils.Amount(4700_00), run.Items[1].BaseSalary)
+	})
+
+	t.Run("mission bonuses are paid in the currency of the day", func(t *testing.T) {
+		repo := newRepo()
+		repo.cats[2] = payroll.PayableCat{ID: 3, Name: "Luna", Salary: usd(66000_00), Changes: []payroll.AppliedSalaryChange{
+			{EffectiveDate: "2024-05-11", Old: eur(60000_00), New: usd(66000_00)},
+		}}
+		repo.completed[3] = []string{"2024-05-05", "2024-05-10", "2024-05-11"}
+		svc := payroll.NewService(repo, payroll.Config{MissionBonus: 100_00})
+
+		usdRun, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		require.NoError(t, err)
+		eurRun, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "EUR"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, usdRun.Items[2].MissionsCompleted)
+		assert.Equal(t, utils.Amount(100_00), usdRun.Items[2].MissionBonus)
+		assert.Equal(t, 2, eurRun.Items[0].MissionsCompleted)
+		assert.Equal(t, utils.Amount(200_00), eurRun.Items[0].MissionBonus)
+	})
+
+	t.Run("currencies without decimal places are paid in whole units", func(t *testing.T) {
+		repo := newRepo()
+		repo.cats = []payroll.PayableCat{{ID: 4, Name: "Mochi", Salary: utils.Money{Amount: 5000000_00, Currency: "JPY"}}}
+		repo.completed = map[int64][]string{4: {"2024-05-02"}}
+		svc := payroll.NewService(repo, payroll.Config{MissionBonus: 500_50, WithholdingRate: 1234})
+
+		run, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "JPY"})
+		require.NoError(t, err)
+		// 416666.67 rounds to 416667, the bonus to 501 and 12.34% of 417168
+		// to 51479
+		assert.Equal(t, []payroll.LineItem{
+			{CatID: 4, CatName: "Mochi", BaseSalary: 416667_00, MissionsCompleted: 1, MissionBonus: 501_00, Deductions: 51479_00, NetPay: 365689_00},
+		}, run.Items)
+	})
+
+	t.Run("biweekly", func(t *testing.T) {
+		svc := payroll.NewService(newRepo(), payroll.Config{})
+
+		run, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Biweekly, PeriodStart: "2024-02-20"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "2024-03-04", run.PeriodEnd)
+		assert.Equal(t, utils.Amount(1923_08), run.Items[0].BaseSalary)
+		assert.Equal(t, utils.Amount(1000_00), run.Items[1].NetPay)
+	})
+
+	t.Run("closed periods cannot be run again", func(t *testing.T) {
+		repo := newRepo()
+		svc := payroll.NewService(repo, payroll.Config{})
+
+		_, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		require.NoError(t, err)
+
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		assert.ErrorIs(t, err, payroll.ErrPeriodClosed)
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Biweekly, PeriodStart: "2024-04-20"})
+		assert.ErrorIs(t, err, payroll.ErrPeriodClosed)
+		assert.Len(t, repo.runs, 1)
+
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Biweekly, PeriodStart: "2024-06-01"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("runs are per currency", func(t *testing.T) {
+		repo := newRepo()
+		svc := payroll.NewService(repo, payroll.Config{Currency: "EUR"})
+
+		run, err := svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01"})
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", run.Currency)
+		assert.Equal(t, []payroll.LineItem{{CatID: 3, CatName: "Luna", BaseSalary: 5000_00, NetPay: 5000_00}}, run.Items)
+
+		run, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "usd"})
+		require.NoError(t, err)
+		assert.Equal(t, "USD", run.Currency)
+		assert.Len(t, run.Items, 2)
+
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-05-01", Currency: "EUR"})
+		assert.ErrorIs(t, err, payroll.ErrPeriodClosed)
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-06-01", Currency: "E1R"})
+		assert.ErrorIs(t, err, payroll.ErrInvalidCurrency)
+		_, err = svc.CreateRun(payroll.CreateRunRequest{Frequency: payroll.Monthly, PeriodStart: "2024-06-01", Currency: "KWD"})
+		assert.ErrorIs(t, err, payroll.ErrInvalidCurrency)
+	})
+
+	t.Run("invalid periods", func(t *testing.T) {
+		svc := payroll.NewService(newRepo(), payroll.Config{})
+		now := time.Now().UTC()
+		tomorrow := now.AddDate(0, 0, 1).Format(time.DateOnly)
+		thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(time.DateOnly)
+		twoWeeksAgo := now.AddDate(0, 0, -13).Format(time.DateOnly)
+
+		for _, req := range []payroll.CreateRunRequest{
+			{Frequency: payroll.Monthly, PeriodStart: "2024-05-15"},
+			{Frequency: payroll.Biweekly, PeriodStart: tomorrow},
+			{Frequency: payroll.Monthly, PeriodStart: thisMonth},
+			{Frequency: payroll.Biweekly, PeriodStart: twoWeeksAgo},
+			{Frequency: payroll.Monthly, PeriodStart: "2024-13-01"},
+			{Frequency: "weekly", PeriodStart: "2024-05-01"},
+		} {
+			_, err := svc.CreateRun(req)
+			assert.ErrorIs(t, err, payroll.ErrInvalidPeriod, req)
+		}
+	})
+}
+
+func usd(amount utils.Amount) utils.Money {
+	return utils.Money{Amount: amount, Currency: "USD"}
+}
+
+func eur(amount utils.Amount) utils.Money {
+	return utils.Money{Amount: amount, Currency: "EUR"}
+}
+
+func TestServiceGetRun(t *testing.T) {
+	svc := payroll.NewService(&fakeRepo{}, payroll.Config{})
+	_, err := svc.GetRun(42)
+	assert.ErrorIs(t, err, payroll.ErrRunNotFound)
+}
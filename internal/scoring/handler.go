@@ -0,0 +1,78 @@
+package scoring
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScoringService interface {
+	GetStats(catID int64) (*Stats, error)
+	GetLeaderboard(q LeaderboardQuery) ([]LeaderboardEntry, error)
+}
+
+type Handler struct {
+	service ScoringService
+}
+
+func NewHandler(service ScoringService) *Handler {
+	return &Handler{service: service}
+}
+
+// GetCatStats retrieves the performance record of a cat
+// @Summary      Get a cat's stats
+// @Description  Get the missions a cat completed and failed, the targets it neutralized, its success rate, average time from assignment to completion and score. Stats are updated shortly after missions and targets close.
+// @Tags         cats
+// @Produce      json
+// @Param        id   path      int  true  "Cat ID"
+// @Success      200  {object}  Stats             "Cat stats"
+// @Failure      400  {object}  map[string]string "Invalid cat ID"
+// @Failure      404  {object}  map[string]string "Cat not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /cats/{id}/stats [get]
+func (h *Handler) GetCatStats(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cat id"})
+		return
+	}
+
+	stats, err := h.service.GetStats(id)
+	if err != nil {
+		if errors.Is(err, ErrCatNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cat stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetLeaderboard ranks the cats
+// @Summary      Get the leaderboard
+// @Description  Rank the cats that have completed or failed a mission or neutralized a target. Cats earn 100 points per completed mission and 25 per neutralized target. Ranking by success rate or average time to complete only includes cats those are defined for; ties go to the lower cat ID.
+// @Tags         cats
+// @Produce      json
+// @Param        sort   query     string  false  "Ranking"  Enums(score, missions_completed, targets_neutralized, success_rate, avg_time_to_complete)  default(score)
+// @Param        limit  query     int     false  "Number of cats (1-100)"  default(10)
+// @Success      200    {array}   LeaderboardEntry  "Ranked cats"
+// @Failure      400    {object}  map[string]string "Invalid query"
+// @Failure      500    {object}  map[string]string "Internal server error"
+// @Router       /leaderboard [get]
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	var q LeaderboardQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.service.GetLeaderboard(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get leaderboard"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
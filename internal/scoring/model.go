@@ -0,0 +1,59 @@
+package scoring
+
+import (
+	"time"
+
+	"spy-cats/internal/missions"
+)
+
+// Points awarded to a cat's score
+const (
+	MissionPoints = 100
+	TargetPoints  = 25
+)
+
+// Stats is the performance record of a cat. SuccessRate is the share of the
+// cat's closed missions that were completed rather than failed, and
+// AvgTimeToCompleteSeconds the mean time from assignment to completion of
+// its completed missions; both are omitted until the cat has such missions.
+type Stats struct {
+	CatID                    int64    `json:"cat_id" example:"1"`
+	CatName                  string   `json:"cat_name" example:"Whiskers"`
+	MissionsCompleted        int      `json:"missions_completed" example:"4"`
+	MissionsFailed           int      `json:"missions_failed" example:"1"`
+	TargetsNeutralized       int      `json:"targets_neutralized" example:"9"`
+	SuccessRate              *float64 `json:"success_rate,omitempty" example:"0.8"`
+	AvgTimeToCompleteSeconds *int64   `json:"avg_time_to_complete_seconds,omitempty" example:"172800"`
+	Score                    int      `json:"score" example:"625"`
+}
+
+// LeaderboardEntry is a ranked cat on the leaderboard
+type LeaderboardEntry struct {
+	Rank int `json:"rank" example:"1"`
+	Stats
+}
+
+// LeaderboardQuery represents the ranking and size of the leaderboard
+type LeaderboardQuery struct {
+	Sort  string `form:"sort" binding:"omitempty,oneof=score missions_completed targets_neutralized success_rate avg_time_to_complete" example:"score"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100" example:"10"`
+}
+
+// Totals are the running counts kept per cat, from which Stats derive
+type Totals struct {
+	CatID              int64
+	CatName            string
+	MissionsCompleted  int
+	MissionsFailed     int
+	TargetsNeutralized int
+	CompletionSeconds  int64
+}
+
+// Event is a completion event recorded by the missions service
+type Event struct {
+	ID         int64
+	Type       missions.EventType
+	CatID      int64
+	StartedAt  time.Time
+	OccurredAt time.Time
+}
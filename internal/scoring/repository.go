@@ -0,0 +1,128 @@
+package scoring
+
+import (
+	"database/sql"
+	"fmt"
+
+	"spy-cats/internal/database"
+)
+
+type Repository struct {
+	db database.Conn
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: database.NewConn(db)}
+}
+
+// InTx runs fn with a repository bound to a single transaction, committing
+// if fn succeeds
+func (r *Repository) InTx(fn func(tx StatsRepository) error) error {
+	return r.db.InTx(func(tx database.Conn) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
+// LockPendingEvents locks up to limit unprocessed events, oldest first.
+// Events locked by another scorer are skipped.
+func (r *Repository) LockPendingEvents(limit int) ([]Event, error) {
+	rows, err := r.db.Query(
+		`SELECT id, type, cat_id, started_at, occurred_at FROM completion_events
+		 WHERE processed_at IS NULL ORDER BY id LIMIT $1
+		 FOR UPDATE SKIP LOCKED`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Type, &e.CatID, &e.StartedAt, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// AddTotals adds delta to the running totals of the cat
+func (r *Repository) AddTotals(delta Totals) error {
+	_, err := r.db.Exec(
+		`INSERT INTO cat_stats (cat_id, missions_completed, missions_failed, targets_neutralized, completion_seconds)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (cat_id) DO UPDATE SET
+		     missions_completed = cat_stats.missions_completed + EXCLUDED.missions_completed,
+		     missions_failed = cat_stats.missions_failed + EXCLUDED.missions_failed,
+		     targets_neutralized = cat_stats.targets_neutralized + EXCLUDED.targets_neutralized,
+		     completion_seconds = cat_stats.completion_seconds + EXCLUDED.completion_seconds,
+		     updated_at = now()`,
+		delta.CatID, delta.MissionsCompleted, delta.MissionsFailed, delta.TargetsNeutralized, delta.CompletionSeconds,
+	)
+	return err
+}
+
+// MarkEventProcessed records that the event has been applied
+func (r *Repository) MarkEventProcessed(id int64) error {
+	_, err := r.db.Exec(`UPDATE completion_events SET processed_at = now() WHERE id = $1`, id)
+	return err
+}
+
+// GetTotals returns the totals of a cat that is not deleted, zero if it has
+// none yet, or sql.ErrNoRows if there is no such cat
+func (r *Repository) GetTotals(catID int64) (*Totals, error) {
+	var t Totals
+	err := r.db.QueryRow(
+		`SELECT c.id, c.name, COALESCE(s.missions_completed, 0), COALESCE(s.missions_failed, 0),
+		        COALESCE(s.targets_neutralized, 0), COALESCE(s.completion_seconds, 0)
+		 FROM cats c LEFT JOIN cat_stats s ON s.cat_id = c.id
+		 WHERE c.id = $1 AND c.deleted_at IS NULL`,
+		catID,
+	).Scan(&t.CatID, &t.CatName, &t.MissionsCompleted, &t.MissionsFailed, &t.TargetsNeutralized, &t.CompletionSeconds)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// leaderboardOrders ranks cats by each sort key of LeaderboardQuery. Keys
+// with a filter only rank the cats the metric is defined for.
+var leaderboardOrders = map[string]struct{ filter, order string }{
+	"score":                {"", fmt.Sprintf("s.missions_completed * %d + s.targets_neutralized * %d DESC", MissionPoints, TargetPoints)},
+	"missions_completed":   {"", "s.missions_completed DESC"},
+	"targets_neutralized":  {"", "s.targets_neutralized DESC"},
+	"success_rate":         {"s.missions_completed + s.missions_failed > 0", "s.missions_completed::float / (s.missions_completed + s.missions_failed) DESC"},
+	"avg_time_to_complete": {"s.missions_completed > 0", "s.completion_seconds::float / s.missions_completed ASC"},
+}
+
+// Leaderboard returns the totals of the first limit cats that are not
+// deleted, ranked by sort with ties broken by cat id
+func (r *Repository) Leaderboard(sort string, limit int) ([]Totals, error) {
+	rank := leaderboardOrders[sort]
+	where := "c.deleted_at IS NULL"
+	if rank.filter != "" {
+		where += " AND " + rank.filter
+	}
+	rows, err := r.db.Query(fmt.Sprintf(
+		`SELECT c.id, c.name, s.missions_completed, s.missions_failed, s.targets_neutralized, s.completion_seconds
+		 FROM cat_stats s JOIN cats c ON c.id = s.cat_id
+		 WHERE %s ORDER BY %s, c.id LIMIT $1`, where, rank.order),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []Totals{}
+	for rows.Next() {
+		var t Totals
+		if err := rows.Scan(&t.CatID, &t.CatName, &t.MissionsCompleted, &t.MissionsFailed, &t.TargetsNeutralized, &t.CompletionSeconds); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
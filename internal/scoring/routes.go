@@ -0,0 +1,18 @@
+package scoring
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes adds the stats of a cat to the cats routes and the
+// leaderboard to api
+func RegisterRoutes(api *gin.RouterGroup, db *sql.DB) {
+	repo := NewRepository(db)
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	api.GET("/cats/:id/stats", handler.GetCatStats)
+	api.GET("/leaderboard", handler.GetLeaderboard)
+}
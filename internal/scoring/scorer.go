@@ -0,0 +1,67 @@
+package scoring
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+)
+
+const DefaultScoringInterval = time.Minute
+
+// IntervalFromEnv reads how often pending completion events are checked
+// from SCORING_INTERVAL
+func IntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("SCORING_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultScoringInterval
+}
+
+// Scorer consumes completion events into the cats' stats. It polls every
+// interval and right after being notified of new events.
+type Scorer struct {
+	service  *Service
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewScorer(db *sql.DB, interval time.Duration) *Scorer {
+	if interval <= 0 {
+		interval = DefaultScoringInterval
+	}
+	return &Scorer{service: NewService(NewRepository(db)), interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Notify wakes the scorer without blocking; notifications that arrive while
+// one is pending are merged
+func (s *Scorer) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run applies pending events every interval and on notification until ctx
+// is cancelled
+func (s *Scorer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.service.ProcessEvents()
+		if err != nil {
+			log.Printf("scoring: %v", err)
+		} else if n > 0 {
+			log.Printf("scoring: applied %d completion event(s)", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
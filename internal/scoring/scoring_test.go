@@ -0,0 +1,206 @@
+package scoring_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"spy-cats/internal/scoring"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) GetStats(catID int64) (*scoring.Stats, error) {
+	args := m.Called(catID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*scoring.Stats), args.Error(1)
+}
+
+func (m *mockService) GetLeaderboard(q scoring.LeaderboardQuery) ([]scoring.LeaderboardEntry, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]scoring.LeaderboardEntry), args.Error(1)
+}
+
+func TestGetCatStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rate, avg := 0.8, int64(172800)
+	tests := []struct {
+		name            string
+		catID           string
+		callsService    bool
+		mockReturnStats *scoring.Stats
+		mockReturnErr   error
+		expectedStatus  int
+		expectedBody    string
+	}{
+		{
+			name:         "success",
+			catID:        "1",
+			callsService: true,
+			mockReturnStats: &scoring.Stats{
+				CatID: 1, CatName: "Whiskers", MissionsCompleted: 4, MissionsFailed: 1, TargetsNeutralized: 9,
+				SuccessRate: &rate, AvgTimeToCompleteSeconds: &avg, Score: 625,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"cat_id":1,"cat_name":"Whiskers","missions_completed":4,"missions_failed":1,"targets_neutralized":9,"success_rate":0.8,"avg_time_to_complete_seconds":172800,"score":625}`,
+		},
+		{
+			name:            "cat without closed missions",
+			catID:           "2",
+			callsService:    true,
+			mockReturnStats: &scoring.Stats{CatID: 2, CatName: "Mittens"},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"cat_id":2,"cat_name":"Mittens","missions_completed":0,"missions_failed":0,"targets_neutralized":0,"score":0}`,
+		},
+		{
+			name:           "invalid cat ID",
+			catID:          "invalid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"invalid cat id"`,
+		},
+		{
+			name:           "cat not found",
+			catID:          "999",
+			callsService:   true,
+			mockReturnErr:  fmt.Errorf("%w with id 999", scoring.ErrCatNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"error":"cat not found with id 999"`,
+		},
+		{
+			name:           "service error",
+			catID:          "1",
+			callsService:   true,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to get cat stats"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := scoring.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/cats/:id/stats", h.GetCatStats)
+
+			if tt.callsService {
+				var ret any
+				if tt.mockReturnStats != nil {
+					ret = tt.mockReturnStats
+				}
+				mockSvc.On("GetStats", mock.AnythingOfType("int64")).Return(ret, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/cats/"+tt.catID+"/stats", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name              string
+		query             string
+		callsService      bool
+		expectedQuery     scoring.LeaderboardQuery
+		mockReturnEntries []scoring.LeaderboardEntry
+		mockReturnErr     error
+		expectedStatus    int
+		expectedBody      string
+	}{
+		{
+			name:         "default ranking",
+			callsService: true,
+			mockReturnEntries: []scoring.LeaderboardEntry{
+				{Rank: 1, Stats: scoring.Stats{CatID: 3, CatName: "Shadow", MissionsCompleted: 2, Score: 200}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"rank":1,"cat_id":3,"cat_name":"Shadow","missions_completed":2,`,
+		},
+		{
+			name:              "sort and limit",
+			query:             "?sort=success_rate&limit=3",
+			callsService:      true,
+			expectedQuery:     scoring.LeaderboardQuery{Sort: "success_rate", Limit: 3},
+			mockReturnEntries: []scoring.LeaderboardEntry{},
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `[]`,
+		},
+		{
+			name:           "unknown sort",
+			query:          "?sort=salary",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "limit too large",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error"`,
+		},
+		{
+			name:           "service error",
+			callsService:   true,
+			mockReturnErr:  errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"error":"failed to get leaderboard"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(mockService)
+			h := scoring.NewHandler(mockSvc)
+
+			r := gin.Default()
+			r.GET("/leaderboard", h.GetLeaderboard)
+
+			if tt.callsService {
+				var ret any
+				if tt.mockReturnEntries != nil {
+					ret = tt.mockReturnEntries
+				}
+				mockSvc.On("GetLeaderboard", tt.expectedQuery).Return(ret, tt.mockReturnErr)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/leaderboard"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIntervalFromEnv(t *testing.T) {
+	t.Setenv("SCORING_INTERVAL", "")
+	assert.Equal(t, scoring.DefaultScoringInterval, scoring.IntervalFromEnv())
+
+	t.Setenv("SCORING_INTERVAL", "10s")
+	assert.Equal(t, 10*time.Second, scoring.IntervalFromEnv())
+}
@@ -0,0 +1,144 @@
+package scoring
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"spy-cats/internal/missions"
+)
+
+// ErrCatNotFound is returned for stats of a cat that does not exist
+var ErrCatNotFound = errors.New("cat not found")
+
+const (
+	DefaultLeaderboardSize = 10
+	// eventBatchSize is the number of events applied per transaction
+	eventBatchSize = 100
+)
+
+// StatsRepository is the persistence used by Service. *Repository
+// implements it on top of PostgreSQL.
+type StatsRepository interface {
+	// InTx runs fn in a single transaction
+	InTx(fn func(tx StatsRepository) error) error
+
+	LockPendingEvents(limit int) ([]Event, error)
+	AddTotals(delta Totals) error
+	MarkEventProcessed(id int64) error
+	GetTotals(catID int64) (*Totals, error)
+	Leaderboard(sort string, limit int) ([]Totals, error)
+}
+
+type Service struct {
+	repo StatsRepository
+}
+
+func NewService(repo StatsRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// ProcessEvents applies the pending completion events to the totals of the
+// cats they credit and returns how many were applied. Each batch of events
+// is applied and marked processed in one transaction, so every event counts
+// exactly once.
+func (s *Service) ProcessEvents() (int, error) {
+	processed := 0
+	for {
+		n := 0
+		err := s.repo.InTx(func(tx StatsRepository) error {
+			events, err := tx.LockPendingEvents(eventBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, e := range events {
+				if err := tx.AddTotals(delta(e)); err != nil {
+					return err
+				}
+				if err := tx.MarkEventProcessed(e.ID); err != nil {
+					return err
+				}
+			}
+			n = len(events)
+			return nil
+		})
+		if err != nil {
+			return processed, err
+		}
+		processed += n
+		if n < eventBatchSize {
+			return processed, nil
+		}
+	}
+}
+
+// delta is the change an event makes to its cat's totals
+func delta(e Event) Totals {
+	d := Totals{CatID: e.CatID}
+	switch e.Type {
+	case missions.EventMissionCompleted:
+		d.MissionsCompleted = 1
+		d.CompletionSeconds = max(int64(e.OccurredAt.Sub(e.StartedAt).Seconds()), 0)
+	case missions.EventMissionFailed:
+		d.MissionsFailed = 1
+	case missions.EventTargetCompleted:
+		d.TargetsNeutralized = 1
+	}
+	return d
+}
+
+// stats derives the performance record from a cat's totals
+func stats(t Totals) Stats {
+	st := Stats{
+		CatID:              t.CatID,
+		CatName:            t.CatName,
+		MissionsCompleted:  t.MissionsCompleted,
+		MissionsFailed:     t.MissionsFailed,
+		TargetsNeutralized: t.TargetsNeutralized,
+		Score:              t.MissionsCompleted*MissionPoints + t.TargetsNeutralized*TargetPoints,
+	}
+	if closed := t.MissionsCompleted + t.MissionsFailed; closed > 0 {
+		rate := math.Round(float64(t.MissionsCompleted)/float64(closed)*1000) / 1000
+		st.SuccessRate = &rate
+	}
+	if t.MissionsCompleted > 0 {
+		avg := t.CompletionSeconds / int64(t.MissionsCompleted)
+		st.AvgTimeToCompleteSeconds = &avg
+	}
+	return st
+}
+
+// GetStats returns the performance record of a cat that is not deleted
+func (s *Service) GetStats(catID int64) (*Stats, error) {
+	t, err := s.repo.GetTotals(catID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with id %d", ErrCatNotFound, catID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := stats(*t)
+	return &st, nil
+}
+
+// GetLeaderboard ranks the cats that have completed or failed anything by
+// q.Sort, score by default
+func (s *Service) GetLeaderboard(q LeaderboardQuery) ([]LeaderboardEntry, error) {
+	if q.Sort == "" {
+		q.Sort = "score"
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultLeaderboardSize
+	}
+
+	totals, err := s.repo.Leaderboard(q.Sort, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]LeaderboardEntry, len(totals))
+	for i, t := range totals {
+		entries[i] = LeaderboardEntry{Rank: i + 1, Stats: stats(t)}
+	}
+	return entries, nil
+}
@@ -0,0 +1,200 @@
+package scoring_test
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/database/dbtest"
+	"spy-cats/internal/missions"
+	"spy-cats/internal/scoring"
+)
+
+// fakeRepo is an in-memory StatsRepository. InTx restores the previous
+// state when fn fails, like a rolled back transaction.
+type fakeRepo struct {
+	cats      map[int64]string
+	events    []scoring.Event
+	processed map[int64]bool
+	totals    map[int64]scoring.Totals
+	failOn    int64
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		cats:      map[int64]string{1: "Whiskers", 2: "Shadow", 3: "Luna"},
+		processed: make(map[int64]bool),
+		totals:    make(map[int64]scoring.Totals),
+	}
+}
+
+func (r *fakeRepo) InTx(fn func(tx scoring.StatsRepository) error) error {
+	return dbtest.InTx(func() error { return fn(r) }, &r.processed, &r.totals)
+}
+
+func (r *fakeRepo) LockPendingEvents(limit int) ([]scoring.Event, error) {
+	events := []scoring.Event{}
+	for _, e := range r.events {
+		if !r.processed[e.ID] && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeRepo) AddTotals(d scoring.Totals) error {
+	if d.CatID == r.failOn {
+		return errors.New("database error")
+	}
+	t := r.totals[d.CatID]
+	t.CatID = d.CatID
+	t.MissionsCompleted += d.MissionsCompleted
+	t.MissionsFailed += d.MissionsFailed
+	t.TargetsNeutralized += d.TargetsNeutralized
+	t.CompletionSeconds += d.CompletionSeconds
+	r.totals[d.CatID] = t
+	return nil
+}
+
+func (r *fakeRepo) MarkEventProcessed(id int64) error {
+	r.processed[id] = true
+	return nil
+}
+
+func (r *fakeRepo) GetTotals(catID int64) (*scoring.Totals, error) {
+	name, ok := r.cats[catID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	t := r.totals[catID]
+	t.CatID, t.CatName = catID, name
+	return &t, nil
+}
+
+// Leaderboard only ranks by score
+func (r *fakeRepo) Leaderboard(sortBy string, limit int) ([]scoring.Totals, error) {
+	totals := []scoring.Totals{}
+	for id, t := range r.totals {
+		t.CatName = r.cats[id]
+		totals = append(totals, t)
+	}
+	score := func(t scoring.Totals) int {
+		return t.MissionsCompleted*scoring.MissionPoints + t.TargetsNeutralized*scoring.TargetPoints
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if si, sj := score(totals[i]), score(totals[j]); si != sj {
+			return si > sj
+		}
+		return totals[i].CatID < totals[j].CatID
+	})
+	return totals[:min(limit, len(totals))], nil
+}
+
+func (r *fakeRepo) event(t missions.EventType, catID int64, took time.Duration) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	r.events = append(r.events, scoring.Event{
+		ID: int64(len(r.events) + 1), Type: t, CatID: catID, StartedAt: start, OccurredAt: start.Add(took),
+	})
+}
+
+func TestServiceProcessEvents(t *testing.T) {
+	t.Run("applies each event once", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := scoring.NewService(repo)
+		repo.event(missions.EventTargetCompleted, 1, time.Hour)
+		repo.event(missions.EventTargetCompleted, 1, 2*time.Hour)
+		repo.event(missions.EventMissionCompleted, 1, 2*time.Hour)
+		repo.event(missions.EventMissionCompleted, 1, 4*time.Hour)
+		repo.event(missions.EventMissionFailed, 1, time.Hour)
+		repo.event(missions.EventMissionFailed, 2, time.Hour)
+
+		n, err := svc.ProcessEvents()
+		require.NoError(t, err)
+		assert.Equal(t, 6, n)
+		n, err = svc.ProcessEvents()
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		stats, err := svc.GetStats(1)
+		require.NoError(t, err)
+		rate, avg := 0.667, int64(3*3600)
+		assert.Equal(t, &scoring.Stats{
+			CatID: 1, CatName: "Whiskers", MissionsCompleted: 2, MissionsFailed: 1, TargetsNeutralized: 2,
+			SuccessRate: &rate, AvgTimeToCompleteSeconds: &avg, Score: 250,
+		}, stats)
+
+		stats, err = svc.GetStats(2)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, *stats.SuccessRate)
+		assert.Nil(t, stats.AvgTimeToCompleteSeconds)
+	})
+
+	t.Run("processes every batch", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := scoring.NewService(repo)
+		for range 250 {
+			repo.event(missions.EventTargetCompleted, 3, time.Minute)
+		}
+
+		n, err := svc.ProcessEvents()
+		require.NoError(t, err)
+		assert.Equal(t, 250, n)
+		assert.Equal(t, 250, repo.totals[3].TargetsNeutralized)
+	})
+
+	t.Run("failed batch is retried", func(t *testing.T) {
+		repo := newFakeRepo()
+		svc := scoring.NewService(repo)
+		repo.event(missions.EventMissionCompleted, 1, time.Hour)
+		repo.event(missions.EventMissionCompleted, 2, time.Hour)
+		repo.failOn = 2
+
+		_, err := svc.ProcessEvents()
+		assert.Error(t, err)
+		assert.Empty(t, repo.totals)
+		assert.Empty(t, repo.processed)
+
+		repo.failOn = 0
+		n, err := svc.ProcessEvents()
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, 1, repo.totals[1].MissionsCompleted)
+	})
+}
+
+func TestServiceGetStats(t *testing.T) {
+	svc := scoring.NewService(newFakeRepo())
+
+	stats, err := svc.GetStats(3)
+	require.NoError(t, err)
+	assert.Equal(t, &scoring.Stats{CatID: 3, CatName: "Luna"}, stats)
+
+	_, err = svc.GetStats(42)
+	assert.ErrorIs(t, err, scoring.ErrCatNotFound)
+}
+
+func TestServiceGetLeaderboard(t *testing.T) {
+	repo := newFakeRepo()
+	svc := scoring.NewService(repo)
+	repo.event(missions.EventMissionCompleted, 2, time.Hour)
+	repo.event(missions.EventTargetCompleted, 1, time.Hour)
+	repo.event(missions.EventTargetCompleted, 3, time.Hour)
+	_, err := svc.ProcessEvents()
+	require.NoError(t, err)
+
+	entries, err := svc.GetLeaderboard(scoring.LeaderboardQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []int64{2, 1, 3}, []int64{entries[0].CatID, entries[1].CatID, entries[2].CatID})
+	assert.Equal(t, []int{1, 2, 3}, []int{entries[0].Rank, entries[1].Rank, entries[2].Rank})
+	assert.Equal(t, 100, entries[0].Score)
+
+	entries, err = svc.GetLeaderboard(scoring.LeaderboardQuery{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
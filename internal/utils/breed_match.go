@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxBreedSuggestions = 3
+
+// UnknownBreedError is returned when a breed matches neither a breed name nor
+// an alias. Suggestions holds the closest canonical names, best first.
+type UnknownBreedError struct {
+	Breed       string
+	Suggestions []string
+}
+
+func (e *UnknownBreedError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("invalid cat breed: %s", e.Breed)
+	}
+	return fmt.Sprintf("invalid cat breed: %s, did you mean: %s?", e.Breed, strings.Join(e.Suggestions, ", "))
+}
+
+// BreedMatcher resolves user input to canonical breed names. Matching ignores
+// case, surrounding whitespace, and hyphen/underscore vs space differences,
+// and accepts aliases.
+type BreedMatcher struct {
+	canonical map[string]string
+	entries   []matchEntry
+}
+
+type matchEntry struct {
+	key  string
+	name string
+}
+
+func NewBreedMatcher() *BreedMatcher {
+	return &BreedMatcher{canonical: make(map[string]string)}
+}
+
+// Add registers a breed and its aliases. The first breed to claim a
+// normalized name or alias keeps it.
+func (m *BreedMatcher) Add(name string, aliases ...string) {
+	for _, key := range append([]string{name}, aliases...) {
+		k := NormalizeBreed(key)
+		if k == "" {
+			continue
+		}
+		if _, taken := m.canonical[k]; taken {
+			continue
+		}
+		m.canonical[k] = name
+		m.entries = append(m.entries, matchEntry{key: k, name: name})
+	}
+}
+
+// Resolve returns the canonical name for breed, or an *UnknownBreedError
+// carrying "did you mean" suggestions ranked by edit distance
+func (m *BreedMatcher) Resolve(breed string) (string, error) {
+	key := NormalizeBreed(breed)
+	if name, ok := m.canonical[key]; ok {
+		return name, nil
+	}
+	return "", &UnknownBreedError{Breed: breed, Suggestions: m.suggest(key)}
+}
+
+func (m *BreedMatcher) suggest(key string) []string {
+	if key == "" {
+		return []string{}
+	}
+
+	// allow roughly one typo per three characters, but always at least two
+	limit := len([]rune(key)) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	best := make(map[string]int)
+	for _, e := range m.entries {
+		d := levenshtein(key, e.key)
+		if d > limit {
+			continue
+		}
+		if cur, ok := best[e.name]; !ok || d < cur {
+			best[e.name] = d
+		}
+	}
+
+	names := make([]string, 0, len(best))
+	for name := range best {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if best[names[i]] != best[names[j]] {
+			return best[names[i]] < best[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxBreedSuggestions {
+		names = names[:maxBreedSuggestions]
+	}
+	return names
+}
+
+// NormalizeBreed lowercases breed, treats '-' and '_' as spaces and
+// collapses whitespace
+func NormalizeBreed(breed string) string {
+	breed = strings.ToLower(breed)
+	breed = strings.NewReplacer("-", " ", "_", " ").Replace(breed)
+	return strings.Join(strings.Fields(breed), " ")
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
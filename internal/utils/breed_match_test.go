@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"spy-cats/internal/utils"
+)
+
+func TestBreedMatcher(t *testing.T) {
+	m := utils.NewBreedMatcher()
+	m.Add("Siamese", "Siam", "Thai Cat")
+	m.Add("Persian", "Longhair")
+	m.Add("Pixie-bob")
+	m.Add("Maine Coon", "Coon Cat")
+	m.Add("Manx")
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		suggestions []string
+	}{
+		{name: "exact", input: "Siamese", expected: "Siamese"},
+		{name: "case insensitive", input: "siamese", expected: "Siamese"},
+		{name: "surrounding whitespace", input: "  MAINE   coon ", expected: "Maine Coon"},
+		{name: "hyphen and space", input: "pixie bob", expected: "Pixie-bob"},
+		{name: "alias", input: "thai cat", expected: "Siamese"},
+		{name: "typo", input: "Siamse", suggestions: []string{"Siamese"}},
+		{name: "typo in alias", input: "coon kat", suggestions: []string{"Maine Coon"}},
+		{name: "ranked by distance", input: "Mans", suggestions: []string{"Manx"}},
+		{name: "nothing close", input: "Unicorn", suggestions: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Resolve(tt.input)
+			if tt.expected != "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+				return
+			}
+
+			var unknown *utils.UnknownBreedError
+			assert.True(t, errors.As(err, &unknown))
+			assert.Equal(t, tt.input, unknown.Breed)
+			assert.Equal(t, tt.suggestions, unknown.Suggestions)
+		})
+	}
+}
+
+func TestUnknownBreedErrorMessage(t *testing.T) {
+	err := &utils.UnknownBreedError{Breed: "Siamse", Suggestions: []string{"Siamese"}}
+	assert.Equal(t, "invalid cat breed: Siamse, did you mean: Siamese?", err.Error())
+
+	err = &utils.UnknownBreedError{Breed: "Unicorn"}
+	assert.Equal(t, "invalid cat breed: Unicorn", err.Error())
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of decimal places of an Amount
+const MoneyScale = 2
+
+const DefaultCurrency = "USD"
+
+// ErrInvalidMoney is returned for amounts that are not exact decimals with
+// at most MoneyScale decimal places
+var ErrInvalidMoney = errors.New("invalid money")
+
+// Amount is an exact amount of money in minor units, hundredths of the
+// currency unit. It is written as a decimal string such as "1234.50" to JSON
+// and to the database.
+type Amount int64
+
+// ParseAmount parses a decimal such as "1234.5" or "-0.05". Amounts with
+// more than MoneyScale significant decimal places are rejected rather than
+// rounded.
+func ParseAmount(s string) (Amount, error) {
+	n, err := parseFixed(s, MoneyScale)
+	return Amount(n), err
+}
+
+// parseFixed parses a decimal into an integer number of 10^-scale units,
+// rejecting decimals that have more significant decimal places than scale
+func parseFixed(s string, scale int) (int64, error) {
+	digits, neg := strings.CutPrefix(s, "-")
+	whole, frac, dot := strings.Cut(digits, ".")
+	if whole == "" || (dot && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("%w: %q is not a decimal amount", ErrInvalidMoney, s)
+	}
+	if len(frac) > scale {
+		if strings.TrimRight(frac[scale:], "0") != "" {
+			return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, scale)
+		}
+		frac = frac[:scale]
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with exactly MoneyScale decimal places
+func (a Amount) String() string {
+	sign, n := "", int64(a)
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// MulDiv returns a*num/den rounded half away from zero, the one rounding
+// rule used wherever money is prorated or split. den must not be zero.
+func (a Amount) MulDiv(num, den int64) Amount {
+	p := int64(a) * num
+	q, r := p/den, p%den
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= abs(den) {
+		if (p < 0) != (den < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Amount(q)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts the amount as a string or as a JSON number; numbers
+// are parsed from their text so they are never rounded through a float
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// UnmarshalParam parses the amount from a query or form parameter
+func (a *Amount) UnmarshalParam(param string) error {
+	v, err := ParseAmount(param)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Scan reads a NUMERIC column
+func (a *Amount) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into an amount", ErrInvalidMoney, src)
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value writes the amount as a decimal for NUMERIC columns
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// RateScale is the number of decimal places of a Rate
+const RateScale = 4
+
+// Rate is an exact fraction in ten-thousandths, so 0.125 is Rate(1250)
+type Rate int64
+
+// ParseRate parses a fraction such as "0.2" with at most RateScale decimal
+// places
+func ParseRate(s string) (Rate, error) {
+	n, err := parseFixed(s, RateScale)
+	return Rate(n), err
+}
+
+// Of returns the rate's share of the amount, rounded like MulDiv
+func (r Rate) Of(a Amount) Amount {
+	return a.MulDiv(int64(r), 10_000)
+}
+
+// Money is an exact amount of an ISO 4217 currency. Amounts have at most
+// MoneyScale decimal places, and no more than the currency uses.
+type Money struct {
+	Amount   Amount `json:"amount" swaggertype:"string" example:"65000.00"`
+	Currency string `json:"currency" example:"USD"`
+}
+
+// UnmarshalJSON also accepts a bare amount, as sent by clients that predate
+// currencies; Currency is then left empty for the caller to default
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		type plain Money
+		return json.Unmarshal(data, (*plain)(m))
+	}
+	return m.Amount.UnmarshalJSON(data)
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// ValidCurrency reports whether code is an ISO 4217 currency that an Amount
+// holds exactly, one with at most MoneyScale decimal places. Currencies with
+// three, such as BHD and KWD, are not supported.
+func ValidCurrency(code string) bool {
+	digits, ok := CurrencyDigits(code)
+	return ok && digits <= MoneyScale
+}
+
+// MinorUnit returns the smallest amount of a valid currency: 0.01 for most
+// currencies and 1.00 for those without decimal places, such as JPY
+func MinorUnit(code string) Amount {
+	unit := Amount(1)
+	for digits, _ := CurrencyDigits(code); digits < MoneyScale; digits++ {
+		unit *= 10
+	}
+	return unit
+}
+
+// Exact reports whether the amount is a whole number of minor units of its
+// currency
+func (m Money) Exact() bool {
+	return m.Amount%MinorUnit(m.Currency) == 0
+}
+
+// DefaultCurrencyFromEnv returns DEFAULT_CURRENCY, or DefaultCurrency if it
+// is unset or not a supported currency
+func DefaultCurrencyFromEnv() string {
+	if code := strings.ToUpper(os.Getenv("DEFAULT_CURRENCY")); ValidCurrency(code) {
+		return code
+	}
+	return DefaultCurrency
+}
@@ -0,0 +1,137 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/utils"
+)
+
+func TestParseAmount(t *testing.T) {
+	valid := map[string]utils.Amount{
+		"0":                    0,
+		"1234":                 123400,
+		"1234.5":               123450,
+		"1234.50":              123450,
+		"1234.500":             123450,
+		"-0.05":                -5,
+		"99999999.99":          9999999999,
+		"92233720368547758.07": 9223372036854775807,
+	}
+	for s, want := range valid {
+		got, err := utils.ParseAmount(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	for _, s := range []string{"", ".5", "12.", "1,5", "1e5", "12.345", "abc", "92233720368547758.08"} {
+		_, err := utils.ParseAmount(s)
+		assert.ErrorIs(t, err, utils.ErrInvalidMoney, s)
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	assert.Equal(t, "0.00", utils.Amount(0).String())
+	assert.Equal(t, "1234.50", utils.Amount(123450).String())
+	assert.Equal(t, "-0.05", utils.Amount(-5).String())
+}
+
+func TestAmountMulDiv(t *testing.T) {
+	// 50000.00 a year is 4166.666... a month, rounded half away from zero
+	assert.Equal(t, utils.Amount(4166_67), utils.Amount(50000_00).MulDiv(1, 12))
+	assert.Equal(t, utils.Amount(1923_08), utils.Amount(50000_00).MulDiv(1, 26))
+	assert.Equal(t, utils.Amount(3), utils.Amount(5).MulDiv(1, 2))
+	assert.Equal(t, utils.Amount(-3), utils.Amount(-5).MulDiv(1, 2))
+	assert.Equal(t, utils.Amount(2), utils.Amount(5).MulDiv(2, 5))
+	assert.Equal(t, utils.Amount(1), utils.Amount(7).MulDiv(1, 5))
+}
+
+func TestRate(t *testing.T) {
+	r, err := utils.ParseRate("0.125")
+	require.NoError(t, err)
+	assert.Equal(t, utils.Rate(1250), r)
+	assert.Equal(t, utils.Amount(62_50), r.Of(500_00))
+	// 10% of 5166.67 is 516.667, rounded up
+	assert.Equal(t, utils.Amount(516_67), utils.Rate(1000).Of(5166_67))
+
+	_, err = utils.ParseRate("0.12345")
+	assert.ErrorIs(t, err, utils.ErrInvalidMoney)
+}
+
+func TestAmountUnmarshalParam(t *testing.T) {
+	var a utils.Amount
+	require.NoError(t, a.UnmarshalParam("1000.10"))
+	assert.Equal(t, utils.Amount(1000_10), a)
+	assert.ErrorIs(t, a.UnmarshalParam("1000.101"), utils.ErrInvalidMoney)
+}
+
+func TestAmountScan(t *testing.T) {
+	var a utils.Amount
+	require.NoError(t, a.Scan([]byte("50000.10")))
+	assert.Equal(t, utils.Amount(5000010), a)
+	require.NoError(t, a.Scan(int64(7)))
+	assert.Equal(t, utils.Amount(700), a)
+	assert.Error(t, a.Scan(1.5))
+
+	v, err := utils.Amount(5000010).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "50000.10", v)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(utils.Money{Amount: 5000010, Currency: "EUR"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": "50000.10", "currency": "EUR"}`, string(data))
+
+	tests := map[string]utils.Money{
+		`{"amount": "50000.10", "currency": "EUR"}`: {Amount: 5000010, Currency: "EUR"},
+		`{"amount": 50000.1}`:                       {Amount: 5000010},
+		`50000.1`:                                   {Amount: 5000010},
+		`"0.07"`:                                    {Amount: 7},
+	}
+	for in, want := range tests {
+		var m utils.Money
+		require.NoError(t, json.Unmarshal([]byte(in), &m), in)
+		assert.Equal(t, want, m, in)
+	}
+
+	var m utils.Money
+	assert.ErrorIs(t, json.Unmarshal([]byte(`1000.005`), &m), utils.ErrInvalidMoney)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1e3"}`), &m), utils.ErrInvalidMoney)
+}
+
+func TestValidCurrency(t *testing.T) {
+	assert.True(t, utils.ValidCurrency("USD"))
+	assert.False(t, utils.ValidCurrency("usd"))
+	assert.False(t, utils.ValidCurrency("US"))
+	assert.False(t, utils.ValidCurrency("XYZ"), "not in ISO 4217")
+	assert.True(t, utils.ValidCurrency("JPY"))
+	assert.False(t, utils.ValidCurrency("KWD"), "three decimal places")
+
+	digits, ok := utils.CurrencyDigits("KWD")
+	assert.True(t, ok)
+	assert.Equal(t, 3, digits)
+}
+
+func TestMinorUnit(t *testing.T) {
+	assert.Equal(t, utils.Amount(1), utils.MinorUnit("USD"))
+	assert.Equal(t, utils.Amount(100), utils.MinorUnit("JPY"))
+
+	assert.True(t, utils.Money{Amount: 1000_50, Currency: "USD"}.Exact())
+	assert.True(t, utils.Money{Amount: 1000_00, Currency: "JPY"}.Exact())
+	assert.False(t, utils.Money{Amount: 1000_50, Currency: "JPY"}.Exact())
+}
+
+func TestDefaultCurrencyFromEnv(t *testing.T) {
+	t.Setenv("DEFAULT_CURRENCY", "")
+	assert.Equal(t, utils.DefaultCurrency, utils.DefaultCurrencyFromEnv())
+
+	t.Setenv("DEFAULT_CURRENCY", "eur")
+	assert.Equal(t, "EUR", utils.DefaultCurrencyFromEnv())
+
+	t.Setenv("DEFAULT_CURRENCY", "euro")
+	assert.Equal(t, utils.DefaultCurrency, utils.DefaultCurrencyFromEnv())
+}
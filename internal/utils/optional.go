@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional is a JSON Merge Patch field. It tells an absent key (Present is
+// false) apart from an explicit null (Null is true) and a value.
+type Optional[T any] struct {
+	Present bool
+	Null    bool
+	Value   T
+}
+
+// Some returns a present, non-null Optional holding v
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Present: true, Value: v}
+}
+
+// Null returns a present Optional set to null
+func Null[T any]() Optional[T] {
+	return Optional[T]{Present: true, Null: true}
+}
+
+// UnmarshalJSON only runs for keys present in the document
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+		var zero T
+		o.Value = zero
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON writes null for absent and null fields; tag the field with
+// omitzero to leave absent fields out
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// IsZero reports whether the field is absent
+func (o Optional[T]) IsZero() bool {
+	return !o.Present
+}
+
+// Ptr returns nil for absent and null fields and a pointer to the value
+// otherwise
+func (o Optional[T]) Ptr() *T {
+	if !o.Present || o.Null {
+		return nil
+	}
+	v := o.Value
+	return &v
+}
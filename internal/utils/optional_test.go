@@ -0,0 +1,32 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/utils"
+)
+
+func TestOptional(t *testing.T) {
+	var patch struct {
+		Absent utils.Optional[string] `json:"absent,omitzero"`
+		Null   utils.Optional[string] `json:"null,omitzero"`
+		Value  utils.Optional[int]    `json:"value,omitzero"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"null": null, "value": 7}`), &patch))
+
+	assert.Equal(t, utils.Optional[string]{}, patch.Absent)
+	assert.Equal(t, utils.Null[string](), patch.Null)
+	assert.Equal(t, utils.Some(7), patch.Value)
+	assert.Nil(t, patch.Null.Ptr())
+	assert.Equal(t, 7, *patch.Value.Ptr())
+
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"null": null, "value": 7}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "seven"}`), &patch))
+}
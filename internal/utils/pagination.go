@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were issued
+// for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a page for keyset pagination: the value of the
+// sort column and the row id used as a tiebreaker
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor and checks that it belongs to sort
+func DecodeCursor(s, sort string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort {
+		return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, c.Sort)
+	}
+	return &c, nil
+}
+
+// SortSpec splits a sort parameter such as "-salary" into its column and
+// direction
+func SortSpec(sort string) (column string, desc bool) {
+	if strings.HasPrefix(sort, "-") {
+		return sort[1:], true
+	}
+	return sort, false
+}
+
+// KeysetCondition returns the WHERE clause selecting rows after the cursor
+// for "ORDER BY column, id" in the given direction. argPos is the position of
+// the first of the two placeholders it uses.
+func KeysetCondition(column string, desc bool, argPos int) string {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, op, argPos, argPos+1)
+}
+
+// EscapeLike escapes LIKE wildcards so s matches literally
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetPageHeaders exposes the total count and, if there is one, the next page
+// through X-Total-Count, X-Next-Cursor and a Link header
+func SetPageHeaders(c *gin.Context, total int, nextCursor string) {
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	if nextCursor == "" {
+		return
+	}
+
+	next := *c.Request.URL
+	q := next.Query()
+	q.Set("cursor", nextCursor)
+	next.RawQuery = q.Encode()
+
+	c.Header("X-Next-Cursor", nextCursor)
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+}
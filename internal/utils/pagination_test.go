@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/utils"
+)
+
+func TestCursor(t *testing.T) {
+	encoded := utils.EncodeCursor(utils.Cursor{Sort: "-salary", Value: "1000.5", ID: 42})
+
+	c, err := utils.DecodeCursor(encoded, "-salary")
+	require.NoError(t, err)
+	assert.Equal(t, utils.Cursor{Sort: "-salary", Value: "1000.5", ID: 42}, *c)
+
+	_, err = utils.DecodeCursor(encoded, "name")
+	assert.True(t, errors.Is(err, utils.ErrInvalidCursor))
+
+	_, err = utils.DecodeCursor("not a cursor", "id")
+	assert.True(t, errors.Is(err, utils.ErrInvalidCursor))
+}
+
+func TestKeysetCondition(t *testing.T) {
+	column, desc := utils.SortSpec("-years_of_experience")
+	assert.Equal(t, "years_of_experience", column)
+	assert.True(t, desc)
+	assert.Equal(t, "(years_of_experience, id) < ($3, $4)", utils.KeysetCondition(column, desc, 3))
+
+	column, desc = utils.SortSpec("name")
+	assert.Equal(t, "(name, id) > ($1, $2)", utils.KeysetCondition(column, desc, 1))
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\% off\_now\\`, utils.EscapeLike(`50% off_now\`))
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+//go:embed ranks.json
+var embeddedRanks []byte
+
+// ErrInvalidRanks is returned for rank ladders that cannot be used
+var ErrInvalidRanks = errors.New("invalid rank ladder")
+
+// RankTier is a seniority rank. A cat holds the highest tier whose minimum
+// years of experience or minimum completed missions it meets; a minimum of 0
+// above the first tier leaves the tier closed to that path. Salaries of the tier
+// must lie within MinSalary..MaxSalary of the default currency, and its
+// cats may only be assigned missions with at most MaxTargets targets.
+type RankTier struct {
+	Name          string `json:"name"`
+	MinExperience int    `json:"min_experience"`
+	MinMissions   int    `json:"min_missions"`
+	MinSalary     Amount `json:"min_salary"`
+	MaxSalary     Amount `json:"max_salary"`
+	MaxTargets    int    `json:"max_targets"`
+}
+
+// RankLadder is the ordered list of rank tiers, lowest first
+type RankLadder struct {
+	tiers []RankTier
+}
+
+// RankLadderFromEnv loads the ladder from the JSON file at RANKS_PATH, or
+// the bundled default ladder if it is unset
+func RankLadderFromEnv() (*RankLadder, error) {
+	data := embeddedRanks
+	if path := os.Getenv("RANKS_PATH"); path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read rank ladder: %w", err)
+		}
+	}
+	return ParseRankLadder(data)
+}
+
+// DefaultRankLadder returns the bundled ladder: recruit, agent, senior and
+// handler
+func DefaultRankLadder() *RankLadder {
+	ladder, err := ParseRankLadder(embeddedRanks)
+	if err != nil {
+		panic(err)
+	}
+	return ladder
+}
+
+// ParseRankLadder reads a ladder of the form {"tiers": [...]}. The first
+// tier has no minimums so that every cat has a rank, every other tier sets at
+// least one, and each tier needs at least as much experience and as many
+// missions as the one below it.
+func ParseRankLadder(data []byte) (*RankLadder, error) {
+	var cfg struct {
+		Tiers []RankTier `json:"tiers"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRanks, err)
+	}
+	if len(cfg.Tiers) == 0 {
+		return nil, fmt.Errorf("%w: no tiers", ErrInvalidRanks)
+	}
+	if first := cfg.Tiers[0]; first.MinExperience != 0 || first.MinMissions != 0 {
+		return nil, fmt.Errorf("%w: the first tier %q must not have minimums", ErrInvalidRanks, first.Name)
+	}
+
+	seen := make(map[string]bool, len(cfg.Tiers))
+	for i, t := range cfg.Tiers {
+		switch {
+		case t.Name == "" || seen[t.Name]:
+			return nil, fmt.Errorf("%w: tier %d needs a unique name", ErrInvalidRanks, i+1)
+		case t.MinSalary < 0 || t.MinSalary > t.MaxSalary:
+			return nil, fmt.Errorf("%w: tier %q has salary band %s..%s", ErrInvalidRanks, t.Name, t.MinSalary, t.MaxSalary)
+		case i > 0 && t.MinExperience == 0 && t.MinMissions == 0:
+			return nil, fmt.Errorf("%w: tier %q needs a minimum", ErrInvalidRanks, t.Name)
+		case t.MaxTargets < 1:
+			return nil, fmt.Errorf("%w: tier %q must allow at least one target", ErrInvalidRanks, t.Name)
+		case i > 0 && (t.MinExperience < cfg.Tiers[i-1].MinExperience || t.MinMissions < cfg.Tiers[i-1].MinMissions):
+			return nil, fmt.Errorf("%w: tier %q needs less than the tier below it", ErrInvalidRanks, t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return &RankLadder{tiers: cfg.Tiers}, nil
+}
+
+// Tiers returns the tiers, lowest first
+func (l *RankLadder) Tiers() []RankTier {
+	return l.tiers
+}
+
+// Rank returns the tier of a cat with the given years of experience and
+// completed missions
+func (l *RankLadder) Rank(years, missions int) RankTier {
+	rank := l.tiers[0]
+	for _, t := range l.tiers[1:] {
+		if (t.MinExperience > 0 && years >= t.MinExperience) || (t.MinMissions > 0 && missions >= t.MinMissions) {
+			rank = t
+		}
+	}
+	return rank
+}
@@ -0,0 +1,82 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/utils"
+)
+
+func TestRankLadderRank(t *testing.T) {
+	ladder := utils.DefaultRankLadder()
+
+	tests := []struct {
+		years, missions int
+		want            string
+	}{
+		{0, 0, "recruit"},
+		{1, 2, "recruit"},
+		{2, 0, "agent"},
+		{0, 3, "agent"},
+		{4, 9, "agent"},
+		{5, 0, "senior"},
+		{1, 10, "senior"},
+		{12, 0, "handler"},
+		{0, 25, "handler"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ladder.Rank(tt.years, tt.missions).Name, "%d years, %d missions", tt.years, tt.missions)
+	}
+
+	senior := ladder.Rank(5, 10)
+	assert.Equal(t, utils.Amount(60000_00), senior.MinSalary)
+	assert.Equal(t, utils.Amount(120000_00), senior.MaxSalary)
+	assert.Equal(t, 3, senior.MaxTargets)
+	assert.Len(t, ladder.Tiers(), 4)
+}
+
+func TestParseRankLadder(t *testing.T) {
+	ladder, err := utils.ParseRankLadder([]byte(`{"tiers": [
+		{"name": "cadet", "min_salary": 0, "max_salary": "1000.50", "max_targets": 1},
+		{"name": "veteran", "min_experience": 3, "min_salary": 500, "max_salary": 5000, "max_targets": 3}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "veteran", ladder.Rank(3, 0).Name)
+	assert.Equal(t, utils.Amount(1000_50), ladder.Rank(0, 0).MaxSalary)
+
+	for name, data := range map[string]string{
+		"malformed":           `{"tiers": [`,
+		"no tiers":            `{"tiers": []}`,
+		"first with minimums": `{"tiers": [{"name": "a", "min_experience": 1, "max_salary": 1, "max_targets": 1}]}`,
+		"duplicate names":     `{"tiers": [{"name": "a", "max_salary": 1, "max_targets": 1}, {"name": "a", "max_salary": 1, "max_targets": 1}]}`,
+		"inverted band":       `{"tiers": [{"name": "a", "min_salary": 2, "max_salary": 1, "max_targets": 1}]}`,
+		"no minimums":         `{"tiers": [{"name": "a", "max_salary": 1, "max_targets": 1}, {"name": "b", "max_salary": 1, "max_targets": 1}]}`,
+		"no targets":          `{"tiers": [{"name": "a", "max_salary": 1}]}`,
+		"decreasing":          `{"tiers": [{"name": "a", "max_salary": 1, "max_targets": 1}, {"name": "b", "min_experience": 5, "max_salary": 1, "max_targets": 1}, {"name": "c", "min_experience": 4, "max_salary": 1, "max_targets": 1}]}`,
+	} {
+		_, err := utils.ParseRankLadder([]byte(data))
+		assert.ErrorIs(t, err, utils.ErrInvalidRanks, name)
+	}
+}
+
+func TestRankLadderFromEnv(t *testing.T) {
+	t.Setenv("RANKS_PATH", "")
+	ladder, err := utils.RankLadderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, utils.DefaultRankLadder(), ladder)
+
+	path := filepath.Join(t.TempDir(), "ranks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"tiers": [{"name": "solo", "max_salary": 10, "max_targets": 3}]}`), 0o600))
+	t.Setenv("RANKS_PATH", path)
+	ladder, err = utils.RankLadderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "solo", ladder.Rank(50, 50).Name)
+
+	t.Setenv("RANKS_PATH", filepath.Join(t.TempDir(), "missing.json"))
+	_, err = utils.RankLadderFromEnv()
+	assert.Error(t, err)
+}
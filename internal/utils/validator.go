@@ -1,31 +1,300 @@
 package utils
 
 import (
+	"context"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-func CatBreedExists(breed string) (bool, error) {
-	resp, err := http.Get("https://api.thecatapi.com/v1/breeds")
+const (
+	DefaultBreedsURL    = "https://api.thecatapi.com/v1/breeds"
+	DefaultBreedsTTL    = 24 * time.Hour
+	DefaultFetchTimeout = 5 * time.Second
+	DefaultRetryBackoff = time.Minute
+)
+
+// ErrCatalogOffline is returned when a refresh is requested from a catalog
+// that has no remote source configured
+var ErrCatalogOffline = errors.New("breed catalog is offline")
+
+//go:embed breeds_snapshot.json
+var embeddedBreeds []byte
+
+// Breed is a cat breed in TheCatAPI format
+type Breed struct {
+	Name        string `json:"name"`
+	Origin      string `json:"origin"`
+	Temperament string `json:"temperament"`
+	AltNames    string `json:"alt_names"`
+}
+
+// Aliases splits the comma separated alt_names field
+func (b Breed) Aliases() []string {
+	aliases := []string{}
+	for _, a := range strings.Split(b.AltNames, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases
+}
+
+// BreedCatalogConfig configures where a BreedCatalog gets its breeds from.
+// An empty URL disables remote fetching and serves the snapshot only. A
+// failed fetch is retried after RetryBackoff rather than a full TTL.
+type BreedCatalogConfig struct {
+	URL          string
+	TTL          time.Duration
+	Timeout      time.Duration
+	RetryBackoff time.Duration
+	SnapshotPath string
+}
+
+// BreedCatalogConfigFromEnv builds the catalog config from BREEDS_* variables
+func BreedCatalogConfigFromEnv() BreedCatalogConfig {
+	cfg := BreedCatalogConfig{
+		URL:          DefaultBreedsURL,
+		TTL:          DefaultBreedsTTL,
+		Timeout:      DefaultFetchTimeout,
+		RetryBackoff: DefaultRetryBackoff,
+		SnapshotPath: os.Getenv("BREEDS_SNAPSHOT_PATH"),
+	}
+	if url, ok := os.LookupEnv("BREEDS_API_URL"); ok {
+		cfg.URL = url
+	}
+	if offline, _ := strconv.ParseBool(os.Getenv("BREEDS_OFFLINE")); offline {
+		cfg.URL = ""
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("BREEDS_CACHE_TTL")); err == nil {
+		cfg.TTL = ttl
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("BREEDS_FETCH_TIMEOUT")); err == nil {
+		cfg.Timeout = timeout
+	}
+	if backoff, err := time.ParseDuration(os.Getenv("BREEDS_RETRY_BACKOFF")); err == nil {
+		cfg.RetryBackoff = backoff
+	}
+	return cfg
+}
+
+// BreedCatalog validates breeds against TheCatAPI with an in-memory cache.
+// Expired entries keep being served while a refresh runs in the background,
+// and the bundled snapshot is used whenever the API cannot be reached.
+type BreedCatalog struct {
+	cfg    BreedCatalogConfig
+	client *http.Client
+	now    func() time.Time
+
+	fetchMu sync.Mutex
+
+	mu         sync.Mutex
+	breeds     []Breed
+	matcher    *BreedMatcher
+	fetchedAt  time.Time
+	failedAt   time.Time // zero unless the last fetch failed
+	primed     bool
+	refreshing bool
+}
+
+func NewBreedCatalog(cfg BreedCatalogConfig) (*BreedCatalog, error) {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultBreedsTTL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultFetchTimeout
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+
+	snapshot := embeddedBreeds
+	if cfg.SnapshotPath != "" {
+		data, err := os.ReadFile(cfg.SnapshotPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read breeds snapshot: %w", err)
+		}
+		snapshot = data
+	}
+
+	var breeds []Breed
+	if err := json.Unmarshal(snapshot, &breeds); err != nil {
+		return nil, fmt.Errorf("invalid breeds snapshot: %w", err)
+	}
+
+	c := &BreedCatalog{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}
+	c.store(breeds)
+	return c, nil
+}
+
+// BreedExists reports whether breed matches a known breed name or alias
+func (c *BreedCatalog) BreedExists(breed string) (bool, error) {
+	_, err := c.ResolveBreed(breed)
+	return err == nil, nil
+}
+
+// ResolveBreed returns the canonical name for breed or an *UnknownBreedError
+func (c *BreedCatalog) ResolveBreed(breed string) (string, error) {
+	c.ensureFresh()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.matcher.Resolve(breed)
+}
+
+// Breeds returns the breeds currently known to the catalog and when they
+// were fetched from the API, the zero time for the snapshot
+func (c *BreedCatalog) Breeds() ([]Breed, time.Time) {
+	c.ensureFresh()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Breed(nil), c.breeds...), c.fetchedAt
+}
+
+// Refresh fetches the breed list from the API and replaces the cached one
+func (c *BreedCatalog) Refresh(ctx context.Context) error {
+	if c.cfg.URL == "" {
+		return ErrCatalogOffline
+	}
+
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	return c.refreshLocked(ctx)
+}
+
+// prime performs the first synchronous load, once, however many callers race
+func (c *BreedCatalog) prime() error {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	c.mu.Lock()
+	primed := c.primed
+	c.mu.Unlock()
+	if primed {
+		return nil
+	}
+	return c.refreshLocked(context.Background())
+}
+
+// refreshLocked must be called with c.fetchMu held
+func (c *BreedCatalog) refreshLocked(ctx context.Context) error {
+	breeds, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.primed = true
+	if err != nil {
+		c.failedAt = c.now()
+		return err
+	}
+	c.fetchedAt, c.failedAt = c.now(), time.Time{}
+	c.store(breeds)
+	return nil
+}
+
+// ensureFresh loads the breed list synchronously on first use and afterwards
+// revalidates expired data in the background
+func (c *BreedCatalog) ensureFresh() {
+	if c.cfg.URL == "" {
+		return
+	}
+
+	c.mu.Lock()
+	if !c.primed {
+		c.mu.Unlock()
+		if err := c.prime(); err != nil {
+			log.Printf("breed catalog: using snapshot, %v", err)
+		}
+		return
+	}
+	expired := c.now().Sub(c.fetchedAt) >= c.cfg.TTL
+	if !c.failedAt.IsZero() {
+		expired = c.now().Sub(c.failedAt) >= c.cfg.RetryBackoff
+	}
+	if !expired || c.refreshing {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing = true
+	c.mu.Unlock()
+
+	go func() {
+		if err := c.Refresh(context.Background()); err != nil {
+			log.Printf("breed catalog: refresh failed, serving stale data: %v", err)
+		}
+		c.mu.Lock()
+		c.refreshing = false
+		c.mu.Unlock()
+	}()
+}
+
+func (c *BreedCatalog) fetch(ctx context.Context) ([]Breed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.URL, nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch breeds: %w", err)
+		return nil, fmt.Errorf("failed to fetch breeds: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch breeds: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var breeds []struct {
-		Name string `json:"name"`
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch breeds: unexpected status %d", resp.StatusCode)
 	}
 
+	var breeds []Breed
 	if err := json.NewDecoder(resp.Body).Decode(&breeds); err != nil {
-		return false, fmt.Errorf("invalid response from Cat API: %w", err)
+		return nil, fmt.Errorf("invalid response from Cat API: %w", err)
+	}
+	if len(breeds) == 0 {
+		return nil, fmt.Errorf("invalid response from Cat API: no breeds")
 	}
+	return breeds, nil
+}
 
+// store must be called with c.mu held or before c is shared
+func (c *BreedCatalog) store(breeds []Breed) {
+	matcher := NewBreedMatcher()
 	for _, b := range breeds {
-		if b.Name == breed {
-			return true, nil
-		}
+		matcher.Add(b.Name, b.Aliases()...)
+	}
+	c.breeds = breeds
+	c.matcher = matcher
+}
+
+// StaticBreedValidator accepts a fixed set of breed names. It never touches
+// the network, which makes it a convenient fake in tests.
+type StaticBreedValidator struct {
+	matcher *BreedMatcher
+}
+
+func NewStaticBreedValidator(names ...string) *StaticBreedValidator {
+	matcher := NewBreedMatcher()
+	for _, n := range names {
+		matcher.Add(n)
 	}
+	return &StaticBreedValidator{matcher: matcher}
+}
+
+func (v *StaticBreedValidator) BreedExists(breed string) (bool, error) {
+	_, err := v.matcher.Resolve(breed)
+	return err == nil, nil
+}
 
-	return false, nil
+func (v *StaticBreedValidator) ResolveBreed(breed string) (string, error) {
+	return v.matcher.Resolve(breed)
 }
@@ -0,0 +1,186 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"spy-cats/internal/utils"
+)
+
+type breedsAPI struct {
+	mu     sync.Mutex
+	breeds []utils.Breed
+	status int
+	delay  time.Duration
+	hits   atomic.Int32
+}
+
+func (a *breedsAPI) set(names ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.breeds = nil
+	for _, n := range names {
+		a.breeds = append(a.breeds, utils.Breed{Name: n})
+	}
+}
+
+func (a *breedsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.hits.Add(1)
+	time.Sleep(a.delay)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.status != 0 {
+		w.WriteHeader(a.status)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(a.breeds)
+}
+
+func TestBreedCatalog(t *testing.T) {
+	t.Run("caches the API response", func(t *testing.T) {
+		api := &breedsAPI{}
+		api.set("Siamese", "Persian")
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, TTL: time.Hour})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			ok, err := c.BreedExists("Persian")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+		ok, _ := c.BreedExists("Bengal")
+		assert.False(t, ok)
+		assert.Equal(t, int32(1), api.hits.Load())
+	})
+
+	t.Run("falls back to the snapshot when the API fails", func(t *testing.T) {
+		api := &breedsAPI{status: http.StatusServiceUnavailable}
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, TTL: time.Hour})
+		require.NoError(t, err)
+
+		ok, err := c.BreedExists("Bengal")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("falls back to the snapshot when the API times out", func(t *testing.T) {
+		api := &breedsAPI{delay: 200 * time.Millisecond}
+		api.set("Persian")
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, Timeout: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		start := time.Now()
+		ok, _ := c.BreedExists("Siamese")
+		assert.True(t, ok)
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+	})
+
+	t.Run("offline catalog never calls the API", func(t *testing.T) {
+		api := &breedsAPI{}
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{})
+		require.NoError(t, err)
+
+		ok, _ := c.BreedExists("Maine Coon")
+		assert.True(t, ok)
+		breed, err := c.ResolveBreed("coon cat")
+		assert.NoError(t, err)
+		assert.Equal(t, "Maine Coon", breed)
+		breeds, fetchedAt := c.Breeds()
+		assert.NotEmpty(t, breeds)
+		assert.True(t, fetchedAt.IsZero())
+		assert.Equal(t, int32(0), api.hits.Load())
+	})
+
+	t.Run("retries a failed fetch after the backoff", func(t *testing.T) {
+		api := &breedsAPI{status: http.StatusServiceUnavailable}
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, TTL: time.Hour, RetryBackoff: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		ok, _ := c.BreedExists("New Breed")
+		require.False(t, ok)
+
+		api.mu.Lock()
+		api.status = 0
+		api.mu.Unlock()
+		api.set("New Breed")
+
+		ok, _ = c.BreedExists("New Breed")
+		assert.False(t, ok, "no retry before the backoff")
+		assert.Equal(t, int32(1), api.hits.Load())
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Eventually(t, func() bool {
+			ok, _ := c.BreedExists("New Breed")
+			return ok
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(2), api.hits.Load())
+	})
+
+	t.Run("serves stale data while revalidating", func(t *testing.T) {
+		api := &breedsAPI{}
+		api.set("Old Breed")
+		srv := httptest.NewServer(api)
+		defer srv.Close()
+
+		c, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{URL: srv.URL, TTL: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		ok, _ := c.BreedExists("Old Breed")
+		require.True(t, ok)
+
+		api.set("New Breed")
+		time.Sleep(30 * time.Millisecond)
+
+		ok, _ = c.BreedExists("Old Breed")
+		assert.True(t, ok, "expired data should still be served")
+
+		assert.Eventually(t, func() bool {
+			ok, _ := c.BreedExists("New Breed")
+			return ok
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("invalid snapshot file", func(t *testing.T) {
+		_, err := utils.NewBreedCatalog(utils.BreedCatalogConfig{SnapshotPath: "does-not-exist.json"})
+		assert.Error(t, err)
+	})
+}
+
+func TestStaticBreedValidator(t *testing.T) {
+	v := utils.NewStaticBreedValidator("Siamese")
+
+	ok, err := v.BreedExists("Siamese")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _ = v.BreedExists("Persian")
+	assert.False(t, ok)
+
+	breed, err := v.ResolveBreed("siamese")
+	assert.NoError(t, err)
+	assert.Equal(t, "Siamese", breed)
+}